@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"cli-tg-chat-summary/internal/app"
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+const inboxUsage = "usage: tg-summary inbox [--folder ID|title] [--include-muted] [--include-archived] [--format text|xml|xml-compact]"
+
+// parseInboxArgs parses the arguments of the inbox command, which exports
+// the unread messages of all chats into one digest.
+func parseInboxArgs(args []string) (app.RunOptions, error) {
+	var formatName string
+	var folder string
+	var names string
+	var muted, archived bool
+	flags := flag.NewFlagSet("inbox", flag.ContinueOnError)
+	flags.StringVar(&folder, "folder", "", "Only include the chats of this folder (ID or title)")
+	flags.BoolVar(&muted, "include-muted", false, "Include muted chats")
+	flags.BoolVar(&archived, "include-archived", false, "Include archived chats")
+	flags.StringVar(&formatName, "format", "text", "Export format (text, xml, xml-compact)")
+	flags.StringVar(&names, "names", "contact", "Sender names to use (contact, profile, username)")
+	if err := flags.Parse(args); err != nil {
+		return app.RunOptions{}, err
+	}
+	if flags.NArg() > 0 {
+		return app.RunOptions{}, fmt.Errorf("unexpected arguments %q\n%s", flags.Args(), inboxUsage)
+	}
+	switch telegram.NameSource(names) {
+	case telegram.NamesContact, telegram.NamesProfile, telegram.NamesUsername:
+	default:
+		return app.RunOptions{}, fmt.Errorf("unknown --names value %q (use contact, profile or username)", names)
+	}
+	return app.RunOptions{
+		NonInteractive: true,
+		ExportFormat:   formatName,
+		SenderNames:    names,
+		Inbox:          true,
+		InboxMuted:     muted,
+		InboxArchived:  archived,
+		Folder:         folder,
+	}, nil
+}
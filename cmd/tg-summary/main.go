@@ -15,22 +15,102 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "search" {
+		opts, err := parseSearchArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		run(opts)
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "inbox" {
+		opts, err := parseInboxArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		run(opts)
+		return
+	}
+
 	var sinceStr, untilStr string
 	var formatName string
 	var chatIDRaw int64
 	var topicID int
 	var topicTitle string
+	var resume bool
+	var stream bool
+	var parallel int
+	var last, fromID, toID int
+	var mentions, reactions bool
+	var contextSize int
+	var attention bool
+	var pinned, withPinned bool
+	var pollResults bool
+	var minViews, topPosts int
+	var names string
+	var adminLog bool
+	var adminLogEvents string
+	var saved bool
+	var sender string
+	var hashtags, folder string
+	var allTopics bool
 	flag.StringVar(&sinceStr, "since", "", "Start date (YYYY-MM-DD)")
 	flag.StringVar(&untilStr, "until", "", "End date (YYYY-MM-DD)")
 	flag.StringVar(&formatName, "format", "text", "Export format (text, xml, xml-compact)")
 	flag.Int64Var(&chatIDRaw, "id", 0, "Chat ID (raw or -100... format) to export without TUI")
 	flag.IntVar(&topicID, "topic-id", 0, "Forum topic ID (required for forum chats in non-interactive mode)")
 	flag.StringVar(&topicTitle, "topic", "", "Forum topic title (alternative to --topic-id)")
+	flag.BoolVar(&allTopics, "all-topics", false, "Export every forum topic with activity into one file, a section per topic (requires --id)")
+	flag.BoolVar(&resume, "resume", false, "Resume an interrupted export from its checkpoint")
+	flag.BoolVar(&stream, "stream", false, "Write messages to the export file as they are fetched")
+	flag.IntVar(&parallel, "parallel", 1, "Number of concurrent partitions for date range fetches")
+	flag.IntVar(&last, "last", 0, "Export the newest N messages")
+	flag.IntVar(&fromID, "from-id", 0, "First message ID to export (requires --id)")
+	flag.IntVar(&toID, "to-id", 0, "Last message ID to export (requires --id; defaults to the newest message)")
+	flag.BoolVar(&mentions, "mentions", false, "Export unread mentions of you")
+	flag.BoolVar(&reactions, "reactions", false, "Export your messages with unread reactions")
+	flag.IntVar(&contextSize, "context", 0, "Include N already read messages before the first unread one")
+	flag.BoolVar(&pinned, "pinned", false, "Export the pinned messages of the chat or topic")
+	flag.BoolVar(&withPinned, "with-pinned", false, "Add a section with the pinned messages to the export")
+	flag.BoolVar(&pollResults, "poll-results", false, "Fetch fresh results for open polls before exporting")
+	flag.BoolVar(&attention, "attention", false, "Add a section listing messages that mention or reply to you")
+	flag.IntVar(&minViews, "min-views", 0, "Export only messages with at least N views")
+	flag.IntVar(&topPosts, "top-posts", 0, "Add a section ranking the N most engaging posts")
+	flag.StringVar(&names, "names", "contact", "Sender names to use (contact, profile, username)")
+	flag.BoolVar(&adminLog, "admin-log", false, "Export the admin log of a supergroup or channel (--since/--until limit the time window)")
+	flag.BoolVar(&saved, "saved", false, "Export Saved Messages grouped by source (--since/--until limit the time window)")
+	flag.StringVar(&sender, "sender", "", "Export the messages of a user (ID or @username) from all shared chats (--since/--until limit the time window)")
+	flag.StringVar(&hashtags, "hashtags", "", "Export messages with any of the comma separated hashtags or cashtags, grouped by tag (--since/--until limit the time window)")
+	flag.StringVar(&folder, "folder", "", "Chat folder (ID or title) to search for --hashtags instead of all chats")
+	flag.StringVar(&adminLogEvents, "admin-log-events", "", "Comma separated admin log events to export (bans, edits, deletions, settings, invites)")
 	flag.Parse()
 
 	var opts app.RunOptions
 	var err error
 	opts.ExportFormat = formatName
+	opts.Resume = resume
+	opts.Stream = stream
+	opts.Parallel = parallel
+	opts.Last = last
+	opts.FromID = fromID
+	opts.ToID = toID
+	opts.UnreadMentions = mentions
+	opts.UnreadReactions = reactions
+	opts.Context = contextSize
+	opts.Attention = attention
+	opts.Pinned = pinned
+	opts.WithPinned = withPinned
+	opts.PollResults = pollResults
+	opts.MinViews = minViews
+	opts.TopPosts = topPosts
+	opts.SenderNames = names
+	opts.AdminLog = adminLog
+	opts.Saved = saved
+	opts.Sender = sender
+	opts.Folder = folder
+	opts.AllTopics = allTopics
 
 	if chatIDRaw != 0 {
 		opts.NonInteractive = true
@@ -41,34 +121,164 @@ func main() {
 		opts.TopicID = topicID
 		opts.TopicTitle = topicTitle
 	}
+	if saved {
+		if chatIDRaw != 0 {
+			fmt.Fprintln(os.Stderr, "Error: --saved cannot be combined with --id")
+			os.Exit(1)
+		}
+		// Saved Messages is the user's own chat, so no --id is needed.
+		opts.NonInteractive = true
+	}
+	if sender != "" {
+		if chatIDRaw != 0 {
+			fmt.Fprintln(os.Stderr, "Error: --sender cannot be combined with --id")
+			os.Exit(1)
+		}
+		// The sender's messages are collected from all shared chats.
+		opts.NonInteractive = true
+	}
+	if hashtags != "" {
+		opts.Hashtags, err = telegram.ParseHashtags(hashtags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(opts.Hashtags) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: --hashtags requires at least one tag")
+			os.Exit(1)
+		}
+		if chatIDRaw != 0 && folder != "" {
+			fmt.Fprintln(os.Stderr, "Error: --folder cannot be combined with --id")
+			os.Exit(1)
+		}
+		// Without --id the tags are collected from a folder or all chats.
+		opts.NonInteractive = true
+	}
+	if folder != "" && hashtags == "" {
+		fmt.Fprintln(os.Stderr, "Error: --folder requires --hashtags")
+		os.Exit(1)
+	}
 
 	if (topicID != 0 || topicTitle != "") && chatIDRaw == 0 {
 		fmt.Fprintln(os.Stderr, "Error: --topic-id/--topic requires --id")
 		os.Exit(1)
 	}
-
-	if sinceStr != "" {
-		opts.UseDateRange = true
-		opts.Since, err = time.Parse("2006-01-02", sinceStr)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Invalid date format for --since: %s\n", sinceStr)
-			fmt.Fprintln(os.Stderr, "Please use the format YYYY-MM-DD (e.g., 2024-01-20)")
+	if allTopics {
+		if chatIDRaw == 0 {
+			fmt.Fprintln(os.Stderr, "Error: --all-topics requires --id")
 			os.Exit(1)
 		}
-		if untilStr != "" {
-			opts.Until, err = time.Parse("2006-01-02", untilStr)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: Invalid date format for --until: %s\n", untilStr)
-				fmt.Fprintln(os.Stderr, "Please use the format YYYY-MM-DD (e.g., 2024-01-20)")
-				os.Exit(1)
-			}
-			// set until to end of that day
-			opts.Until = opts.Until.Add(24 * time.Hour).Add(-time.Nanosecond)
-		} else {
-			opts.Until = time.Now()
+		if topicID != 0 || topicTitle != "" {
+			fmt.Fprintln(os.Stderr, "Error: --all-topics cannot be combined with --topic-id/--topic")
+			os.Exit(1)
+		}
+		// Topics are exported from unread messages or a date range only.
+		if countSet(last > 0, fromID > 0 || toID > 0, mentions, reactions, pinned, adminLog, saved, sender != "", hashtags != "", contextSize > 0) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: --all-topics only applies to unread and --since/--until exports")
+			os.Exit(1)
 		}
+		if stream || resume {
+			fmt.Fprintln(os.Stderr, "Error: --all-topics cannot be combined with --stream or --resume")
+			os.Exit(1)
+		}
+	}
+
+	if last < 0 || fromID < 0 || toID < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --last, --from-id and --to-id must be positive")
+		os.Exit(1)
+	}
+	if countSet(sinceStr != "" && !adminLog && !saved && sender == "" && hashtags == "", last > 0, fromID > 0 || toID > 0, mentions, reactions, pinned, adminLog, saved, sender != "", hashtags != "") > 1 {
+		fmt.Fprintln(os.Stderr, "Error: only one of --since, --last, --from-id/--to-id, --mentions, --reactions, --pinned, --admin-log, --saved, --sender and --hashtags can be used")
+		os.Exit(1)
+	}
+	if contextSize < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --context must be positive")
+		os.Exit(1)
+	}
+	if contextSize > 0 && countSet(sinceStr != "", last > 0, fromID > 0 || toID > 0, mentions, reactions, pinned, adminLog, saved, sender != "", hashtags != "") > 0 {
+		fmt.Fprintln(os.Stderr, "Error: --context only applies to unread exports")
+		os.Exit(1)
+	}
+	if (fromID > 0 || toID > 0) && chatIDRaw == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --from-id/--to-id requires --id")
+		os.Exit(1)
+	}
+	if toID > 0 && fromID > toID {
+		fmt.Fprintln(os.Stderr, "Error: --from-id cannot be greater than --to-id")
+		os.Exit(1)
 	}
+	if toID > 0 && fromID == 0 {
+		opts.FromID = 1
+	}
+
+	if pinned && withPinned {
+		fmt.Fprintln(os.Stderr, "Error: --with-pinned cannot be combined with --pinned")
+		os.Exit(1)
+	}
+
+	if stream && attention {
+		fmt.Fprintln(os.Stderr, "Error: --attention cannot be combined with --stream")
+		os.Exit(1)
+	}
+
+	if minViews < 0 || topPosts < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --min-views and --top-posts must be positive")
+		os.Exit(1)
+	}
+	switch telegram.NameSource(names) {
+	case telegram.NamesContact, telegram.NamesProfile, telegram.NamesUsername:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown --names value %q (use contact, profile or username)\n", names)
+		os.Exit(1)
+	}
+	if adminLogEvents != "" && !adminLog {
+		fmt.Fprintln(os.Stderr, "Error: --admin-log-events requires --admin-log")
+		os.Exit(1)
+	}
+	opts.AdminLogEvents, err = telegram.ParseAdminLogEvents(adminLogEvents)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if stream && (adminLog || saved || sender != "" || hashtags != "") {
+		fmt.Fprintln(os.Stderr, "Error: --admin-log, --saved, --sender and --hashtags cannot be combined with --stream")
+		os.Exit(1)
+	}
+	if stream && topPosts > 0 {
+		fmt.Fprintln(os.Stderr, "Error: --top-posts cannot be combined with --stream")
+		os.Exit(1)
+	}
+
+	if stream && resume {
+		fmt.Fprintln(os.Stderr, "Error: --stream cannot be combined with --resume")
+		os.Exit(1)
+	}
+	if saved && resume {
+		fmt.Fprintln(os.Stderr, "Error: --saved cannot be combined with --resume")
+		os.Exit(1)
+	}
+
+	// Only date range fetches of chat history are partitioned.
+	if parallel > 1 && (sinceStr == "" || adminLog || saved || sender != "" || hashtags != "") {
+		fmt.Fprintln(os.Stderr, "Error: --parallel only applies to --since/--until exports")
+		os.Exit(1)
+	}
+	if parallel > 1 && (resume || stream) {
+		fmt.Fprintln(os.Stderr, "Error: --parallel cannot be combined with --resume or --stream")
+		os.Exit(1)
+	}
+
+	if err := parseDateRange(&opts, sinceStr, untilStr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Please use the format YYYY-MM-DD (e.g., 2024-01-20)")
+		os.Exit(1)
+	}
+
+	run(opts)
+}
 
+// run logs in and runs the application with opts.
+func run(opts app.RunOptions) {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -94,6 +304,42 @@ func main() {
 	}
 }
 
+// parseDateRange enables date range mode when sinceStr is set. until
+// defaults to now and otherwise covers the whole day.
+func parseDateRange(opts *app.RunOptions, sinceStr, untilStr string) error {
+	if sinceStr == "" {
+		return nil
+	}
+	since, err := time.Parse("2006-01-02", sinceStr)
+	if err != nil {
+		return fmt.Errorf("invalid date format for --since: %s", sinceStr)
+	}
+	until := time.Now()
+	if untilStr != "" {
+		until, err = time.Parse("2006-01-02", untilStr)
+		if err != nil {
+			return fmt.Errorf("invalid date format for --until: %s", untilStr)
+		}
+		// set until to end of that day
+		until = until.Add(24 * time.Hour).Add(-time.Nanosecond)
+	}
+	opts.UseDateRange = true
+	opts.Since = since
+	opts.Until = until
+	return nil
+}
+
+// countSet returns how many of the given flags are set.
+func countSet(flags ...bool) int {
+	n := 0
+	for _, set := range flags {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
 func normalizeChatID(id int64) int64 {
 	if id <= -1000000000000 {
 		return -id - 1000000000000
@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"testing"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
 
 func TestNormalizeChatID(t *testing.T) {
 	tests := []struct {
@@ -38,3 +42,43 @@ func TestNormalizeChatID(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSearchArgs(t *testing.T) {
+	opts, err := parseSearchArgs([]string{"--scope", "channels", "--since", "2025-01-01", "--until", "2025-01-31", "release", "notes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Search != "release notes" || opts.SearchScope != telegram.SearchChannels {
+		t.Fatalf("unexpected search options: %+v", opts)
+	}
+	if !opts.UseDateRange || opts.Since.Format("2006-01-02") != "2025-01-01" || opts.Until.Format("2006-01-02") != "2025-01-31" {
+		t.Fatalf("unexpected window: %v to %v", opts.Since, opts.Until)
+	}
+
+	opts, err = parseSearchArgs([]string{"golang"})
+	if err != nil || opts.SearchScope != telegram.SearchAll || opts.UseDateRange {
+		t.Fatalf("unexpected defaults: %+v, %v", opts, err)
+	}
+
+	for _, args := range [][]string{{}, {"--scope", "bots", "golang"}, {"--since", "01/02/2025", "golang"}} {
+		if _, err := parseSearchArgs(args); err == nil {
+			t.Errorf("parseSearchArgs(%q) expected an error", args)
+		}
+	}
+}
+
+func TestParseInboxArgs(t *testing.T) {
+	opts, err := parseInboxArgs([]string{"--folder", "Work", "--include-muted", "--format", "xml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.Inbox || opts.Folder != "Work" || !opts.InboxMuted || opts.InboxArchived || opts.ExportFormat != "xml" {
+		t.Fatalf("unexpected inbox options: %+v", opts)
+	}
+
+	for _, args := range [][]string{{"extra"}, {"--names", "nick"}} {
+		if _, err := parseInboxArgs(args); err == nil {
+			t.Errorf("parseInboxArgs(%q) expected an error", args)
+		}
+	}
+}
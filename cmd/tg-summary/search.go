@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"cli-tg-chat-summary/internal/app"
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+const searchUsage = "usage: tg-summary search [--scope all|groups|channels|private] [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--format text|xml|xml-compact] <query>"
+
+// parseSearchArgs parses the arguments of the search command, which exports
+// the hits of a global search across all chats.
+func parseSearchArgs(args []string) (app.RunOptions, error) {
+	var sinceStr, untilStr string
+	var formatName string
+	var scope string
+	var names string
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	flags.StringVar(&sinceStr, "since", "", "Search messages sent since this date (YYYY-MM-DD)")
+	flags.StringVar(&untilStr, "until", "", "Search messages sent until this date (YYYY-MM-DD)")
+	flags.StringVar(&formatName, "format", "text", "Export format (text, xml, xml-compact)")
+	flags.StringVar(&scope, "scope", "all", "Chats to search (all, groups, channels, private)")
+	flags.StringVar(&names, "names", "contact", "Sender names to use (contact, profile, username)")
+	if err := flags.Parse(args); err != nil {
+		return app.RunOptions{}, err
+	}
+
+	query := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if query == "" {
+		return app.RunOptions{}, fmt.Errorf("missing search query\n%s", searchUsage)
+	}
+	opts := app.RunOptions{
+		NonInteractive: true,
+		ExportFormat:   formatName,
+		SenderNames:    names,
+		Search:         query,
+	}
+	var err error
+	opts.SearchScope, err = telegram.ParseSearchScope(scope)
+	if err != nil {
+		return app.RunOptions{}, err
+	}
+	switch telegram.NameSource(names) {
+	case telegram.NamesContact, telegram.NamesProfile, telegram.NamesUsername:
+	default:
+		return app.RunOptions{}, fmt.Errorf("unknown --names value %q (use contact, profile or username)", names)
+	}
+	if err := parseDateRange(&opts, sinceStr, untilStr); err != nil {
+		return app.RunOptions{}, err
+	}
+	return opts, nil
+}
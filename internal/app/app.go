@@ -12,9 +12,10 @@ import (
 )
 
 type App struct {
-	cfg      *config.Config
-	tgClient *telegram.Client
-	exporter Exporter
+	cfg         *config.Config
+	tgClient    *telegram.Client
+	exporter    Exporter
+	checkpoints *checkpointStore
 }
 
 func New(cfg *config.Config, tgClient *telegram.Client) *App {
@@ -26,9 +27,10 @@ func NewWithExporter(cfg *config.Config, tgClient *telegram.Client, exporter Exp
 		exporter = NewDefaultExporter()
 	}
 	return &App{
-		cfg:      cfg,
-		tgClient: tgClient,
-		exporter: exporter,
+		cfg:         cfg,
+		tgClient:    tgClient,
+		exporter:    exporter,
+		checkpoints: newCheckpointStore("checkpoints"),
 	}
 }
 
@@ -42,6 +44,107 @@ type RunOptions struct {
 	TopicID        int
 	TopicTitle     string
 	NonInteractive bool
+	Resume         bool
+	// Partial marks an export of messages collected before a canceled fetch.
+	Partial bool
+	// Stream writes messages to the export file while they are fetched
+	// instead of collecting them in memory first.
+	Stream bool
+	// Parallel is the number of concurrent partitions used for date range
+	// fetches; values below 2 fetch sequentially.
+	Parallel int
+	// Last exports the newest Last messages instead of unread ones.
+	Last int
+	// FromID and ToID export a message ID range; ToID 0 means up to the
+	// newest message.
+	FromID int
+	ToID   int
+	// UnreadMentions and UnreadReactions export only unread mentions of the
+	// user, or the user's messages with unseen reactions.
+	UnreadMentions  bool
+	UnreadReactions bool
+	// Pinned exports the pinned messages of the chat or topic.
+	Pinned bool
+	// WithPinned adds a "Pinned" section with the chat's pinned messages
+	// at the top of other exports.
+	WithPinned bool
+	// PollResults fetches fresh vote counts for open polls before export.
+	PollResults bool
+	// Context is the number of already read messages exported before the
+	// first unread one. It only applies to unread exports.
+	Context int
+	// Attention adds a "Needs your attention" section listing messages
+	// that mention the user or reply to the user's messages.
+	Attention bool
+	// MinViews skips messages with fewer views, e.g. to keep only popular
+	// channel posts. Skipped messages are still marked as read.
+	MinViews int
+	// TopPosts adds a "Top posts" section ranking the TopPosts most viewed
+	// messages by engagement.
+	TopPosts int
+	// SenderNames selects the sender display names: "contact" (default),
+	// "profile" or "username".
+	SenderNames string
+	// AdminLog exports the admin log of a supergroup or channel instead of
+	// messages, limited to AdminLogEvents when set. With UseDateRange, only
+	// events between Since and Until are exported.
+	AdminLog       bool
+	AdminLogEvents []telegram.AdminLogEvent
+	// Saved exports Saved Messages as a digest grouped by the source each
+	// item was saved from. With UseDateRange, only items saved between
+	// Since and Until are exported.
+	Saved bool
+	// Search exports the hits of a global search for the query across all
+	// chats, limited to SearchScope. With UseDateRange, only messages sent
+	// between Since and Until are searched.
+	Search      string
+	SearchScope telegram.SearchScope
+	// Sender exports the messages one user (a numeric ID or @username)
+	// sent in every chat shared with them. With UseDateRange, only messages
+	// sent between Since and Until are exported.
+	Sender string
+	// Hashtags exports the messages tagged with any of the hashtags or
+	// cashtags, grouped by tag. The search covers ChatID when set, the chats
+	// of Folder (an ID or title) when set, and all chats otherwise. With
+	// UseDateRange, only messages sent between Since and Until are exported.
+	Hashtags []string
+	Folder   string
+	// Inbox exports the unread messages of all chats and forum topics into
+	// one digest with a section per chat or topic, limited to Folder when
+	// set. Muted and archived chats are skipped unless InboxMuted and
+	// InboxArchived are set.
+	Inbox         bool
+	InboxMuted    bool
+	InboxArchived bool
+	// AllTopics exports every topic of a forum with unread messages, or
+	// every topic in date range mode, into one file with a section per
+	// topic. Each topic is marked as read on its own.
+	AllTopics bool
+}
+
+// nameSource returns the sender name source, defaulting to contact names.
+func (o RunOptions) nameSource() telegram.NameSource {
+	if o.SenderNames == "" {
+		return telegram.NamesContact
+	}
+	return telegram.NameSource(o.SenderNames)
+}
+
+func (o RunOptions) idRange() bool {
+	return o.FromID > 0 || o.ToID > 0
+}
+
+// unreadMode reports whether the export covers unread messages, which are
+// marked as read once exported.
+func (o RunOptions) unreadMode() bool {
+	return !o.UseDateRange && o.Last == 0 && !o.idRange() && !o.UnreadMentions && !o.UnreadReactions && !o.Pinned && !o.AdminLog && !o.Saved && o.Search == "" && o.Sender == "" && len(o.Hashtags) == 0
+}
+
+// checkpointed reports whether fetches save checkpoints that can be resumed.
+// Streamed and partitioned fetches and digests fetched per chat, topic or
+// Saved Messages source do not.
+func (o RunOptions) checkpointed() bool {
+	return !o.Stream && !(o.UseDateRange && o.Parallel > 1) && !o.AllTopics && !o.Saved
 }
 
 func (a *App) Run(ctx context.Context, opts RunOptions) error {
@@ -50,6 +153,22 @@ func (a *App) Run(ctx context.Context, opts RunOptions) error {
 		return fmt.Errorf("failed to login: %w", err)
 	}
 
+	if opts.Search != "" {
+		return a.runAcrossChats(ctx, a.searchPlan(opts), opts)
+	}
+	if opts.Sender != "" {
+		return a.runAcrossChats(ctx, a.senderPlan(opts), opts)
+	}
+	if opts.Inbox {
+		return a.runInbox(ctx, opts)
+	}
+	if len(opts.Hashtags) > 0 {
+		chats, err := a.hashtagChats(ctx, opts)
+		if err != nil {
+			return err
+		}
+		return a.runAcrossChats(ctx, a.hashtagPlan(chats, opts), opts)
+	}
 	if opts.NonInteractive {
 		return a.runNonInteractive(ctx, opts)
 	}
@@ -67,7 +186,15 @@ func (a *App) runNonInteractive(ctx context.Context, opts RunOptions) error {
 		return nil
 	}
 
-	selectedChat := findChatByID(chats, opts.ChatID)
+	var selectedChat *telegram.Chat
+	if opts.Saved && opts.ChatID == 0 {
+		selectedChat = findSavedMessages(chats)
+		if selectedChat == nil {
+			return fmt.Errorf("saved messages chat not found")
+		}
+	} else {
+		selectedChat = findChatByID(chats, opts.ChatID)
+	}
 	if selectedChat == nil {
 		chatID := opts.ChatID
 		if opts.ChatIDRaw != 0 {
@@ -75,9 +202,13 @@ func (a *App) runNonInteractive(ctx context.Context, opts RunOptions) error {
 		}
 		return fmt.Errorf("chat with id %d not found; accepts raw ID or -100... format", chatID)
 	}
+	if err := selectedChat.ExportError(); err != nil {
+		return err
+	}
+	opts.Saved = opts.Saved || savedDigest(*selectedChat, opts)
 
 	var selectedTopic *telegram.Topic
-	if selectedChat.IsForum {
+	if selectedChat.IsForum && !opts.AdminLog && !opts.AllTopics {
 		if opts.TopicID == 0 && opts.TopicTitle == "" {
 			return fmt.Errorf("forum chat requires --topic-id or --topic")
 		}
@@ -98,27 +229,323 @@ func (a *App) runNonInteractive(ctx context.Context, opts RunOptions) error {
 	if err != nil {
 		return err
 	}
-	messages, err := plan.fetch(ctx, nil)
+	if opts.Stream {
+		return a.runStream(ctx, plan, *selectedChat, selectedTopic, opts)
+	}
+	resume, err := a.loadResumeCursor(plan, opts)
+	if err != nil {
+		return err
+	}
+	messages, err := a.fetchWithCheckpoint(ctx, plan, resume, nil)
 	if err != nil {
+		if ctx.Err() != nil && opts.checkpointed() {
+			fmt.Fprintln(os.Stderr, "Interrupted; progress saved. Run again with --resume to continue.")
+		}
 		return err
 	}
 
 	if len(messages) == 0 {
 		fmt.Fprintln(os.Stderr, "No text messages found to export.")
-		return nil
+		return a.checkpoints.Remove(plan.checkpoint)
+	}
+	exported := filterMinViews(messages, opts.MinViews)
+	if len(exported) == 0 {
+		fmt.Fprintf(os.Stderr, "No messages with at least %d views found to export.\n", opts.MinViews)
+		printMarkReadStatus(a.markMessagesAsRead(ctx, *selectedChat, selectedTopic, messages, opts))
+		return a.checkpoints.Remove(plan.checkpoint)
+	}
+	if err := a.refreshPolls(ctx, plan, exported); err != nil {
+		return err
+	}
+	if err := a.nameSenders(ctx, plan, exported); err != nil {
+		return err
+	}
+	sections, err := a.fetchSections(ctx, plan, exported, nil)
+	if err != nil {
+		return err
+	}
+	for _, line := range sections.warningLines() {
+		fmt.Fprintln(os.Stderr, line)
 	}
 
-	filename, err := a.exportMessages(plan.exportTitle, messages, opts)
+	filename, err := a.exportMessages(plan.exportTitle, exported, sections, opts)
 	if err != nil {
 		return err
 	}
+	if err := a.checkpoints.Remove(plan.checkpoint); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 
-	fmt.Fprintf(os.Stderr, "Successfully exported %d messages to %s\n", len(messages), filename)
+	fmt.Fprintf(os.Stderr, "Successfully exported %d messages to %s\n", countMessages(exported), filename)
+	if plan.digest != nil {
+		if status := a.markDigestAsRead(ctx, plan.digest, opts); status != "" {
+			fmt.Fprintln(os.Stderr, status)
+		}
+		return nil
+	}
 	markResult := a.markMessagesAsRead(ctx, *selectedChat, selectedTopic, messages, opts)
 	printMarkReadStatus(markResult)
 	return nil
 }
 
+func (a *App) runStream(ctx context.Context, plan fetchPlan, selectedChat telegram.Chat, selectedTopic *telegram.Topic, opts RunOptions) error {
+	result, err := a.exportStream(ctx, plan, opts, nil)
+	if err != nil {
+		return err
+	}
+	if result.count == 0 {
+		fmt.Fprintln(os.Stderr, "No text messages found to export.")
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Successfully exported %d messages to %s\n", result.count, result.filename)
+	for _, line := range result.warnings {
+		fmt.Fprintln(os.Stderr, line)
+	}
+	markResult := a.markAsReadUpTo(ctx, selectedChat, selectedTopic, result.maxID, opts)
+	printMarkReadStatus(markResult)
+	return nil
+}
+
+// runAcrossChats exports the messages of a plan that spans many chats,
+// such as a global search, into one file. Nothing is marked as read.
+func (a *App) runAcrossChats(ctx context.Context, plan fetchPlan, opts RunOptions) error {
+	messages, err := plan.fetch(ctx, nil, nil)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		fmt.Fprintln(os.Stderr, "No text messages found to export.")
+		return nil
+	}
+	if err := a.nameSenders(ctx, plan, messages); err != nil {
+		return err
+	}
+	filename, err := a.exportMessages(plan.exportTitle, messages, ExportSections{}, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Successfully exported %d messages to %s\n", countMessages(messages), filename)
+	return nil
+}
+
+// streamedExport describes an export written while messages were fetched.
+type streamedExport struct {
+	filename string
+	count    int
+	maxID    int
+	// warnings come from the sections fetched before the messages.
+	warnings []string
+}
+
+// exportStream pipes the plan's message iterator straight into the export
+// file. Streamed exports are not checkpointed; an interrupted one is removed.
+func (a *App) exportStream(ctx context.Context, plan fetchPlan, opts RunOptions, progress telegram.ProgressFunc) (streamedExport, error) {
+	exporter, ok := a.exporter.(StreamExporter)
+	if !ok {
+		return streamedExport{}, fmt.Errorf("exporter does not support streaming")
+	}
+	if plan.stream == nil {
+		return streamedExport{}, fmt.Errorf("streaming is not supported for this export mode")
+	}
+
+	sections, err := a.fetchSections(ctx, plan, nil, progress)
+	if err != nil {
+		return streamedExport{}, err
+	}
+
+	result := streamedExport{warnings: sections.warningLines()}
+	messages := func(yield func(telegram.Message, error) bool) {
+		for msg, err := range plan.stream(ctx, progress) {
+			if err == nil {
+				// Skipped messages still count as seen for marking as read.
+				result.maxID = max(result.maxID, msg.ID)
+				if msg.Views < opts.MinViews {
+					continue
+				}
+			}
+			if err == nil && plan.refreshPoll != nil {
+				err = plan.refreshPoll(ctx, &msg)
+			}
+			if err == nil && plan.senderName != nil {
+				err = plan.senderName(ctx, &msg)
+			}
+			if err == nil && msg.Event == "" {
+				result.count++
+			}
+			if !yield(msg, err) {
+				return
+			}
+		}
+	}
+	filename, err := exporter.ExportStream(plan.exportTitle, messages, sections, opts)
+	if err != nil {
+		return streamedExport{}, fmt.Errorf("failed to export: %w", err)
+	}
+	result.filename = filename
+	return result, nil
+}
+
+// filterMinViews returns the messages with at least minViews views. It
+// returns messages unchanged when minViews is not set.
+func filterMinViews(messages []telegram.Message, minViews int) []telegram.Message {
+	if minViews <= 0 {
+		return messages
+	}
+	filtered := make([]telegram.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Views >= minViews {
+			filtered = append(filtered, msg)
+		}
+	}
+	return filtered
+}
+
+// refreshPolls updates open polls in messages with fresh results when the
+// plan asks for it.
+func (a *App) refreshPolls(ctx context.Context, plan fetchPlan, messages []telegram.Message) error {
+	if plan.refreshPoll == nil {
+		return nil
+	}
+	for i := range messages {
+		if err := plan.refreshPoll(ctx, &messages[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nameSenders sets the display names of the senders of messages.
+func (a *App) nameSenders(ctx context.Context, plan fetchPlan, messages []telegram.Message) error {
+	if plan.senderName == nil {
+		return nil
+	}
+	// Senders of resumed messages were cached by an earlier run; without
+	// them the names fall back to the contacts.
+	if err := a.tgClient.ResolveUsers(ctx, senderIDs(nil, nil, messages)); err != nil && ctx.Err() != nil {
+		return err
+	}
+	for i := range messages {
+		if err := plan.senderName(ctx, &messages[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchSections fetches the extra sections requested for the export and
+// orders them oldest first. messages are the fetched messages, newest first;
+// their senders make up the participant directory. Streamed exports pass
+// nil and get the chat metadata only.
+func (a *App) fetchSections(ctx context.Context, plan fetchPlan, messages []telegram.Message, progress telegram.ProgressFunc) (ExportSections, error) {
+	var sections ExportSections
+	if plan.digest != nil {
+		sections.warnings = append(sections.warnings, plan.digest.failures...)
+	}
+	if plan.pinned != nil {
+		pinned, err := plan.pinned(ctx, progress)
+		if err != nil {
+			return ExportSections{}, fmt.Errorf("failed to fetch pinned messages: %w", err)
+		}
+		reverseMessages(pinned)
+		if err := a.nameSenders(ctx, plan, pinned); err != nil {
+			return ExportSections{}, err
+		}
+		sections.Pinned = pinned
+	}
+	if plan.readContext != nil {
+		readContext, err := plan.readContext(ctx, progress)
+		if err != nil {
+			return ExportSections{}, fmt.Errorf("failed to fetch context messages: %w", err)
+		}
+		reverseMessages(readContext)
+		if err := a.nameSenders(ctx, plan, readContext); err != nil {
+			return ExportSections{}, err
+		}
+		sections.Context = readContext
+	}
+	if plan.chatInfo != nil {
+		// The header is optional; a long fetch is not thrown away for it.
+		info, err := plan.chatInfo(ctx, senderIDs(sections.Pinned, sections.Context, messages))
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ExportSections{}, fmt.Errorf("%w: %w", telegram.ErrFetchCanceled, ctx.Err())
+		case err != nil:
+			sections.warnings = append(sections.warnings, fmt.Sprintf("failed to fetch chat info: %v", err))
+		default:
+			sections.Info = info
+		}
+	}
+	return sections, nil
+}
+
+// senderIDs returns the distinct senders of pinned, readContext and
+// messages in the order they first appear in the export.
+func senderIDs(pinned, readContext, messages []telegram.Message) []int64 {
+	var ids []int64
+	seen := make(map[int64]bool)
+	add := func(msg telegram.Message) {
+		if msg.SenderID == 0 || seen[msg.SenderID] {
+			return
+		}
+		seen[msg.SenderID] = true
+		ids = append(ids, msg.SenderID)
+	}
+	for _, msg := range pinned {
+		add(msg)
+	}
+	for _, msg := range readContext {
+		add(msg)
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		add(messages[i])
+	}
+	return ids
+}
+
+// loadResumeCursor returns the saved cursor for plan when --resume is set.
+func (a *App) loadResumeCursor(plan fetchPlan, opts RunOptions) (*telegram.Cursor, error) {
+	if !opts.checkpointed() {
+		return nil, nil
+	}
+	cp, err := a.checkpoints.Load(plan.checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil {
+		if opts.Resume {
+			fmt.Fprintln(os.Stderr, "No checkpoint found; starting from scratch.")
+		}
+		return nil, nil
+	}
+	if !opts.Resume {
+		fmt.Fprintln(os.Stderr, "Found a checkpoint for this export; starting over (use --resume to continue it).")
+		return nil, nil
+	}
+	fmt.Fprintf(os.Stderr, "Resuming from checkpoint (%d messages already fetched).\n", len(cp.Messages))
+	return cp.cursor(), nil
+}
+
+// fetchWithCheckpoint runs the plan and saves a checkpoint after every batch,
+// so an interrupted fetch can later continue from resume. Cursors hold all
+// messages fetched so far; the first save of a run rewrites the checkpoint,
+// later ones only append the new messages.
+func (a *App) fetchWithCheckpoint(ctx context.Context, plan fetchPlan, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+	saved := 0
+	return plan.fetch(ctx, resume, func(update telegram.ProgressUpdate) {
+		if update.Cursor != nil {
+			if err := a.checkpoints.Save(plan.checkpoint, *update.Cursor, saved); err != nil {
+				update.Phase = err.Error()
+			} else {
+				saved = len(update.Cursor.Messages)
+			}
+		}
+		if progress != nil {
+			progress(update)
+		}
+	})
+}
+
 func findChatByID(chats []telegram.Chat, chatID int64) *telegram.Chat {
 	for i := range chats {
 		if chats[i].ID == chatID {
@@ -128,6 +555,67 @@ func findChatByID(chats []telegram.Chat, chatID int64) *telegram.Chat {
 	return nil
 }
 
+// hashtagChats resolves the chats a hashtag digest searches: the chat with
+// opts.ChatID, the chats of opts.Folder, or nil for all chats.
+func (a *App) hashtagChats(ctx context.Context, opts RunOptions) ([]telegram.Chat, error) {
+	if opts.ChatID == 0 && opts.Folder == "" {
+		return nil, nil
+	}
+	chats, err := a.tgClient.GetDialogs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dialogs: %w", err)
+	}
+	if opts.ChatID != 0 {
+		selectedChat := findChatByID(chats, opts.ChatID)
+		if selectedChat == nil {
+			return nil, fmt.Errorf("chat with id %d not found; accepts raw ID or -100... format", opts.ChatID)
+		}
+		return []telegram.Chat{*selectedChat}, nil
+	}
+	folder, err := a.findFolder(ctx, opts.Folder)
+	if err != nil {
+		return nil, err
+	}
+	return folderChats(*folder, chats), nil
+}
+
+// findFolder returns the user's chat folder with the given ID or title.
+func (a *App) findFolder(ctx context.Context, ref string) (*telegram.Folder, error) {
+	folders, err := a.tgClient.GetFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return telegram.FindFolder(folders, ref)
+}
+
+// folderChats returns the chats of a folder that can be exported.
+func folderChats(folder telegram.Folder, chats []telegram.Chat) []telegram.Chat {
+	selected := []telegram.Chat{}
+	for _, chat := range chats {
+		if folder.Contains(chat) && chat.ExportError() == nil {
+			selected = append(selected, chat)
+		}
+	}
+	return selected
+}
+
+// findSavedMessages returns the user's own chat, or nil.
+func findSavedMessages(chats []telegram.Chat) *telegram.Chat {
+	for i := range chats {
+		if chats[i].IsSelf {
+			return &chats[i]
+		}
+	}
+	return nil
+}
+
+// savedDigest reports whether an export of selectedChat becomes a Saved
+// Messages digest. Saved Messages has no unread items, so unread and date
+// range exports of it list the saved items by source instead.
+func savedDigest(selectedChat telegram.Chat, opts RunOptions) bool {
+	return selectedChat.IsSelf && (opts.unreadMode() || opts.UseDateRange) && !opts.AdminLog
+}
+
 func selectForumTopic(topics []telegram.Topic, topicID int, topicTitle string) (*telegram.Topic, error) {
 	if topicID != 0 {
 		for i := range topics {
@@ -180,21 +668,24 @@ func formatTopicCandidates(topics []telegram.Topic) string {
 	return strings.Join(parts, ", ")
 }
 
-func (a *App) exportMessages(exportTitle string, messages []telegram.Message, opts RunOptions) (string, error) {
+func (a *App) exportMessages(exportTitle string, messages []telegram.Message, sections ExportSections, opts RunOptions) (string, error) {
 	// Sort messages by date (oldest first)
 	// fetched messages are usually newest first from history?
 	// `GetUnreadMessages` implementation appended them as they came.
 	// If we used `MessagesGetHistory` without offset loop, we got newest first.
 	// Let's reverse to have chronological order for reading.
-	for i := len(messages)/2 - 1; i >= 0; i-- {
-		opp := len(messages) - 1 - i
-		messages[i], messages[opp] = messages[opp], messages[i]
-	}
+	reverseMessages(messages)
 
 	// Export to file
 	// format: ChatName_Date.txt or ChatName_TopicName_Date.txt
 	// date range format: ChatName_YYYY-MM-DD_to_YYYY-MM-DD.txt
-	filename, err := a.exporter.Export(exportTitle, messages, opts)
+	var filename string
+	var err error
+	if exporter, ok := a.exporter.(SectionExporter); ok {
+		filename, err = exporter.ExportWithSections(exportTitle, messages, sections, opts)
+	} else {
+		filename, err = a.exporter.Export(exportTitle, messages, opts)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to export: %w", err)
 	}
@@ -202,12 +693,24 @@ func (a *App) exportMessages(exportTitle string, messages []telegram.Message, op
 	return filename, nil
 }
 
+func reverseMessages(messages []telegram.Message) {
+	for i := len(messages)/2 - 1; i >= 0; i-- {
+		opp := len(messages) - 1 - i
+		messages[i], messages[opp] = messages[opp], messages[i]
+	}
+}
+
 type markReadResult struct {
 	Attempted bool
 	Err       error
+	// Skipped explains why an unread export was not marked as read.
+	Skipped string
 }
 
 func (a *App) markMessagesAsRead(ctx context.Context, selectedChat telegram.Chat, selectedTopic *telegram.Topic, messages []telegram.Message, opts RunOptions) markReadResult {
+	if opts.UnreadMentions || opts.UnreadReactions {
+		return a.markContentsAsRead(ctx, selectedChat, selectedTopic, messages, opts)
+	}
 	// Mark as read
 	maxID := 0
 	for _, msg := range messages {
@@ -215,17 +718,74 @@ func (a *App) markMessagesAsRead(ctx context.Context, selectedChat telegram.Chat
 			maxID = msg.ID
 		}
 	}
+	return a.markAsReadUpTo(ctx, selectedChat, selectedTopic, maxID, opts)
+}
+
+func (a *App) markAsReadUpTo(ctx context.Context, selectedChat telegram.Chat, selectedTopic *telegram.Topic, maxID int, opts RunOptions) markReadResult {
+	// Partial exports never mark as read: messages between the last fetched
+	// one and the previous read position were never exported.
+	if maxID == 0 || opts.Partial {
+		return markReadResult{}
+	}
+	var err error
+	switch {
+	case !opts.unreadMode():
+		return markReadResult{}
+	case selectedChat.ReadOnly():
+		return readOnlyResult(selectedChat)
+	case selectedTopic != nil:
+		err = a.tgClient.MarkTopicAsRead(ctx, selectedChat.ID, selectedTopic.ID, maxID)
+	default:
+		err = a.tgClient.MarkAsRead(ctx, selectedChat, maxID)
+	}
+	return markReadResult{Attempted: true, Err: err}
+}
 
-	if maxID > 0 && !opts.UseDateRange {
-		var err error
-		if selectedTopic != nil {
-			err = a.tgClient.MarkTopicAsRead(ctx, selectedChat.ID, selectedTopic.ID, maxID)
-		} else {
-			err = a.tgClient.MarkAsRead(ctx, selectedChat, maxID)
+// markContentsAsRead marks the mentions or reactions of the exported
+// messages as read. When the export covered every unread mention or
+// reaction of the chat (or topic), they are read with messages.readMentions
+// or messages.readReactions; otherwise only the exported messages are read,
+// leaving the rest unread.
+func (a *App) markContentsAsRead(ctx context.Context, selectedChat telegram.Chat, selectedTopic *telegram.Topic, messages []telegram.Message, opts RunOptions) markReadResult {
+	if len(messages) == 0 || opts.Partial {
+		return markReadResult{}
+	}
+	if selectedChat.ReadOnly() {
+		return readOnlyResult(selectedChat)
+	}
+	ids := make([]int, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+	}
+	if !coversUnreadMarks(selectedChat, selectedTopic, len(ids), opts) {
+		return markReadResult{Attempted: true, Err: a.tgClient.ReadMessageContents(ctx, selectedChat, ids)}
+	}
+	topicID := topicIDOf(selectedTopic)
+	if opts.UnreadReactions {
+		return markReadResult{Attempted: true, Err: a.tgClient.ReadReactions(ctx, selectedChat.ID, topicID)}
+	}
+	return markReadResult{Attempted: true, Err: a.tgClient.ReadMentions(ctx, selectedChat.ID, topicID)}
+}
+
+// coversUnreadMarks reports whether exported messages include every unread
+// mention, or with --reactions every message with unread reactions, that
+// the chat or topic counted.
+func coversUnreadMarks(selectedChat telegram.Chat, selectedTopic *telegram.Topic, exported int, opts RunOptions) bool {
+	unread := selectedChat.UnreadMentions
+	if opts.UnreadReactions {
+		unread = selectedChat.UnreadReactions
+	}
+	if selectedTopic != nil {
+		unread = selectedTopic.UnreadMentions
+		if opts.UnreadReactions {
+			unread = selectedTopic.UnreadReactions
 		}
-		return markReadResult{Attempted: true, Err: err}
 	}
-	return markReadResult{}
+	return unread > 0 && exported >= unread
+}
+
+func readOnlyResult(chat telegram.Chat) markReadResult {
+	return markReadResult{Skipped: fmt.Sprintf("Chat is read-only (%s); messages were not marked as read.", chat.Status())}
 }
 
 func sanitizeFilename(name string) string {
@@ -239,7 +799,7 @@ func sanitizeFilename(name string) string {
 
 func formatMarkReadStatus(result markReadResult) string {
 	if !result.Attempted {
-		return ""
+		return result.Skipped
 	}
 	if result.Err != nil {
 		return fmt.Sprintf("Warning: failed to mark messages as read: %v", result.Err)
@@ -249,6 +809,9 @@ func formatMarkReadStatus(result markReadResult) string {
 
 func printMarkReadStatus(result markReadResult) {
 	if !result.Attempted {
+		if result.Skipped != "" {
+			fmt.Fprintln(os.Stderr, result.Skipped)
+		}
 		return
 	}
 	fmt.Fprintln(os.Stderr, "Marking messages as read...")
@@ -1,7 +1,13 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+
+	"cli-tg-chat-summary/internal/telegram"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -112,3 +118,189 @@ func TestSanitizeFilename_EdgeCases(t *testing.T) {
 		t.Errorf("sanitizeFilename should have modified dangerous path: %s", result)
 	}
 }
+
+func TestRunOptions_Checkpointed(t *testing.T) {
+	tests := []struct {
+		name string
+		opts RunOptions
+		want bool
+	}{
+		{name: "sequential", opts: RunOptions{UseDateRange: true}, want: true},
+		{name: "stream", opts: RunOptions{Stream: true}, want: false},
+		{name: "parallel date range", opts: RunOptions{UseDateRange: true, Parallel: 4}, want: false},
+		{name: "parallel unread", opts: RunOptions{Parallel: 4}, want: true},
+		{name: "saved messages", opts: RunOptions{Saved: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.checkpointed(); got != tt.want {
+				t.Fatalf("checkpointed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFetchPlan_Pinned(t *testing.T) {
+	a := &App{}
+	chat := telegram.Chat{ID: 1, Title: "Team"}
+
+	plan, err := a.buildFetchPlan(chat, nil, RunOptions{Pinned: true})
+	if err != nil {
+		t.Fatalf("buildFetchPlan error: %v", err)
+	}
+	if plan.checkpoint.Mode != "pinned" || plan.pinned != nil {
+		t.Fatalf("unexpected pinned plan: %+v", plan.checkpoint)
+	}
+
+	plan, err = a.buildFetchPlan(chat, nil, RunOptions{WithPinned: true})
+	if err != nil {
+		t.Fatalf("buildFetchPlan error: %v", err)
+	}
+	if plan.checkpoint.Mode != "unread" || plan.pinned == nil {
+		t.Fatal("expected unread plan with a pinned section")
+	}
+}
+
+func TestBuildFetchPlan_LastAnchoredAtTopMessage(t *testing.T) {
+	a := &App{}
+	chat := telegram.Chat{ID: 1, Title: "Team", TopMessageID: 500}
+
+	plan, err := a.buildFetchPlan(chat, nil, RunOptions{Last: 50})
+	if err != nil {
+		t.Fatalf("buildFetchPlan error: %v", err)
+	}
+	if plan.checkpoint.Mode != "last" || plan.checkpoint.TopMessageID != 500 {
+		t.Fatalf("unexpected last checkpoint: %+v", plan.checkpoint)
+	}
+
+	forum := telegram.Chat{ID: 2, Title: "Forum", IsForum: true, TopMessageID: 900}
+	topic := &telegram.Topic{ID: 7, Title: "Releases", TopMessageID: 640}
+	plan, err = a.buildFetchPlan(forum, topic, RunOptions{Last: 50})
+	if err != nil {
+		t.Fatalf("buildFetchPlan error: %v", err)
+	}
+	if plan.checkpoint.TopicID != 7 || plan.checkpoint.TopMessageID != 640 {
+		t.Fatalf("unexpected topic checkpoint: %+v", plan.checkpoint)
+	}
+}
+
+func TestBuildFetchPlan_MinViews(t *testing.T) {
+	a := &App{}
+	opts := RunOptions{UseDateRange: true, MinViews: 100}
+	if _, err := a.buildFetchPlan(telegram.Chat{ID: 1, Title: "Group"}, nil, opts); err == nil {
+		t.Fatal("expected --min-views to be rejected for a group")
+	}
+	channel := telegram.Chat{ID: 2, Title: "News", IsChannel: true, IsBroadcast: true}
+	if _, err := a.buildFetchPlan(channel, nil, opts); err != nil {
+		t.Fatalf("buildFetchPlan error: %v", err)
+	}
+}
+
+func TestBuildFetchPlan_AdminLog(t *testing.T) {
+	a := &App{}
+	opts := RunOptions{AdminLog: true, AdminLogEvents: []telegram.AdminLogEvent{telegram.AdminLogBans, telegram.AdminLogEdits}}
+
+	if _, err := a.buildFetchPlan(telegram.Chat{ID: 1, Title: "Group"}, nil, opts); err == nil {
+		t.Fatal("expected basic group to be rejected")
+	}
+
+	forum := telegram.Chat{ID: 2, Title: "Forum", IsChannel: true, IsForum: true}
+	plan, err := a.buildFetchPlan(forum, nil, opts)
+	if err != nil {
+		t.Fatalf("buildFetchPlan error: %v", err)
+	}
+	if plan.checkpoint.Mode != "admin-log" || plan.checkpoint.Events != "bans,edits" {
+		t.Fatalf("unexpected admin log checkpoint: %+v", plan.checkpoint)
+	}
+	if opts.unreadMode() {
+		t.Fatal("expected admin log not to be an unread mode")
+	}
+}
+
+func TestSavedDigest(t *testing.T) {
+	self := telegram.Chat{ID: 1, IsUser: true, IsSelf: true}
+	if !savedDigest(self, RunOptions{}) || !savedDigest(self, RunOptions{UseDateRange: true}) {
+		t.Fatal("expected unread and date range exports of Saved Messages to be digests")
+	}
+	if savedDigest(self, RunOptions{Last: 10}) || savedDigest(self, RunOptions{Pinned: true}) {
+		t.Fatal("expected last N and pinned exports to keep the plain history")
+	}
+	if savedDigest(telegram.Chat{ID: 2, IsUser: true}, RunOptions{}) {
+		t.Fatal("expected other chats not to be digests")
+	}
+	if (RunOptions{Saved: true}).unreadMode() {
+		t.Fatal("expected the digest not to mark anything as read")
+	}
+}
+
+func TestFilterMinViews(t *testing.T) {
+	messages := []telegram.Message{{ID: 1, Views: 50}, {ID: 2, Views: 150}, {ID: 3}}
+
+	if got := filterMinViews(messages, 0); len(got) != 3 {
+		t.Fatalf("expected all messages without a threshold, got %d", len(got))
+	}
+	got := filterMinViews(messages, 100)
+	if len(got) != 1 || got[0].ID != 2 {
+		t.Fatalf("unexpected filtered messages: %+v", got)
+	}
+}
+
+func TestSenderIDs(t *testing.T) {
+	pinned := []telegram.Message{{SenderID: 5}}
+	readContext := []telegram.Message{{SenderID: 3}, {SenderID: 5}}
+	// Fetched messages are newest first.
+	messages := []telegram.Message{{SenderID: 1}, {SenderID: 0}, {SenderID: 2}, {SenderID: 3}}
+
+	got := senderIDs(pinned, readContext, messages)
+	want := []int64{5, 3, 2, 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestFetchSections_ChatInfoIsOptional(t *testing.T) {
+	a := &App{}
+	plan := fetchPlan{chatInfo: func(context.Context, []int64) (*telegram.ChatInfo, error) {
+		return nil, errors.New("CHAT_ADMIN_REQUIRED")
+	}}
+
+	sections, err := a.fetchSections(context.Background(), plan, nil, nil)
+	if err != nil {
+		t.Fatalf("expected chat info to be optional, got %v", err)
+	}
+	if sections.Info != nil || len(sections.warningLines()) != 1 || !strings.Contains(sections.warningLines()[0], "CHAT_ADMIN_REQUIRED") {
+		t.Fatalf("unexpected sections: %+v", sections)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := a.fetchSections(ctx, plan, nil, nil); !errors.Is(err, telegram.ErrFetchCanceled) {
+		t.Fatalf("expected ErrFetchCanceled, got %v", err)
+	}
+}
+
+func TestCoversUnreadMarks(t *testing.T) {
+	chat := telegram.Chat{ID: 1, Title: "Team", UnreadMentions: 3, UnreadReactions: 1}
+	topic := &telegram.Topic{ID: 7, Title: "Releases", UnreadMentions: 2}
+
+	tests := []struct {
+		name     string
+		topic    *telegram.Topic
+		exported int
+		opts     RunOptions
+		want     bool
+	}{
+		{name: "all mentions", exported: 3, opts: RunOptions{UnreadMentions: true}, want: true},
+		{name: "some mentions", exported: 2, opts: RunOptions{UnreadMentions: true}, want: false},
+		{name: "all reactions", exported: 1, opts: RunOptions{UnreadReactions: true}, want: true},
+		{name: "all topic mentions", topic: topic, exported: 2, opts: RunOptions{UnreadMentions: true}, want: true},
+		{name: "unknown topic reactions", topic: topic, exported: 1, opts: RunOptions{UnreadReactions: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := coversUnreadMarks(chat, tt.topic, tt.exported, tt.opts); got != tt.want {
+				t.Fatalf("coversUnreadMarks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cli-tg-chat-summary/internal/telegram"
+	"cli-tg-chat-summary/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// batchJob is one chat or topic of a multi-select export. Every job is
+// exported to its own file and marked as read on its own.
+type batchJob struct {
+	chat  telegram.Chat
+	topic *telegram.Topic
+	opts  RunOptions
+	plan  fetchPlan
+	// sections is filled by the fetch of the job.
+	sections ExportSections
+	// skip explains why the job cannot be exported in the chosen mode.
+	skip string
+}
+
+func (j batchJob) title() string {
+	if j.topic != nil {
+		return j.topic.Title
+	}
+	return j.chat.Title
+}
+
+type batchResultMsg struct {
+	index  int
+	result fetchResult
+}
+
+// chatJobs returns the jobs of the chats selected in the chat list. Forum
+// chats are exported with all their topics in unread and date range modes,
+// since no topic was chosen; other modes need a topic and skip them.
+func chatJobs(chats []telegram.Chat, opts RunOptions) []batchJob {
+	jobs := make([]batchJob, 0, len(chats))
+	for _, chat := range chats {
+		job := batchJob{chat: chat, opts: opts}
+		job.opts.Saved = savedDigest(chat, job.opts)
+		if chat.IsForum && !job.opts.AdminLog {
+			if job.opts.unreadMode() || job.opts.UseDateRange {
+				job.opts.AllTopics = true
+			} else {
+				job.skip = "forum skipped: this mode needs a topic; open the forum to select its topics"
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// topicJobs returns the jobs of the topics of forum selected in the topic
+// list.
+func topicJobs(forum telegram.Chat, topics []telegram.Topic, opts RunOptions) []batchJob {
+	jobs := make([]batchJob, 0, len(topics))
+	for i := range topics {
+		jobs = append(jobs, batchJob{chat: forum, topic: &topics[i], opts: opts})
+	}
+	return jobs
+}
+
+// startBatch exports jobs one after another with a combined progress view.
+// Batch exports are never streamed and do not ask to resume checkpoints;
+// --resume continues every job that has one.
+func (m appModel) startBatch(title string, jobs []batchJob) (tea.Model, tea.Cmd) {
+	titles := make([]string, len(jobs))
+	for i := range jobs {
+		jobs[i].opts.Stream = false
+		titles[i] = jobs[i].title()
+	}
+	m.batch = jobs
+	m.batchView = tui.NewBatchModel(title, titles)
+	m.selectedChat = nil
+	m.selectedTopic = nil
+	m.state = stateBatch
+	return m.startBatchJob(0)
+}
+
+// startBatchJob starts the fetch of the first job from index on that can be
+// exported, or shows the final status when none is left.
+func (m appModel) startBatchJob(index int) (tea.Model, tea.Cmd) {
+	for ; index < len(m.batch); index++ {
+		job := &m.batch[index]
+		if job.skip != "" {
+			m.batchView = m.batchView.Finish(index, job.skip, true)
+			continue
+		}
+		plan, err := m.app.buildFetchPlan(job.chat, job.topic, job.opts)
+		if err == nil {
+			err = job.chat.ExportError()
+		}
+		var resume *telegram.Cursor
+		if err == nil {
+			resume, err = m.app.batchResume(plan, job.opts)
+		}
+		if err != nil {
+			m.batchView = m.batchView.Finish(index, err.Error(), true)
+			continue
+		}
+		job.plan = plan
+
+		handle := m.app.startFetchWithProgress(FetchOpts{Ctx: m.ctx, Title: plan.progressTitle}, func(ctx context.Context, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			messages, err := m.app.fetchWithCheckpoint(ctx, plan, resume, progress)
+			if err != nil || len(messages) == 0 {
+				return messages, err
+			}
+			if err := m.app.refreshPolls(ctx, plan, messages); err != nil {
+				return messages, err
+			}
+			if err := m.app.nameSenders(ctx, plan, messages); err != nil {
+				return messages, err
+			}
+			job.sections, err = m.app.fetchSections(ctx, plan, filterMinViews(messages, job.opts.MinViews), progress)
+			return messages, err
+		})
+		m.fetchHandle = &handle
+		var cmd tea.Cmd
+		m.batchView, cmd = m.batchView.Start(index, handle.msgCh)
+		return m, tea.Batch(cmd, waitForBatchResult(index, handle.resultCh))
+	}
+	m.batchView = m.batchView.Complete()
+	return m, nil
+}
+
+// handleBatchResult exports the messages of a finished job and starts the
+// next one. A canceled fetch stops the batch; its checkpoint is kept.
+func (m appModel) handleBatchResult(msg batchResultMsg) (tea.Model, tea.Cmd) {
+	m.cancelFetch()
+	result := msg.result
+	switch {
+	case errors.Is(result.err, telegram.ErrFetchCanceled):
+		m.batchView = m.batchView.Finish(msg.index, "canceled; nothing was exported", true).Skip("canceled").Complete()
+		return m, nil
+	case result.err != nil:
+		m.batchView = m.batchView.Finish(msg.index, result.err.Error(), true)
+	default:
+		status, err := m.app.exportBatchJob(m.ctx, m.batch[msg.index], result.messages)
+		m.batchView = m.batchView.Finish(msg.index, status, err != nil)
+	}
+	return m.startBatchJob(msg.index + 1)
+}
+
+// exportBatchJob writes the messages of one job to its own file and marks
+// them as read like a single export would, and describes the outcome.
+// Messages skipped by --min-views are marked as read as well.
+func (a *App) exportBatchJob(ctx context.Context, job batchJob, messages []telegram.Message) (string, error) {
+	if len(messages) == 0 {
+		if err := a.checkpoints.Remove(job.plan.checkpoint); err != nil {
+			return err.Error(), err
+		}
+		return "no text messages found", nil
+	}
+	var parts []string
+	exported := filterMinViews(messages, job.opts.MinViews)
+	if len(exported) == 0 {
+		parts = append(parts, fmt.Sprintf("no messages with at least %d views", job.opts.MinViews))
+	} else {
+		filename, err := a.exportMessages(job.plan.exportTitle, exported, job.sections, job.opts)
+		if err != nil {
+			return err.Error(), err
+		}
+		parts = append(parts, fmt.Sprintf("%d messages to %s", countMessages(exported), filename))
+	}
+	if job.plan.digest != nil {
+		parts = append(parts, a.markDigestAsRead(ctx, job.plan.digest, job.opts))
+	} else {
+		parts = append(parts, formatMarkReadStatus(a.markMessagesAsRead(ctx, job.chat, job.topic, messages, job.opts)))
+	}
+	if err := a.checkpoints.Remove(job.plan.checkpoint); err != nil {
+		parts = append(parts, "Warning: "+err.Error())
+	}
+	parts = append(parts, job.sections.warningLines()...)
+	var status []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			status = append(status, strings.ReplaceAll(part, "\n", "; "))
+		}
+	}
+	return strings.Join(status, "; "), nil
+}
+
+// batchResume returns the checkpoint cursor of plan when --resume was
+// given; batch exports do not ask for every job.
+func (a *App) batchResume(plan fetchPlan, opts RunOptions) (*telegram.Cursor, error) {
+	if !opts.Resume || !opts.checkpointed() {
+		return nil, nil
+	}
+	cp, err := a.checkpoints.Load(plan.checkpoint)
+	if err != nil || cp == nil {
+		return nil, err
+	}
+	return cp.cursor(), nil
+}
+
+func waitForBatchResult(index int, resultCh <-chan fetchResult) tea.Cmd {
+	return func() tea.Msg {
+		return batchResultMsg{index: index, result: <-resultCh}
+	}
+}
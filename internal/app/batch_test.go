@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+func TestChatJobs(t *testing.T) {
+	chats := []telegram.Chat{
+		{ID: 1, Title: "Team"},
+		{ID: 2, Title: "Forum", IsForum: true},
+		{ID: 3, Title: "Saved Messages", IsSelf: true, IsUser: true},
+	}
+
+	jobs := chatJobs(chats, RunOptions{})
+	if len(jobs) != 3 {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+	if jobs[0].opts.AllTopics || jobs[0].opts.Saved {
+		t.Fatalf("unexpected options for a group: %+v", jobs[0].opts)
+	}
+	if !jobs[1].opts.AllTopics {
+		t.Fatal("expected the forum to be exported with all topics")
+	}
+	if !jobs[2].opts.Saved {
+		t.Fatal("expected Saved Messages to be exported as a digest")
+	}
+
+	jobs = chatJobs(chats[1:2], RunOptions{AdminLog: true})
+	if jobs[0].opts.AllTopics || jobs[0].skip != "" {
+		t.Fatal("expected the admin log of a forum to skip topics")
+	}
+
+	jobs = chatJobs(chats[1:2], RunOptions{UseDateRange: true})
+	if !jobs[0].opts.AllTopics || jobs[0].skip != "" {
+		t.Fatal("expected a date range export of the forum with all topics")
+	}
+}
+
+func TestStartBatch_ForumInLastMode(t *testing.T) {
+	chats := []telegram.Chat{{ID: 2, Title: "Forum", IsForum: true}}
+	jobs := chatJobs(chats, RunOptions{Last: 50})
+	if jobs[0].opts.AllTopics || jobs[0].skip == "" {
+		t.Fatalf("expected the forum to be skipped in last N mode, got %+v", jobs[0])
+	}
+
+	m := appModel{app: &App{}}
+	model, _ := m.startBatch("Exporting 1 chats", jobs)
+	if view := model.(appModel).batchView.View(); !strings.Contains(view, "this mode needs a topic") {
+		t.Fatalf("expected a per-item status for the forum, got %q", view)
+	}
+}
+
+func TestTopicJobs(t *testing.T) {
+	forum := telegram.Chat{ID: 2, Title: "Forum", IsForum: true}
+	topics := []telegram.Topic{{ID: 7, Title: "Releases"}, {ID: 9, Title: "Ops"}}
+
+	jobs := topicJobs(forum, topics, RunOptions{})
+	if len(jobs) != 2 || jobs[0].topic.ID != 7 || jobs[1].title() != "Ops" || jobs[1].chat.ID != 2 {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+}
+
+func TestExportBatchJob(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+	a := NewWithExporter(nil, nil, env.Exporter)
+	a.checkpoints = newCheckpointStore(t.TempDir())
+
+	opts := RunOptions{UseDateRange: true, Since: now.AddDate(0, 0, -1), Until: now}
+	job := batchJob{chat: telegram.Chat{ID: 1, Title: "Team"}, opts: opts, plan: fetchPlan{exportTitle: "Team", checkpoint: checkpointKey{ChatID: 1, Mode: "range"}}}
+	messages := []telegram.Message{
+		{ID: 2, SenderID: 10, Date: now, Text: "second"},
+		{ID: 1, SenderID: 10, Date: now.Add(-time.Minute), Text: "first"},
+	}
+
+	status, err := a.exportBatchJob(context.Background(), job, messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(status, "2 messages to exports/Team_") {
+		t.Fatalf("unexpected status: %q", status)
+	}
+	if !strings.Contains(env.Buffer.String(), "first\n  second") {
+		t.Fatalf("unexpected export: %q", env.Buffer.String())
+	}
+
+	status, err = a.exportBatchJob(context.Background(), job, nil)
+	if err != nil || status != "no text messages found" {
+		t.Fatalf("unexpected status for an empty job: %q, %v", status, err)
+	}
+}
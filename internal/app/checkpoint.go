@@ -0,0 +1,167 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+// checkpointKey identifies a fetch so a saved checkpoint is only reused
+// for the exact same chat, topic, mode and range.
+// Range bounds are kept at day precision like export filenames, so an
+// open-ended range ("until now") still matches when resumed the same day;
+// the resumed fetch then ends where the original one did (checkpoint.Until).
+// Exports counted from the newest message record it as TopMessageID, so
+// they are not resumed once newer messages arrived.
+type checkpointKey struct {
+	ChatID     int64  `json:"chat_id"`
+	TopicID    int    `json:"topic_id,omitempty"`
+	Mode       string `json:"mode"`
+	LastReadID int    `json:"last_read_id,omitempty"`
+	Since      string `json:"since,omitempty"`
+	Until      string `json:"until,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+	FromID     int    `json:"from_id,omitempty"`
+	ToID       int    `json:"to_id,omitempty"`
+	Events     string `json:"events,omitempty"`
+	// TopMessageID is the newest message when the fetch started.
+	TopMessageID int `json:"top_message_id,omitempty"`
+}
+
+// checkpoint is the saved state of a fetch. On disk it is a log of
+// checkpoint records, one JSON object each: the first holds the messages of
+// the first batch and every later one only the messages of its batch, so a
+// long fetch does not rewrite everything it fetched so far.
+type checkpoint struct {
+	Key      checkpointKey      `json:"key"`
+	OffsetID int                `json:"offset_id"`
+	Messages []telegram.Message `json:"messages"`
+	// Until is the exact end of a date range, which the key only holds
+	// at day precision.
+	Until     time.Time `json:"until,omitzero"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (c checkpoint) cursor() *telegram.Cursor {
+	return &telegram.Cursor{OffsetID: c.OffsetID, Messages: c.Messages, Until: c.Until}
+}
+
+type checkpointStore struct {
+	dir string
+	now func() time.Time
+}
+
+func newCheckpointStore(dir string) *checkpointStore {
+	return &checkpointStore{dir: dir, now: time.Now}
+}
+
+func (s *checkpointStore) path(key checkpointKey) string {
+	name := fmt.Sprintf("%d", key.ChatID)
+	if key.TopicID != 0 {
+		name = fmt.Sprintf("%s_%d", name, key.TopicID)
+	}
+	return filepath.Join(s.dir, name+".json")
+}
+
+// Load returns the checkpoint saved for key, or nil when there is none
+// or the saved one belongs to a different mode or range. Every record ends
+// with a newline, so a record torn by an interrupt can only be the
+// unterminated end of the log; it is ignored, while any other unreadable
+// record is an error.
+func (s *checkpointStore) Load(key checkpointKey) (*checkpoint, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp *checkpoint
+	for len(data) > 0 {
+		line, rest, terminated := bytes.Cut(data, []byte("\n"))
+		data = rest
+		var record checkpoint
+		if err := json.Unmarshal(line, &record); err != nil {
+			if !terminated && cp != nil {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+		}
+		if cp == nil {
+			if record.Key != key {
+				return nil, nil
+			}
+			cp = &record
+			continue
+		}
+		cp.OffsetID = record.OffsetID
+		cp.Messages = append(cp.Messages, record.Messages...)
+		cp.UpdatedAt = record.UpdatedAt
+	}
+	return cp, nil
+}
+
+// Save records cursor for key. saved is the number of leading messages of
+// cursor that an earlier Save of the same run already recorded; only the
+// rest is appended. With saved 0 the checkpoint is rewritten in one go,
+// which also drops a record torn by an earlier interrupt.
+func (s *checkpointStore) Save(key checkpointKey, cursor telegram.Cursor, saved int) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoints directory: %w", err)
+	}
+	if saved <= 0 || saved > len(cursor.Messages) {
+		saved = 0
+	}
+	data, err := json.Marshal(checkpoint{
+		Key:       key,
+		OffsetID:  cursor.OffsetID,
+		Messages:  cursor.Messages[saved:],
+		Until:     cursor.Until,
+		UpdatedAt: s.now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	data = append(data, '\n')
+
+	path := s.path(key)
+	if saved == 0 {
+		// Write to a temp file first so an interrupt never leaves a torn
+		// checkpoint.
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *checkpointStore) Remove(key checkpointKey) error {
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
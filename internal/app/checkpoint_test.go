@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+func TestCheckpointStore_SaveLoadRemove(t *testing.T) {
+	store := newCheckpointStore(t.TempDir())
+	key := checkpointKey{ChatID: 42, TopicID: 7, Mode: "date-range", Since: "2025-01-01", Until: "2025-01-31"}
+	msgTime := time.Date(2025, 1, 20, 10, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 1, 31, 14, 30, 0, 0, time.UTC)
+	cursor := telegram.Cursor{
+		OffsetID: 150,
+		Messages: []telegram.Message{{ID: 200, Date: msgTime, Text: "hello", SenderID: 10}},
+		Until:    until,
+	}
+
+	if err := store.Save(key, cursor, 0); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+
+	cp, err := store.Load(key)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if cp == nil {
+		t.Fatal("expected checkpoint, got nil")
+	}
+	if cp.OffsetID != 150 || len(cp.Messages) != 1 {
+		t.Fatalf("unexpected checkpoint: %+v", cp)
+	}
+	if cp.Messages[0].Text != "hello" || !cp.Messages[0].Date.Equal(msgTime) {
+		t.Fatalf("unexpected message: %+v", cp.Messages[0])
+	}
+	if resume := cp.cursor(); !resume.Until.Equal(until) {
+		t.Fatalf("expected the exact end of the range to be kept, got %v", resume.Until)
+	}
+
+	if err := store.Remove(key); err != nil {
+		t.Fatalf("remove error: %v", err)
+	}
+	cp, err = store.Load(key)
+	if err != nil {
+		t.Fatalf("load after remove error: %v", err)
+	}
+	if cp != nil {
+		t.Fatalf("expected no checkpoint after remove, got %+v", cp)
+	}
+	if err := store.Remove(key); err != nil {
+		t.Fatalf("removing a missing checkpoint should not fail: %v", err)
+	}
+}
+
+func TestCheckpointStore_SaveAppendsBatches(t *testing.T) {
+	store := newCheckpointStore(t.TempDir())
+	key := checkpointKey{ChatID: 42, Mode: "unread", LastReadID: 5}
+	first := []telegram.Message{{ID: 30, Text: "c"}, {ID: 29, Text: "b"}}
+	if err := store.Save(key, telegram.Cursor{OffsetID: 29, Messages: first}, 0); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	all := append(first, telegram.Message{ID: 28, Text: "a"})
+	if err := store.Save(key, telegram.Cursor{OffsetID: 28, Messages: all}, len(first)); err != nil {
+		t.Fatalf("append error: %v", err)
+	}
+
+	data, err := os.ReadFile(store.path(key))
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if strings.Count(string(data), `"Text":"c"`) != 1 {
+		t.Fatalf("expected earlier messages to be written once, got %s", data)
+	}
+
+	cp, err := store.Load(key)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if cp == nil || cp.OffsetID != 28 || len(cp.Messages) != 3 || cp.Messages[2].ID != 28 {
+		t.Fatalf("unexpected checkpoint: %+v", cp)
+	}
+
+	// A record torn by an interrupt is dropped, the batches before it kept.
+	if err := os.WriteFile(store.path(key), append(data, `{"key":`...), 0644); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	cp, err = store.Load(key)
+	if err != nil {
+		t.Fatalf("load with torn tail error: %v", err)
+	}
+	if cp == nil || cp.OffsetID != 28 || len(cp.Messages) != 3 {
+		t.Fatalf("unexpected checkpoint with torn tail: %+v", cp)
+	}
+
+	// Starting over replaces the log.
+	if err := store.Save(key, telegram.Cursor{OffsetID: 30, Messages: first[:1]}, 0); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	cp, err = store.Load(key)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if cp == nil || cp.OffsetID != 30 || len(cp.Messages) != 1 {
+		t.Fatalf("unexpected restarted checkpoint: %+v", cp)
+	}
+}
+
+func TestFetchWithCheckpoint_ResumeAfterTornTail(t *testing.T) {
+	store := newCheckpointStore(t.TempDir())
+	a := &App{checkpoints: store}
+	key := checkpointKey{ChatID: 42, Mode: "unread", LastReadID: 5}
+	first := []telegram.Message{{ID: 30, Text: "c"}, {ID: 29, Text: "b"}}
+	if err := store.Save(key, telegram.Cursor{OffsetID: 29, Messages: first}, 0); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	// The first run was interrupted while appending a batch.
+	f, err := os.OpenFile(store.path(key), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	if _, err := f.WriteString(`{"key":{"chat_id":42`); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	_ = f.Close()
+
+	cp, err := store.Load(key)
+	if err != nil || cp == nil {
+		t.Fatalf("expected the checkpoint before the torn record, got %+v, %v", cp, err)
+	}
+	// The resumed run saves two batches and is interrupted again.
+	plan := fetchPlan{
+		checkpoint: key,
+		fetch: func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			messages := append([]telegram.Message(nil), resume.Messages...)
+			for _, id := range []int{28, 27} {
+				messages = append(messages, telegram.Message{ID: id})
+				progress(telegram.ProgressUpdate{Cursor: &telegram.Cursor{OffsetID: id, Messages: messages}})
+			}
+			return messages, context.Canceled
+		},
+	}
+	if _, err := a.fetchWithCheckpoint(context.Background(), plan, cp.cursor(), nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected fetch error: %v", err)
+	}
+
+	cp, err = store.Load(key)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if cp == nil || cp.OffsetID != 27 || len(cp.Messages) != 4 || cp.Messages[3].ID != 27 {
+		t.Fatalf("expected the progress of the resumed run, got %+v", cp)
+	}
+}
+
+func TestCheckpointStore_LoadRejectsCorruptRecord(t *testing.T) {
+	store := newCheckpointStore(t.TempDir())
+	key := checkpointKey{ChatID: 42, Mode: "unread"}
+	if err := store.Save(key, telegram.Cursor{OffsetID: 10, Messages: []telegram.Message{{ID: 10}}}, 0); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	data, err := os.ReadFile(store.path(key))
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	// A broken record in the middle of the log is not a torn tail.
+	corrupt := append(append(data, "{broken\n"...), data...)
+	if err := os.WriteFile(store.path(key), corrupt, 0644); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if _, err := store.Load(key); err == nil {
+		t.Fatal("expected a corrupt record to be reported")
+	}
+}
+
+func TestCheckpointStore_LoadIgnoresDifferentRange(t *testing.T) {
+	store := newCheckpointStore(t.TempDir())
+	key := checkpointKey{ChatID: 42, Mode: "date-range", Since: "2025-01-01", Until: "2025-01-31"}
+	if err := store.Save(key, telegram.Cursor{OffsetID: 10}, 0); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+
+	other := key
+	other.Since = "2024-12-01"
+	cp, err := store.Load(other)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if cp != nil {
+		t.Fatalf("expected checkpoint for another range to be ignored, got %+v", cp)
+	}
+
+	unread := checkpointKey{ChatID: 42, Mode: "unread", LastReadID: 5}
+	cp, err = store.Load(unread)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if cp != nil {
+		t.Fatalf("expected checkpoint for another mode to be ignored, got %+v", cp)
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+// digestEntry is a chat, or a forum topic, exported as one section of a
+// digest. maxID is the newest exported message and stays 0 until the entry
+// was fetched.
+type digestEntry struct {
+	chat  telegram.Chat
+	topic *telegram.Topic
+	title string
+	maxID int
+}
+
+// chatDigest combines several chats or forum topics into one export. The
+// fetch fills entries with what was exported from each, so they can be
+// marked as read once the combined file is written.
+type chatDigest struct {
+	entries []digestEntry
+	// failures describe the chats and topics that could not be fetched;
+	// the digest goes on without them.
+	failures []string
+}
+
+// fetchDigest fetches the messages of every entry of digest with the plan
+// of its chat and topic, a section per entry in the order of the entries.
+func (a *App) fetchDigest(ctx context.Context, digest *chatDigest, opts RunOptions, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+	// Cursors of single chats cannot resume the digest.
+	report := func(update telegram.ProgressUpdate) {
+		if progress != nil {
+			update.Cursor = nil
+			progress(update)
+		}
+	}
+
+	// Sections are added last to first: the export reverses the messages,
+	// which come newest first within a section.
+	var all []telegram.Message
+	var failures []string
+	for i := len(digest.entries) - 1; i >= 0; i-- {
+		entry := &digest.entries[i]
+		plan, err := a.buildModePlan(entry.chat, entry.topic, opts)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("skipped %s: %v", entry.title, err))
+			continue
+		}
+		messages, err := plan.fetch(ctx, nil, report)
+		if err != nil {
+			if ctx.Err() != nil {
+				return all, err
+			}
+			// A section cut short is left out, so the entry is not marked
+			// as read past what the export holds.
+			failures = append(failures, fmt.Sprintf("skipped %s: %v", entry.title, err))
+			continue
+		}
+		for j := range messages {
+			messages[j].Group = entry.title
+			entry.maxID = max(entry.maxID, messages[j].ID)
+		}
+		all = append(all, messages...)
+	}
+	// Failures are listed in the order of the entries.
+	for i := len(failures) - 1; i >= 0; i-- {
+		digest.failures = append(digest.failures, failures[i])
+	}
+	return all, nil
+}
+
+// markDigestAsRead marks every chat and topic of an unread digest as read
+// up to its newest exported message and describes the outcome. It returns
+// an empty status for other exports, which are never marked as read.
+func (a *App) markDigestAsRead(ctx context.Context, digest *chatDigest, opts RunOptions) string {
+	if !opts.unreadMode() {
+		return ""
+	}
+	var marked, total int
+	var warnings []string
+	for _, entry := range digest.entries {
+		if entry.maxID == 0 {
+			continue
+		}
+		total++
+		result := a.markAsReadUpTo(ctx, entry.chat, entry.topic, entry.maxID, opts)
+		switch {
+		case result.Err != nil:
+			warnings = append(warnings, fmt.Sprintf("Warning: failed to mark %s as read: %v", entry.title, result.Err))
+		case result.Attempted:
+			marked++
+		case result.Skipped != "":
+			warnings = append(warnings, entry.title+": "+result.Skipped)
+		}
+	}
+	lines := append([]string{fmt.Sprintf("Marked %d of %d chats and topics as read.", marked, total)}, warnings...)
+	return strings.Join(lines, "\n")
+}
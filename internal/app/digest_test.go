@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+func TestMarkDigestAsRead_SkipsUnexported(t *testing.T) {
+	a := &App{}
+	digest := &chatDigest{entries: []digestEntry{
+		{chat: telegram.Chat{ID: 1, Title: "Stickers only"}, title: "Stickers only"},
+		{chat: telegram.Chat{ID: 2, Title: "Old group", Left: true}, title: "Old group", maxID: 40},
+	}}
+
+	status := a.markDigestAsRead(context.Background(), digest, RunOptions{Inbox: true})
+	if !strings.HasPrefix(status, "Marked 0 of 1 chats and topics as read.") || !strings.Contains(status, "Old group: Chat is read-only") {
+		t.Fatalf("unexpected status: %q", status)
+	}
+
+	if status := a.markDigestAsRead(context.Background(), digest, RunOptions{AllTopics: true, UseDateRange: true}); status != "" {
+		t.Fatalf("expected no status for date range digests, got %q", status)
+	}
+}
+
+func TestFetchDigest_SkipsFailedEntries(t *testing.T) {
+	a := &App{}
+	// Forums without a topic cannot be planned outside all topics mode.
+	digest := &chatDigest{
+		failures: []string{"skipped Forum A: failed to get forum topics: CHANNEL_PRIVATE"},
+		entries: []digestEntry{
+			{chat: telegram.Chat{ID: 1, Title: "Forum B", IsForum: true}, title: "Forum B"},
+			{chat: telegram.Chat{ID: 2, Title: "Forum C", IsForum: true}, title: "Forum C"},
+		},
+	}
+
+	messages, err := a.fetchDigest(context.Background(), digest, RunOptions{}, nil)
+	if err != nil {
+		t.Fatalf("expected failed entries to be skipped, got %v", err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("expected no messages, got %+v", messages)
+	}
+	if len(digest.failures) != 3 || !strings.HasPrefix(digest.failures[1], "skipped Forum B:") || !strings.HasPrefix(digest.failures[2], "skipped Forum C:") {
+		t.Fatalf("unexpected failures: %q", digest.failures)
+	}
+	for _, entry := range digest.entries {
+		if entry.maxID != 0 {
+			t.Fatalf("expected failed entries not to be marked, got %+v", entry)
+		}
+	}
+}
+
+func TestTopicEntries(t *testing.T) {
+	forum := telegram.Chat{ID: 5, Title: "Forum", IsForum: true}
+	topics := []telegram.Topic{
+		{ID: 1, Title: "General", UnreadCount: 2},
+		{ID: 2, Title: "Quiet"},
+		{ID: 3, Title: "Releases", UnreadCount: 1},
+	}
+
+	entries := topicEntries(forum, topics, RunOptions{AllTopics: true})
+	if len(entries) != 2 || entries[0].topic.ID != 1 || entries[1].title != "Releases" {
+		t.Fatalf("unexpected unread entries: %+v", entries)
+	}
+	entries = topicEntries(forum, topics, RunOptions{AllTopics: true, UseDateRange: true})
+	if len(entries) != 3 {
+		t.Fatalf("expected all topics in date range mode, got %+v", entries)
+	}
+
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	topics[0].TopMessageDate = since.Add(time.Hour)
+	topics[1].TopMessageDate = since.Add(-time.Hour)
+	entries = topicEntries(forum, topics, RunOptions{AllTopics: true, UseDateRange: true, Since: since})
+	if len(entries) != 2 || entries[0].topic.ID != 1 || entries[1].topic.ID != 3 {
+		t.Fatalf("expected topics without activity since the range start to be skipped, got %+v", entries)
+	}
+}
+
+func TestAllTopicsPlan_Errors(t *testing.T) {
+	a := &App{}
+	forum := telegram.Chat{ID: 5, Title: "Forum", IsForum: true}
+
+	tests := []struct {
+		name string
+		chat telegram.Chat
+		opts RunOptions
+		want string
+	}{
+		{name: "not a forum", chat: telegram.Chat{ID: 1, Title: "Team"}, opts: RunOptions{AllTopics: true}, want: "not a forum"},
+		{name: "last n", chat: forum, opts: RunOptions{AllTopics: true, Last: 50}, want: "unread and date range"},
+		{name: "stream", chat: forum, opts: RunOptions{AllTopics: true, Stream: true}, want: "cannot be streamed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := a.buildModePlan(tt.chat, nil, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	plan, err := a.buildModePlan(forum, nil, RunOptions{AllTopics: true})
+	if err != nil || plan.digest == nil || plan.exportTitle != "Forum - All topics" {
+		t.Fatalf("unexpected plan: %+v, %v", plan, err)
+	}
+}
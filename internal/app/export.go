@@ -5,13 +5,24 @@ import (
 	"io"
 	"strings"
 	"time"
+
+	"cli-tg-chat-summary/internal/telegram"
 )
 
 type messageBlock struct {
-	SenderID int64
-	Start    time.Time
-	End      time.Time
-	Lines    []string
+	SenderID   int64
+	SenderName string
+	Start      time.Time
+	End        time.Time
+	Lines      []string
+	// Event is set for blocks holding a single event marker.
+	Event string
+	// Heading is set for blocks that start a new group of messages.
+	Heading string
+}
+
+func eventBlock(msg TemplateMessage) messageBlock {
+	return messageBlock{Event: msg.Event, Start: msg.Date, End: msg.Date, Lines: normalizeLines(msg.Text)}
 }
 
 func formatSenderID(id int64) string {
@@ -21,6 +32,15 @@ func formatSenderID(id int64) string {
 	return fmt.Sprintf("id=%d", id)
 }
 
+// formatSender labels a sender with the display name chosen by --names,
+// e.g. "Jane Doe (id=42)", or only the ID when no name is known.
+func formatSender(id int64, name string) string {
+	if name = oneLine(name); name == "" {
+		return formatSenderID(id)
+	}
+	return fmt.Sprintf("%s (%s)", name, formatSenderID(id))
+}
+
 func normalizeLines(text string) []string {
 	normalized := strings.ReplaceAll(text, "\r\n", "\n")
 	normalized = strings.ReplaceAll(normalized, "\r", "\n")
@@ -40,19 +60,197 @@ func normalizeLines(text string) []string {
 	return lines
 }
 
-func buildMessageBlocks(messages []TemplateMessage) []messageBlock {
+// textDetails selects the optional lines of text exports that only the
+// modes asking for them render, keeping default exports unchanged.
+type textDetails struct {
+	// reactions adds a "reactions:" line, in the mentions and reactions
+	// modes.
+	reactions bool
+	// engagement adds an "engagement:" line, with --top-posts or
+	// --min-views.
+	engagement bool
+	// attention starts messages that mention the user or reply to the
+	// user with "@me", with --attention.
+	attention bool
+}
+
+func newTextDetails(opts RunOptions) textDetails {
+	return textDetails{
+		reactions:  opts.UnreadMentions || opts.UnreadReactions,
+		engagement: opts.TopPosts > 0 || opts.MinViews > 0,
+		attention:  opts.Attention,
+	}
+}
+
+// messageLines returns the text lines written for msg in text exports,
+// followed by its poll, media, buttons and a summary of its reactions.
+// Messages that need the user's attention start with an @me marker.
+func messageLines(msg TemplateMessage, details textDetails) []string {
+	lines := append(normalizeLines(msg.Text), pollLines(msg.Poll)...)
+	if line := mediaLine(msg.Media); line != "" {
+		lines = append(lines, line)
+	}
+	// Bot alerts may consist of nothing but buttons.
+	buttons := buttonLines(msg.Buttons)
+	if len(lines) > 0 || len(buttons) > 0 {
+		if line := forwardLine(msg.Forward); line != "" {
+			lines = append(lines, line)
+		}
+		lines = append(lines, buttons...)
+		if len(msg.Tags) > 0 {
+			lines = append(lines, "tags: "+strings.Join(msg.Tags, ", "))
+		}
+		if msg.Chat != "" {
+			lines = append(lines, "chat: "+msg.Chat)
+		}
+		if msg.Link != "" {
+			lines = append(lines, "link: "+msg.Link)
+		}
+		if line := engagementLine(msg); line != "" && details.engagement {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > 0 && msg.needsAttention() && details.attention {
+		lines[0] = "@me " + lines[0]
+	}
+	if len(lines) == 0 || len(msg.Reactions) == 0 || !details.reactions {
+		return lines
+	}
+	parts := make([]string, 0, len(msg.Reactions))
+	for _, reaction := range msg.Reactions {
+		parts = append(parts, fmt.Sprintf("%s %d", reaction.Emoji, reaction.Count))
+	}
+	return append(lines, "reactions: "+strings.Join(parts, ", "))
+}
+
+// pollLines renders a poll as a heading line followed by one line per
+// option, e.g. "poll: Lunch? (closed, 12 voters)" and "- Pizza: 5".
+func pollLines(poll *TemplatePoll) []string {
+	if poll == nil {
+		return nil
+	}
+	kind := "poll"
+	if poll.Quiz {
+		kind = "quiz"
+	}
+	details := []string{fmt.Sprintf("%d voters", poll.TotalVoters)}
+	if poll.Closed {
+		details = append([]string{"closed"}, details...)
+	}
+	lines := []string{fmt.Sprintf("%s: %s (%s)", kind, strings.TrimSpace(poll.Question), strings.Join(details, ", "))}
+	for _, option := range poll.Options {
+		line := fmt.Sprintf("- %s: %d", strings.TrimSpace(option.Text), option.Voters)
+		if option.Correct {
+			line += " (correct)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// forwardLine describes the origin of a forwarded message, e.g.
+// "forwarded from Tech News (post 812 by Jane), 2025-01-20 14:03".
+func forwardLine(forward *TemplateForward) string {
+	if forward == nil {
+		return ""
+	}
+	from := forward.FromName
+	if from == "" {
+		from = formatSenderID(forward.FromID)
+	}
+	var post string
+	switch {
+	case forward.PostID != 0 && forward.PostAuthor != "":
+		post = fmt.Sprintf(" (post %d by %s)", forward.PostID, forward.PostAuthor)
+	case forward.PostID != 0:
+		post = fmt.Sprintf(" (post %d)", forward.PostID)
+	case forward.PostAuthor != "":
+		post = fmt.Sprintf(" (by %s)", forward.PostAuthor)
+	}
+	return fmt.Sprintf("forwarded from %s%s, %s", from, post, forward.Date.Format("2006-01-02 15:04"))
+}
+
+// engagementLine summarizes the views, forwards and comments of a channel
+// post, e.g. "engagement: 1200 views, 15 forwards, 8 comments".
+func engagementLine(msg TemplateMessage) string {
+	summary := engagementSummary(msg, false)
+	if summary == "" {
+		return ""
+	}
+	return "engagement: " + summary
+}
+
+// engagementSummary lists the non-zero engagement counters, optionally
+// including the total number of reactions.
+func engagementSummary(msg TemplateMessage, withReactions bool) string {
+	var parts []string
+	if msg.Views > 0 {
+		parts = append(parts, fmt.Sprintf("%d views", msg.Views))
+	}
+	if msg.Forwards > 0 {
+		parts = append(parts, fmt.Sprintf("%d forwards", msg.Forwards))
+	}
+	if withReactions {
+		if count := msg.reactionCount(); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d reactions", count))
+		}
+	}
+	if msg.Replies > 0 {
+		parts = append(parts, fmt.Sprintf("%d comments", msg.Replies))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buttonLines renders inline buttons one per line, e.g.
+// "button: Open pipeline — https://ci.example.com/p/1".
+func buttonLines(buttons []TemplateButton) []string {
+	lines := make([]string, 0, len(buttons))
+	for _, button := range buttons {
+		text := oneLine(button.Text)
+		switch button.Kind {
+		case telegram.ButtonURL:
+			lines = append(lines, "button: "+joinNonEmpty(" — ", text, button.Value))
+		case telegram.ButtonSwitchInline:
+			lines = append(lines, fmt.Sprintf("button: %s (switch inline: %s)", text, button.Value))
+		default:
+			lines = append(lines, fmt.Sprintf("button: %s (%s)", text, button.Kind))
+		}
+	}
+	return lines
+}
+
+// firstLine returns the first line written for msg, used to identify it in
+// one-line listings.
+func firstLine(msg TemplateMessage) string {
+	if lines := messageLines(msg, textDetails{}); len(lines) > 0 {
+		return lines[0]
+	}
+	return ""
+}
+
+func buildMessageBlocks(messages []TemplateMessage, details textDetails) []messageBlock {
 	var blocks []messageBlock
+	group := ""
 	for _, msg := range messages {
-		lines := normalizeLines(msg.Text)
+		if msg.Group != group && msg.Group != "" {
+			blocks = append(blocks, messageBlock{Heading: msg.Group})
+		}
+		group = msg.Group
+		if msg.Event != "" {
+			blocks = append(blocks, eventBlock(msg))
+			continue
+		}
+		lines := messageLines(msg, details)
 		if len(lines) == 0 {
 			continue
 		}
-		if len(blocks) == 0 || blocks[len(blocks)-1].SenderID != msg.SenderID {
+		if last := len(blocks) - 1; last < 0 || blocks[last].SenderID != msg.SenderID || blocks[last].Event != "" || blocks[last].Heading != "" {
 			blocks = append(blocks, messageBlock{
-				SenderID: msg.SenderID,
-				Start:    msg.Date,
-				End:      msg.Date,
-				Lines:    lines,
+				SenderID:   msg.SenderID,
+				SenderName: msg.SenderName,
+				Start:      msg.Date,
+				End:        msg.Date,
+				Lines:      lines,
 			})
 			continue
 		}
@@ -65,13 +263,25 @@ func buildMessageBlocks(messages []TemplateMessage) []messageBlock {
 
 func writeMessageBlocks(w io.Writer, blocks []messageBlock) error {
 	for _, block := range blocks {
+		if block.Heading != "" {
+			if _, err := fmt.Fprintf(w, "== %s ==\n", block.Heading); err != nil {
+				return err
+			}
+			continue
+		}
 		start := block.Start.Format("15:04")
 		end := block.End.Format("15:04")
 		timeLabel := start
 		if end != start {
 			timeLabel = fmt.Sprintf("%s-%s", start, end)
 		}
-		if _, err := fmt.Fprintf(w, "[%s] %s:\n", timeLabel, formatSenderID(block.SenderID)); err != nil {
+		if block.Event != "" {
+			if _, err := fmt.Fprintf(w, "[%s] %s: %s\n", timeLabel, block.Event, strings.Join(block.Lines, " ")); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "[%s] %s:\n", timeLabel, formatSender(block.SenderID, block.SenderName)); err != nil {
 			return err
 		}
 		for _, line := range block.Lines {
@@ -30,7 +30,7 @@ func TestBuildMessageBlocks_CollapseBySender(t *testing.T) {
 		{SenderID: 1, Date: base.Add(3 * time.Minute), Text: "d"},
 	}
 
-	blocks := buildMessageBlocks(messages)
+	blocks := buildMessageBlocks(messages, textDetails{})
 	if len(blocks) != 3 {
 		t.Fatalf("expected 3 blocks, got %d", len(blocks))
 	}
@@ -64,10 +64,11 @@ func TestWriteMessageBlocks_Format(t *testing.T) {
 			Lines:    []string{"hello"},
 		},
 		{
-			SenderID: 42,
-			Start:    start.Add(5 * time.Minute),
-			End:      start.Add(7 * time.Minute),
-			Lines:    []string{"line one", "line two"},
+			SenderID:   42,
+			SenderName: "Jane Doe",
+			Start:      start.Add(5 * time.Minute),
+			End:        start.Add(7 * time.Minute),
+			Lines:      []string{"line one", "line two"},
 		},
 	}
 
@@ -79,7 +80,7 @@ func TestWriteMessageBlocks_Format(t *testing.T) {
 	expected := strings.Join([]string{
 		"[09:00] id=unknown:",
 		"  hello",
-		"[09:05-09:07] id=42:",
+		"[09:05-09:07] Jane Doe (id=42):",
 		"  line one",
 		"  line two",
 		"",
@@ -3,8 +3,10 @@ package app
 import (
 	"fmt"
 	"io"
+	"iter"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,11 +17,46 @@ type Exporter interface {
 	Export(exportTitle string, messages []telegram.Message, opts RunOptions) (string, error)
 }
 
+// ExportSections holds optional sections rendered alongside the exported
+// messages. All message slices are ordered oldest first.
+type ExportSections struct {
+	// Context holds already read messages preceding the export.
+	Context []telegram.Message
+	// Pinned holds the chat's pinned messages.
+	Pinned []telegram.Message
+	// Info holds the chat metadata and participant directory, if any.
+	Info *telegram.ChatInfo
+	// warnings describe optional sections, and chats or topics of a digest,
+	// that could not be fetched; the export goes on without them.
+	warnings []string
+}
+
+// warningLines returns the warnings as "Warning: ..." lines.
+func (s ExportSections) warningLines() []string {
+	lines := make([]string, 0, len(s.warnings))
+	for _, warning := range s.warnings {
+		lines = append(lines, "Warning: "+warning)
+	}
+	return lines
+}
+
+// SectionExporter exports messages together with extra sections.
+type SectionExporter interface {
+	ExportWithSections(exportTitle string, messages []telegram.Message, sections ExportSections, opts RunOptions) (string, error)
+}
+
+// StreamExporter writes messages to the export file as they are produced.
+// The iterator must yield messages oldest first.
+type StreamExporter interface {
+	ExportStream(exportTitle string, messages iter.Seq2[telegram.Message, error], sections ExportSections, opts RunOptions) (string, error)
+}
+
 type DefaultExporter struct {
 	Now       func() time.Time
 	Getwd     func() (string, error)
 	MkdirAll  func(path string, perm os.FileMode) error
 	Create    func(path string) (io.WriteCloser, error)
+	Remove    func(path string) error
 	Templates *TemplateRegistry
 }
 
@@ -28,6 +65,7 @@ func NewDefaultExporter() *DefaultExporter {
 		Now:       time.Now,
 		Getwd:     os.Getwd,
 		MkdirAll:  os.MkdirAll,
+		Remove:    os.Remove,
 		Templates: NewDefaultTemplateRegistry(),
 		Create: func(path string) (io.WriteCloser, error) {
 			return os.Create(path)
@@ -36,6 +74,115 @@ func NewDefaultExporter() *DefaultExporter {
 }
 
 func (e *DefaultExporter) Export(exportTitle string, messages []telegram.Message, opts RunOptions) (string, error) {
+	return e.ExportWithSections(exportTitle, messages, ExportSections{}, opts)
+}
+
+func (e *DefaultExporter) ExportWithSections(exportTitle string, messages []telegram.Message, sections ExportSections, opts RunOptions) (string, error) {
+	template, err := e.template(opts)
+	if err != nil {
+		return "", err
+	}
+
+	exportDate := e.Now()
+	filename := exportFilename(exportTitle, exportDate, template, opts)
+	f, _, err := e.create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	input := TemplateInput{
+		ExportTitle:   exportTitle,
+		ExportDate:    exportDate,
+		TotalMessages: countMessages(messages),
+		Messages:      newTemplateMessages(messages),
+		Context:       newTemplateMessages(sections.Context),
+		Pinned:        newTemplateMessages(sections.Pinned),
+		Chat:          newTemplateChatInfo(sections.Info),
+		Options:       opts,
+	}
+	if opts.Partial {
+		input.Partial = messageRange(messages)
+	}
+	if opts.Attention {
+		input.Attention = attentionMessages(input.Messages)
+	}
+	if opts.TopPosts > 0 {
+		input.TopPosts = topPosts(input.Messages, opts.TopPosts)
+	}
+	if err := template.Render(f, input); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", template.Name(), err)
+	}
+
+	return filename, nil
+}
+
+// ExportStream renders messages as the iterator yields them, so memory use
+// does not grow with the size of the export. If the iterator fails or yields
+// nothing, the file is removed and no filename is returned.
+func (e *DefaultExporter) ExportStream(exportTitle string, messages iter.Seq2[telegram.Message, error], sections ExportSections, opts RunOptions) (string, error) {
+	template, err := e.template(opts)
+	if err != nil {
+		return "", err
+	}
+	streamer, ok := template.(StreamTemplate)
+	if !ok {
+		return "", fmt.Errorf("export format %q does not support streaming", template.Name())
+	}
+
+	exportDate := e.Now()
+	filename := exportFilename(exportTitle, exportDate, template, opts)
+	f, fullPath, err := e.create(filename)
+	if err != nil {
+		return "", err
+	}
+
+	input := TemplateInput{
+		ExportTitle: exportTitle,
+		ExportDate:  exportDate,
+		Context:     newTemplateMessages(sections.Context),
+		Pinned:      newTemplateMessages(sections.Pinned),
+		Chat:        newTemplateChatInfo(sections.Info),
+		Options:     opts,
+	}
+	count, err := renderStream(f, streamer, input, messages)
+	if err != nil || count == 0 {
+		_ = f.Close()
+		if e.Remove != nil {
+			_ = e.Remove(fullPath)
+		}
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to close file: %w", err)
+	}
+	return filename, nil
+}
+
+func renderStream(w io.Writer, template StreamTemplate, input TemplateInput, messages iter.Seq2[telegram.Message, error]) (int, error) {
+	writer, err := template.Stream(w, input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to render %s: %w", template.Name(), err)
+	}
+	count := 0
+	for msg, err := range messages {
+		if err != nil {
+			return count, err
+		}
+		count++
+		if err := writer.WriteMessage(newTemplateMessage(msg)); err != nil {
+			return count, fmt.Errorf("failed to render %s: %w", template.Name(), err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return count, fmt.Errorf("failed to render %s: %w", template.Name(), err)
+	}
+	return count, nil
+}
+
+func (e *DefaultExporter) template(opts RunOptions) (Template, error) {
 	registry := e.Templates
 	if registry == nil {
 		registry = NewDefaultTemplateRegistry()
@@ -47,57 +194,271 @@ func (e *DefaultExporter) Export(exportTitle string, messages []telegram.Message
 	formatName = strings.ToLower(formatName)
 	template, ok := registry.Get(formatName)
 	if !ok {
-		return "", fmt.Errorf("unknown export format %q (available: %s)", formatName, strings.Join(registry.Names(), ", "))
+		return nil, fmt.Errorf("unknown export format %q (available: %s)", formatName, strings.Join(registry.Names(), ", "))
 	}
+	return template, nil
+}
 
+// create makes the exports directory and opens filename inside the working
+// directory. It returns the open file and its full path.
+func (e *DefaultExporter) create(filename string) (io.WriteCloser, string, error) {
+	cwd, err := e.Getwd()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if err := e.MkdirAll(filepath.Join(cwd, "exports"), 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create exports directory: %w", err)
+	}
+
+	fullPath := filepath.Join(cwd, filename)
+	f, err := e.Create(fullPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create file: %w", err)
+	}
+	return f, fullPath, nil
+}
+
+func exportFilename(exportTitle string, exportDate time.Time, template Template, opts RunOptions) string {
 	// format: ChatName_Date.txt or ChatName_TopicName_Date.txt
 	// date range format: ChatName_YYYY-MM-DD_to_YYYY-MM-DD.txt
+	// last N format: ChatName_last500_YYYY-MM-DD.txt
+	// ID range format: ChatName_ids_12000_to_12850.txt
+	// unread mentions/reactions format: ChatName_mentions_YYYY-MM-DD.txt
+	// pinned format: ChatName_pinned_YYYY-MM-DD.txt
+	// admin log format: ChatName_adminlog_YYYY-MM-DD.txt or
+	// ChatName_adminlog_YYYY-MM-DD_to_YYYY-MM-DD.txt
+	// global search format: search_Query_YYYY-MM-DD.txt or
+	// search_Query_YYYY-MM-DD_to_YYYY-MM-DD.txt
+	// per-sender format: from_username_YYYY-MM-DD.txt or
+	// from_username_YYYY-MM-DD_to_YYYY-MM-DD.txt
+	// hashtag digest format: hashtags_tag1_tag2_YYYY-MM-DD.txt or
+	// hashtags_tag1_tag2_YYYY-MM-DD_to_YYYY-MM-DD.txt
+	// inbox digest format: inbox_YYYY-MM-DD.txt
 	cleanName := sanitizeFilename(exportTitle)
-	exportDate := e.Now()
+	acrossChats := opts.Search != "" || opts.Sender != "" || len(opts.Hashtags) > 0
+	switch {
+	case opts.Search != "":
+		// Hits come from many chats, so the query names the file.
+		cleanName = "search_" + sanitizeFilename(opts.Search)
+	case opts.Sender != "":
+		cleanName = "from_" + sanitizeFilename(strings.TrimPrefix(opts.Sender, "@"))
+	case len(opts.Hashtags) > 0:
+		cleanName = "hashtags_" + sanitizeFilename(hashtagNames(opts.Hashtags))
+	case opts.Inbox:
+		cleanName = "inbox"
+	}
 	var suffix string
-	if opts.UseDateRange {
+	switch {
+	case acrossChats && opts.UseDateRange:
+		suffix = fmt.Sprintf("%s_to_%s", opts.Since.Format("2006-01-02"), opts.Until.Format("2006-01-02"))
+	case acrossChats:
+		suffix = exportDate.Format("2006-01-02")
+	case opts.AdminLog && opts.UseDateRange:
+		suffix = fmt.Sprintf("adminlog_%s_to_%s", opts.Since.Format("2006-01-02"), opts.Until.Format("2006-01-02"))
+	case opts.AdminLog:
+		suffix = "adminlog_" + exportDate.Format("2006-01-02")
+	case opts.UseDateRange:
 		suffix = fmt.Sprintf("%s_to_%s", opts.Since.Format("2006-01-02"), opts.Until.Format("2006-01-02"))
-	} else {
+	case opts.Last > 0:
+		suffix = fmt.Sprintf("last%d_%s", opts.Last, exportDate.Format("2006-01-02"))
+	case opts.idRange():
+		suffix = "ids_" + formatIDRange(opts, "_to_")
+	case opts.UnreadMentions:
+		suffix = "mentions_" + exportDate.Format("2006-01-02")
+	case opts.UnreadReactions:
+		suffix = "reactions_" + exportDate.Format("2006-01-02")
+	case opts.Pinned:
+		suffix = "pinned_" + exportDate.Format("2006-01-02")
+	default:
 		suffix = exportDate.Format("2006-01-02")
 	}
-	filename := fmt.Sprintf("exports/%s_%s.%s", cleanName, suffix, template.Extension())
+	if opts.Partial {
+		suffix += "_partial"
+	}
+	return fmt.Sprintf("exports/%s_%s.%s", cleanName, suffix, template.Extension())
+}
 
-	cwd, err := e.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("failed to get working directory: %w", err)
+// hashtagNames joins tags without their # or $ prefixes, which are awkward
+// in file names.
+func hashtagNames(tags []string) string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, strings.TrimLeft(tag, "#$"))
 	}
-	if err := e.MkdirAll(filepath.Join(cwd, "exports"), 0755); err != nil {
-		return "", fmt.Errorf("failed to create exports directory: %w", err)
+	return strings.Join(names, "_")
+}
+
+// attentionMessages returns the messages that mention the user or reply to
+// the user's messages.
+func attentionMessages(messages []TemplateMessage) []TemplateMessage {
+	var result []TemplateMessage
+	for _, msg := range messages {
+		if msg.needsAttention() && len(normalizeLines(msg.Text)) > 0 {
+			result = append(result, msg)
+		}
 	}
+	return result
+}
 
-	fullPath := filepath.Join(cwd, filename)
-	f, err := e.Create(fullPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+// newTemplateChatInfo converts chat metadata for templates; nil stays nil.
+func newTemplateChatInfo(info *telegram.ChatInfo) *TemplateChatInfo {
+	if info == nil {
+		return nil
 	}
-	defer func() {
-		_ = f.Close()
-	}()
+	result := &TemplateChatInfo{
+		Description:     info.Description,
+		MemberCount:     info.MemberCount,
+		LinkedChatID:    info.LinkedChatID,
+		LinkedChatTitle: info.LinkedChatTitle,
+		SlowModeSeconds: info.SlowModeSeconds,
+		Created:         info.Created,
+	}
+	for _, p := range info.Participants {
+		result.Participants = append(result.Participants, TemplateParticipant(p))
+	}
+	return result
+}
 
+// topPosts returns up to limit messages with the most views, breaking ties
+// by forwards, reactions and comments. Messages without any engagement are
+// left out.
+func topPosts(messages []TemplateMessage, limit int) []TemplateMessage {
+	var ranked []TemplateMessage
+	for _, msg := range messages {
+		if msg.Views+msg.Forwards+msg.Replies+msg.reactionCount() > 0 {
+			ranked = append(ranked, msg)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Views != b.Views {
+			return a.Views > b.Views
+		}
+		if a.Forwards != b.Forwards {
+			return a.Forwards > b.Forwards
+		}
+		if a.reactionCount() != b.reactionCount() {
+			return a.reactionCount() > b.reactionCount()
+		}
+		return a.Replies > b.Replies
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// newTemplateMessages converts messages, returning nil when there are none.
+func newTemplateMessages(messages []telegram.Message) []TemplateMessage {
+	if len(messages) == 0 {
+		return nil
+	}
 	templateMessages := make([]TemplateMessage, 0, len(messages))
 	for _, msg := range messages {
-		templateMessages = append(templateMessages, TemplateMessage{
-			ID:       msg.ID,
-			Date:     msg.Date,
-			Text:     msg.Text,
-			SenderID: msg.SenderID,
-		})
+		templateMessages = append(templateMessages, newTemplateMessage(msg))
 	}
-	input := TemplateInput{
-		ExportTitle:   exportTitle,
-		ExportDate:    exportDate,
-		TotalMessages: len(messages),
-		Messages:      templateMessages,
-		Options:       opts,
+	return templateMessages
+}
+
+func newTemplateMessage(msg telegram.Message) TemplateMessage {
+	templateMsg := TemplateMessage{
+		ID:          msg.ID,
+		Date:        msg.Date,
+		Text:        msg.Text,
+		SenderID:    msg.SenderID,
+		SenderName:  msg.SenderName,
+		Mentioned:   msg.Mentioned,
+		ReplyToMe:   msg.ReplyToMe,
+		Views:       msg.Views,
+		Forwards:    msg.Forwards,
+		Replies:     msg.Replies,
+		Event:       msg.Event,
+		AdminAction: msg.AdminAction,
+		Group:       msg.Group,
+		Tags:        msg.Tags,
+		Link:        msg.Link,
 	}
-	if err := template.Render(f, input); err != nil {
-		return "", fmt.Errorf("failed to render %s: %w", template.Name(), err)
+	if msg.ChatTitle != msg.Group {
+		templateMsg.Chat = msg.ChatTitle
+	}
+	if msg.Forward != nil {
+		forward := TemplateForward(*msg.Forward)
+		templateMsg.Forward = &forward
+	}
+	for _, reaction := range msg.Reactions {
+		templateMsg.Reactions = append(templateMsg.Reactions, TemplateReaction(reaction))
+	}
+	if msg.Poll != nil {
+		templateMsg.Poll = newTemplatePoll(*msg.Poll)
 	}
+	if msg.Media != nil {
+		media := TemplateMedia(*msg.Media)
+		templateMsg.Media = &media
+	}
+	for _, button := range msg.Buttons {
+		templateMsg.Buttons = append(templateMsg.Buttons, TemplateButton(button))
+	}
+	return templateMsg
+}
 
-	return filename, nil
+func newTemplatePoll(poll telegram.Poll) *TemplatePoll {
+	templatePoll := &TemplatePoll{
+		Question:    poll.Question,
+		TotalVoters: poll.TotalVoters,
+		Quiz:        poll.Quiz,
+		Closed:      poll.Closed,
+	}
+	for _, option := range poll.Options {
+		templatePoll.Options = append(templatePoll.Options, TemplatePollOption{
+			Text:    option.Text,
+			Voters:  option.Voters,
+			Correct: option.Correct,
+		})
+	}
+	return templatePoll
+}
+
+// countMessages counts messages, leaving out event markers.
+func countMessages(messages []telegram.Message) int {
+	count := 0
+	for _, msg := range messages {
+		if msg.Event == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// messageRange describes the messages of a partial export. Events are
+// skipped, and the message IDs only span one chat: the original group of an
+// upgraded supergroup, after the migration marker, numbers its messages on
+// its own, so its IDs only count when no supergroup message was fetched.
+func messageRange(messages []telegram.Message) *TemplateRange {
+	r := &TemplateRange{}
+	found, idsDone := false, false
+	for _, msg := range messages {
+		if msg.Event == telegram.EventMigration {
+			idsDone = r.LastID != 0
+		}
+		if msg.Event != "" {
+			continue
+		}
+		if !found {
+			r.From, r.To = msg.Date, msg.Date
+		}
+		if !idsDone && (r.FirstID == 0 || msg.ID < r.FirstID) {
+			r.FirstID = msg.ID
+		}
+		if !idsDone && msg.ID > r.LastID {
+			r.LastID = msg.ID
+		}
+		if msg.Date.Before(r.From) {
+			r.From = msg.Date
+		}
+		if msg.Date.After(r.To) {
+			r.To = msg.Date
+		}
+		found = true
+	}
+	return r
 }
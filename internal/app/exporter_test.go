@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"os"
 	"strings"
@@ -170,3 +171,631 @@ func TestDefaultExporter_Export_XMLCompact(t *testing.T) {
 		t.Fatalf("missing compact message text: %q", output)
 	}
 }
+
+func TestDefaultExporter_Export_Partial(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	messages := []telegram.Message{
+		{ID: 120, SenderID: 10, Date: now.Add(-2 * time.Hour), Text: "first"},
+		{ID: 180, SenderID: 10, Date: now.Add(-1 * time.Hour), Text: "last"},
+	}
+
+	filename, err := env.Exporter.Export("My Chat", messages, RunOptions{Partial: true})
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if filename != "exports/My Chat_2025-01-02_partial.txt" {
+		t.Fatalf("unexpected filename: %s", filename)
+	}
+
+	output := env.Buffer.String()
+	want := "Partial Export: messages 120-180 (Thu, 02 Jan 2025 01:04:05 UTC to Thu, 02 Jan 2025 02:04:05 UTC), fetch was canceled\n"
+	if !strings.Contains(output, want) {
+		t.Fatalf("missing partial marker: %q", output)
+	}
+}
+
+func TestMessageRange_MigratedSupergroup(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []telegram.Message{
+		{ID: 12, Date: now, Text: "supergroup"},
+		{ID: 5, Date: now.Add(-time.Hour), Text: "supergroup"},
+		{Date: now.Add(-2 * time.Hour), Text: "upgraded", Event: telegram.EventMigration},
+		{ID: 900, Date: now.Add(-2 * time.Hour), Text: "legacy"},
+	}
+	got := messageRange(messages)
+	want := TemplateRange{FirstID: 5, LastID: 12, From: now.Add(-2 * time.Hour), To: now}
+	if *got != want {
+		t.Fatalf("messageRange() = %+v, want %+v", *got, want)
+	}
+
+	// Canceled before any supergroup message: the legacy IDs are used.
+	got = messageRange(messages[2:])
+	if got.FirstID != 900 || got.LastID != 900 {
+		t.Fatalf("unexpected legacy range: %+v", *got)
+	}
+}
+
+func TestDefaultExporter_Export_PartialXML(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	messages := []telegram.Message{
+		{ID: 120, SenderID: 10, Date: now, Text: "first"},
+		{ID: 180, SenderID: 10, Date: now.Add(time.Minute), Text: "last"},
+	}
+
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{Partial: true, ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	output := env.Buffer.String()
+	if !strings.Contains(output, `<p f="120" l="180" s="2025-01-02T03:04:05Z" u="2025-01-02T03:05:05Z"></p>`) {
+		t.Fatalf("missing compact partial marker: %q", output)
+	}
+}
+
+func streamOf(messages []telegram.Message, err error) func(func(telegram.Message, error) bool) {
+	return func(yield func(telegram.Message, error) bool) {
+		for _, msg := range messages {
+			if !yield(msg, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(telegram.Message{}, err)
+		}
+	}
+}
+
+func TestDefaultExporter_ExportStream(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	messages := []telegram.Message{
+		{SenderID: 10, Date: now, Text: "hello"},
+		{SenderID: 10, Date: now.Add(1 * time.Minute), Text: "world"},
+		{SenderID: 20, SenderName: "Bob", Date: now.Add(2 * time.Minute), Text: "hi"},
+	}
+
+	filename, err := env.Exporter.ExportStream("My Chat", streamOf(messages, nil), ExportSections{}, RunOptions{})
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if filename != "exports/My Chat_2025-01-02.txt" {
+		t.Fatalf("unexpected filename: %s", filename)
+	}
+
+	output := env.Buffer.String()
+	if !strings.Contains(output, "[03:04-03:05] id=10:\n  hello\n  world\n") {
+		t.Fatalf("missing message block: %q", output)
+	}
+	if !strings.Contains(output, "] Bob (id=20):\n  hi\n") {
+		t.Fatalf("missing second block: %q", output)
+	}
+	if !strings.HasSuffix(output, "\nTotal Messages: 3\n") {
+		t.Fatalf("missing trailing count: %q", output)
+	}
+}
+
+func TestDefaultExporter_ExportStream_XMLCompact(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	messages := []telegram.Message{
+		{SenderID: 10, Date: now, Text: "hello"},
+	}
+
+	if _, err := env.Exporter.ExportStream("My Chat", streamOf(messages, nil), ExportSections{}, RunOptions{ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+
+	output := env.Buffer.String()
+	if !strings.HasPrefix(output, "<c t=\"My Chat\" d=\"2025-01-02T03:04:05Z\">") {
+		t.Fatalf("missing root element: %q", output)
+	}
+	if !strings.HasSuffix(output, "<m t=\"2025-01-02T03:04:05Z\" s=\"10\">hello</m><n>1</n></c>") {
+		t.Fatalf("missing trailing count: %q", output)
+	}
+}
+
+func TestDefaultExporter_ExportStream_RemovesFileOnError(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+	var removed string
+	env.Exporter.Remove = func(path string) error {
+		removed = path
+		return nil
+	}
+
+	messages := []telegram.Message{{SenderID: 10, Date: now, Text: "hello"}}
+	fetchErr := errors.New("boom")
+
+	filename, err := env.Exporter.ExportStream("My Chat", streamOf(messages, fetchErr), ExportSections{}, RunOptions{})
+	if !errors.Is(err, fetchErr) {
+		t.Fatalf("expected fetch error, got %v", err)
+	}
+	if filename != "" {
+		t.Fatalf("expected no filename, got %s", filename)
+	}
+	if removed != "/work/exports/My Chat_2025-01-02.txt" {
+		t.Fatalf("unexpected removed path: %q", removed)
+	}
+}
+
+func TestExportFilename_Modes(t *testing.T) {
+	exportDate := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	template := NewTextTemplate()
+
+	tests := []struct {
+		name string
+		opts RunOptions
+		want string
+	}{
+		{name: "last", opts: RunOptions{Last: 500}, want: "exports/My Chat_last500_2025-01-02.txt"},
+		{name: "id range", opts: RunOptions{FromID: 12000, ToID: 12850}, want: "exports/My Chat_ids_12000_to_12850.txt"},
+		{name: "open id range", opts: RunOptions{FromID: 12000}, want: "exports/My Chat_ids_12000_to_latest.txt"},
+		{name: "pinned", opts: RunOptions{Pinned: true}, want: "exports/My Chat_pinned_2025-01-02.txt"},
+		{name: "admin log", opts: RunOptions{AdminLog: true}, want: "exports/My Chat_adminlog_2025-01-02.txt"},
+		{name: "admin log window", opts: RunOptions{AdminLog: true, UseDateRange: true, Since: exportDate, Until: exportDate.AddDate(0, 0, 1)}, want: "exports/My Chat_adminlog_2025-01-02_to_2025-01-03.txt"},
+		{name: "search", opts: RunOptions{Search: "release notes"}, want: "exports/search_release notes_2025-01-02.txt"},
+		{name: "search window", opts: RunOptions{Search: "a/b", UseDateRange: true, Since: exportDate, Until: exportDate.AddDate(0, 0, 1)}, want: "exports/search_a_b_2025-01-02_to_2025-01-03.txt"},
+		{name: "sender", opts: RunOptions{Sender: "@alice"}, want: "exports/from_alice_2025-01-02.txt"},
+		{name: "sender window", opts: RunOptions{Sender: "12345", UseDateRange: true, Since: exportDate, Until: exportDate.AddDate(0, 0, 1)}, want: "exports/from_12345_2025-01-02_to_2025-01-03.txt"},
+		{name: "hashtags", opts: RunOptions{Hashtags: []string{"#decision", "$TSLA"}}, want: "exports/hashtags_decision_TSLA_2025-01-02.txt"},
+		{name: "inbox", opts: RunOptions{Inbox: true}, want: "exports/inbox_2025-01-02.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exportFilename("My Chat", exportDate, template, tt.opts); got != tt.want {
+				t.Fatalf("exportFilename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultExporter_Export_Reactions(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	messages := []telegram.Message{
+		{SenderID: 10, Date: now, Text: "shipped", Reactions: []telegram.Reaction{{Emoji: "🎉", Count: 4}, {Emoji: "👍", Count: 2}}},
+	}
+
+	filename, err := env.Exporter.Export("My Chat", messages, RunOptions{UnreadReactions: true})
+	if err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if filename != "exports/My Chat_reactions_2025-01-02.txt" {
+		t.Fatalf("unexpected filename: %s", filename)
+	}
+	if !strings.Contains(env.Buffer.String(), "  shipped\n  reactions: 🎉 4, 👍 2\n") {
+		t.Fatalf("missing reactions line: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if strings.Contains(env.Buffer.String(), "reactions:") {
+		t.Fatalf("unexpected reactions line in a default export: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_ExportWithSections_Context(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	sections := ExportSections{Context: []telegram.Message{{SenderID: 20, Date: now.Add(-time.Hour), Text: "question?"}}}
+	messages := []telegram.Message{{SenderID: 10, Date: now, Text: "answer"}}
+
+	if _, err := env.Exporter.ExportWithSections("My Chat", messages, sections, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	content := env.Buffer.String()
+	if !strings.Contains(content, "Total Messages: 1\n\nContext (already read):\n[02:04] id=20:\n  question?\n\nUnread:\n[03:04] id=10:\n  answer\n") {
+		t.Fatalf("unexpected context layout: %q", content)
+	}
+}
+
+func TestDefaultExporter_ExportWithSections_ContextXML(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	sections := ExportSections{Context: []telegram.Message{{SenderID: 20, Date: now.Add(-time.Hour), Text: "question?"}}}
+	messages := []telegram.Message{{SenderID: 10, Date: now, Text: "answer"}}
+
+	if _, err := env.Exporter.ExportWithSections("My Chat", messages, sections, RunOptions{ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	content := env.Buffer.String()
+	if !strings.Contains(content, `<cx><m t="2025-01-02T02:04:05Z" s="20">question?</m></cx><m t="2025-01-02T03:04:05Z" s="10">answer</m>`) {
+		t.Fatalf("unexpected context layout: %q", content)
+	}
+	if !strings.Contains(content, `n="1"`) {
+		t.Fatalf("context must not be counted: %q", content)
+	}
+}
+
+func TestDefaultExporter_Export_Attention(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	messages := []telegram.Message{
+		{ID: 1, SenderID: 10, Date: now, Text: "morning"},
+		{ID: 2, SenderID: 20, Date: now.Add(time.Minute), Text: "@alice can you check?\nthanks", Mentioned: true},
+		{ID: 3, SenderID: 30, Date: now.Add(2 * time.Minute), Text: "agreed", ReplyToMe: true},
+	}
+
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{Attention: true}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	content := env.Buffer.String()
+	wantSection := "Total Messages: 3\n\nNeeds your attention (2):\n" +
+		"  [2025-01-02 03:05] id=20 (mention): @alice can you check?\n" +
+		"  [2025-01-02 03:06] id=30 (reply): agreed\n\n[03:04] id=10:"
+	if !strings.Contains(content, wantSection) {
+		t.Fatalf("missing attention section: %q", content)
+	}
+	if !strings.Contains(content, "  @me @alice can you check?\n  thanks\n") || !strings.Contains(content, "  @me agreed\n") {
+		t.Fatalf("missing @me markers: %q", content)
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if strings.Contains(env.Buffer.String(), "@me") {
+		t.Fatalf("unexpected @me marker without --attention: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_Export_AttentionXML(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	messages := []telegram.Message{
+		{ID: 2, SenderID: 20, Date: now, Text: "ping", Mentioned: true},
+	}
+
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{ExportFormat: "xml"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	content := env.Buffer.String()
+	if !strings.Contains(content, `<message mentioned="true">`) {
+		t.Fatalf("missing mentioned attribute: %q", content)
+	}
+	if strings.Contains(content, "<attention>") {
+		t.Fatalf("attention section should be opt-in: %q", content)
+	}
+}
+
+func TestDefaultExporter_ExportWithSections_Pinned(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	env := newTestExporterEnv(now)
+
+	sections := ExportSections{Pinned: []telegram.Message{{SenderID: 1, Date: now.AddDate(0, -2, 0), Text: "Rules: be nice"}}}
+	messages := []telegram.Message{{SenderID: 10, Date: now, Text: "hello"}}
+
+	if _, err := env.Exporter.ExportWithSections("My Chat", messages, sections, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want := "Total Messages: 1\n\nPinned (1):\n[2024-11-02 03:04] id=1:\n  Rules: be nice\n\n[03:04] id=10:\n  hello\n"
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected pinned section: %q", env.Buffer.String())
+	}
+
+	env.Buffer.Reset()
+	if _, err := env.Exporter.ExportWithSections("My Chat", messages, sections, RunOptions{ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if !strings.Contains(env.Buffer.String(), `<pn><m t="2024-11-02T03:04:05Z" s="1">Rules: be nice</m></pn>`) {
+		t.Fatalf("unexpected compact pinned section: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_Export_Poll(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []telegram.Message{{
+		SenderID: 10,
+		Date:     now,
+		Poll: &telegram.Poll{
+			Question:    "Release on Friday?",
+			TotalVoters: 7,
+			Closed:      true,
+			Options:     []telegram.PollOption{{Text: "Yes", Voters: 5}, {Text: "No", Voters: 2}},
+		},
+	}}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want := "[03:04] id=10:\n  poll: Release on Friday? (closed, 7 voters)\n  - Yes: 5\n  - No: 2\n"
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected poll block: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want = `<m t="2025-01-02T03:04:05Z" s="10"><pl q="Release on Friday?" v="7" c="true"><o v="5">Yes</o><o v="2">No</o></pl></m>`
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected compact poll: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_Export_Media(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []telegram.Message{{
+		SenderID: 10,
+		Date:     now,
+		Text:     "worth a read https://example.com/post",
+		Media:    &telegram.Media{Kind: telegram.MediaLink, URL: "https://example.com/post", Title: "Post", Description: "Short summary"},
+	}}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if !strings.Contains(env.Buffer.String(), "  worth a read https://example.com/post\n  link: Post — Short summary\n") {
+		t.Fatalf("missing link line: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{ExportFormat: "xml"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if !strings.Contains(env.Buffer.String(), `<media kind="link" url="https://example.com/post" title="Post">Short summary</media>`) {
+		t.Fatalf("missing media element: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_Export_Buttons(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []telegram.Message{{
+		SenderID: 10,
+		Date:     now,
+		Text:     "Build #12 failed",
+		Buttons: []telegram.Button{
+			{Kind: telegram.ButtonURL, Text: "Open pipeline", Value: "https://ci.example.com/p/12"},
+			{Kind: telegram.ButtonCallback, Text: "Retry", Value: "retry:12"},
+			{Kind: telegram.ButtonSwitchInline, Text: "Share", Value: "build 12"},
+		},
+	}}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want := "  Build #12 failed\n" +
+		"  button: Open pipeline — https://ci.example.com/p/12\n" +
+		"  button: Retry (callback)\n" +
+		"  button: Share (switch inline: build 12)\n"
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected button list: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if !strings.Contains(env.Buffer.String(), `<bt><b k="url" v="https://ci.example.com/p/12">Open pipeline</b><b k="callback" v="retry:12">Retry</b>`) {
+		t.Fatalf("unexpected compact buttons: %q", env.Buffer.String())
+	}
+
+	// An alert with only buttons is still listed.
+	alert := []telegram.Message{{SenderID: 10, Date: now, Buttons: messages[0].Buttons[:1]}}
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", alert, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if !strings.Contains(env.Buffer.String(), "  button: Open pipeline — https://ci.example.com/p/12\n") {
+		t.Fatalf("expected buttons-only message to be listed: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_Export_TopPosts(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []telegram.Message{
+		{ID: 3, SenderID: 10, Date: now.Add(2 * time.Hour), Text: "third", Views: 900, Forwards: 4},
+		{ID: 2, SenderID: 10, Date: now.Add(time.Hour), Text: "second", Views: 1200, Forwards: 15, Replies: 8,
+			Reactions: []telegram.Reaction{{Emoji: "👍", Count: 20}, {Emoji: "🔥", Count: 10}}},
+		{ID: 1, SenderID: 10, Date: now, Text: "first", Views: 900, Forwards: 9},
+	}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Channel", messages, RunOptions{TopPosts: 2}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	content := env.Buffer.String()
+	wantSection := "Top posts (2):\n" +
+		"  1. [2025-01-02 04:04] id=10 (1200 views, 15 forwards, 30 reactions, 8 comments): second\n" +
+		"  2. [2025-01-02 03:04] id=10 (900 views, 9 forwards): first\n\n"
+	if !strings.Contains(content, wantSection) {
+		t.Fatalf("unexpected top posts section: %q", content)
+	}
+	if !strings.Contains(content, "  second\n  engagement: 1200 views, 15 forwards, 8 comments\n") {
+		t.Fatalf("missing engagement line: %q", content)
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Channel", messages, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if strings.Contains(env.Buffer.String(), "engagement:") {
+		t.Fatalf("unexpected engagement line in a default export: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Channel", messages, RunOptions{ExportFormat: "xml-compact", TopPosts: 1}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	content = env.Buffer.String()
+	if !strings.Contains(content, `<tp><p k="1" i="2" t="2025-01-02T04:04:05Z" vw="1200" fw="15" rc="30" cm="8">second</p></tp>`) {
+		t.Fatalf("unexpected compact top posts: %q", content)
+	}
+	if !strings.Contains(content, `<m t="2025-01-02T03:04:05Z" s="10" vw="900" fw="9">first</m>`) {
+		t.Fatalf("missing compact metrics: %q", content)
+	}
+}
+
+func TestDefaultExporter_ExportWithSections_ChatInfo(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	sections := ExportSections{Info: &telegram.ChatInfo{
+		Description:     "Release coordination\nNo memes",
+		MemberCount:     42,
+		LinkedChatID:    77,
+		LinkedChatTitle: "Releases",
+		SlowModeSeconds: 30,
+		Created:         time.Date(2021, 5, 6, 0, 0, 0, 0, time.UTC),
+		Participants: []telegram.Participant{
+			{ID: 10, Name: "Jane Doe", Username: "jane", AdminTitle: "Moderator"},
+			{ID: 11, Name: "CI", Username: "ci_bot", Bot: true},
+			{ID: 12},
+		},
+	}}
+	messages := []telegram.Message{{SenderID: 10, Date: now, Text: "hello"}}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.ExportWithSections("My Chat", messages, sections, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want := "Description: Release coordination No memes\n" +
+		"Members: 42\n" +
+		"Linked Chat: Releases (id=77)\n" +
+		"Slow Mode: 30s\n" +
+		"Created: 2021-05-06\n" +
+		"Participants (3):\n" +
+		"  id=10: Jane Doe, @jane, admin: Moderator\n" +
+		"  id=11: CI, @ci_bot, bot\n" +
+		"  id=12: unknown\n" +
+		"Total Messages: 1\n"
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected chat info header: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.ExportWithSections("My Chat", messages, sections, RunOptions{ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want = `<ci m="42" sl="30" cr="2021-05-06T00:00:00Z"><ds>Release coordination&#xA;No memes</ds><lc i="77">Releases</lc>` +
+		`<ps><u i="10" n="Jane Doe" un="jane" a="Moderator"></u><u i="11" n="CI" un="ci_bot" b="true"></u><u i="12"></u></ps></ci>`
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected compact chat info: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_Export_MigrationMarker(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []telegram.Message{
+		{ID: 90, SenderID: 10, Date: now, Text: "before"},
+		{Date: now.Add(time.Minute), Text: "Group upgraded to a supergroup", Event: telegram.EventMigration},
+		{ID: 2, SenderID: 10, Date: now.Add(2 * time.Minute), Text: "after"},
+	}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want := "Total Messages: 2\n\n" +
+		"[03:04] id=10:\n  before\n" +
+		"[03:05] migration: Group upgraded to a supergroup\n" +
+		"[03:06] id=10:\n  after\n"
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected migration marker: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("My Chat", messages, RunOptions{ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if !strings.Contains(env.Buffer.String(), `<m t="2025-01-02T03:05:05Z" s="0" ev="migration">Group upgraded to a supergroup</m>`) {
+		t.Fatalf("unexpected compact migration marker: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_Export_SavedDigestGroups(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	forward := &telegram.Forward{FromID: 300, FromName: "Tech News", Date: now.Add(-time.Hour), PostID: 812}
+	messages := []telegram.Message{
+		{ID: 1, SenderID: 10, Date: now, Text: "Go 1.25 released", Group: "Tech News", Forward: forward, Tags: []string{"🔥 Reading"}},
+		{ID: 2, SenderID: 10, Date: now.Add(time.Minute), Text: "buy milk", Group: "Saved Messages"},
+		{ID: 3, SenderID: 10, Date: now.Add(2 * time.Minute), Text: "call mom", Group: "Saved Messages"},
+	}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("Saved Messages", messages, RunOptions{Saved: true}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want := "== Tech News ==\n" +
+		"[03:04] id=10:\n  Go 1.25 released\n  forwarded from Tech News (post 812), 2025-01-02 02:04\n  tags: 🔥 Reading\n" +
+		"== Saved Messages ==\n" +
+		"[03:05-03:06] id=10:\n  buy milk\n  call mom\n"
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected saved digest: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("Saved Messages", messages, RunOptions{Saved: true, ExportFormat: "xml"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	output := env.Buffer.String()
+	if !strings.Contains(output, `<message group="Tech News" tags="🔥 Reading">`) ||
+		!strings.Contains(output, `<forward from_id="300" from_name="Tech News" date="2025-01-02T02:04:05Z" post_id="812"></forward>`) {
+		t.Fatalf("unexpected xml saved digest: %q", output)
+	}
+}
+
+func TestDefaultExporter_Export_SearchLinks(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []telegram.Message{
+		{ID: 812, SenderID: 10, Date: now, Text: "release notes are out", Group: "Go News", Link: "https://t.me/gonews/812"},
+		{ID: 5, SenderID: 20, Date: now.Add(time.Minute), Text: "did you read the release notes?", Group: "Alice"},
+	}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("Search: release notes", messages, RunOptions{Search: "release notes"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want := "== Go News ==\n[03:04] id=10:\n  release notes are out\n  link: https://t.me/gonews/812\n" +
+		"== Alice ==\n[03:05] id=20:\n  did you read the release notes?\n"
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected search export: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("Search: release notes", messages, RunOptions{Search: "release notes", ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if !strings.Contains(env.Buffer.String(), `g="Go News" lk="https://t.me/gonews/812"`) {
+		t.Fatalf("unexpected compact search export: %q", env.Buffer.String())
+	}
+}
+
+func TestDefaultExporter_Export_HashtagChats(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	messages := []telegram.Message{
+		{ID: 812, SenderID: 10, Date: now, Text: "ship it #decision", Group: "#decision", ChatTitle: "Go News", Link: "https://t.me/gonews/812"},
+		{ID: 5, SenderID: 20, Date: now.Add(time.Minute), Text: "#todo review", Group: "#todo", ChatTitle: "Alice"},
+	}
+
+	env := newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("Hashtags: #decision #todo", messages, RunOptions{Hashtags: []string{"#decision", "#todo"}}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	want := "== #decision ==\n[03:04] id=10:\n  ship it #decision\n  chat: Go News\n  link: https://t.me/gonews/812\n" +
+		"== #todo ==\n[03:05] id=20:\n  #todo review\n  chat: Alice\n"
+	if !strings.Contains(env.Buffer.String(), want) {
+		t.Fatalf("unexpected hashtag export: %q", env.Buffer.String())
+	}
+
+	env = newTestExporterEnv(now)
+	if _, err := env.Exporter.Export("Hashtags: #decision #todo", messages, RunOptions{Hashtags: []string{"#decision", "#todo"}, ExportFormat: "xml-compact"}); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+	if !strings.Contains(env.Buffer.String(), `g="#decision" ch="Go News" lk="https://t.me/gonews/812"`) {
+		t.Fatalf("unexpected compact hashtag export: %q", env.Buffer.String())
+	}
+}
@@ -3,6 +3,9 @@ package app
 import (
 	"context"
 	"fmt"
+	"iter"
+	"strings"
+	"time"
 
 	"cli-tg-chat-summary/internal/telegram"
 )
@@ -10,21 +13,103 @@ import (
 type fetchPlan struct {
 	progressTitle string
 	exportTitle   string
-	fetch         func(context.Context, telegram.ProgressFunc) ([]telegram.Message, error)
+	checkpoint    checkpointKey
+	fetch         func(context.Context, *telegram.Cursor, telegram.ProgressFunc) ([]telegram.Message, error)
+	// stream yields the same messages as fetch, oldest first, for --stream.
+	stream func(context.Context, telegram.ProgressFunc) iter.Seq2[telegram.Message, error]
+	// readContext fetches already read messages preceding the export,
+	// newest first. It is nil unless --context applies to the mode.
+	readContext func(context.Context, telegram.ProgressFunc) ([]telegram.Message, error)
+	// pinned fetches the pinned messages for the "Pinned" section, newest
+	// first. It is nil unless --with-pinned was requested.
+	pinned func(context.Context, telegram.ProgressFunc) ([]telegram.Message, error)
+	// refreshPoll replaces the results of an open poll with fresh ones.
+	// It is nil unless --poll-results was requested.
+	refreshPoll func(context.Context, *telegram.Message) error
+	// chatInfo fetches the chat metadata and the participant directory of
+	// the given senders for the export header.
+	chatInfo func(context.Context, []int64) (*telegram.ChatInfo, error)
+	// senderName sets the display name of the message sender.
+	senderName func(context.Context, *telegram.Message) error
+	// digest is set for plans that export several chats or topics as
+	// sections of one file; each of them is marked as read on its own.
+	digest *chatDigest
 }
 
 func (a *App) buildFetchPlan(selectedChat telegram.Chat, selectedTopic *telegram.Topic, opts RunOptions) (fetchPlan, error) {
-	if selectedChat.IsForum {
-		if selectedTopic == nil {
-			return fetchPlan{}, fmt.Errorf("forum chat requires --topic-id or --topic")
+	// Only channel posts have view counts; --min-views would skip every
+	// message of a group.
+	if opts.MinViews > 0 && !selectedChat.IsBroadcast {
+		return fetchPlan{}, fmt.Errorf("--min-views requires a broadcast channel; %s has no view counts", selectedChat.Title)
+	}
+	plan, err := a.buildModePlan(selectedChat, selectedTopic, opts)
+	if err != nil {
+		return fetchPlan{}, err
+	}
+	if opts.WithPinned && !opts.Pinned {
+		topicID := topicIDOf(selectedTopic)
+		plan.pinned = func(ctx context.Context, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetPinnedMessages(ctx, selectedChat.ID, topicID, nil, progress)
+		}
+	}
+	names := opts.nameSource()
+	plan.chatInfo = func(ctx context.Context, senderIDs []int64) (*telegram.ChatInfo, error) {
+		// Without the address book, names fall back to the profile.
+		if err := a.tgClient.LoadContacts(ctx); err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		return a.tgClient.GetChatInfo(ctx, selectedChat, senderIDs, names)
+	}
+	plan.senderName = a.senderNameFunc(names)
+	if opts.PollResults {
+		plan.refreshPoll = func(ctx context.Context, msg *telegram.Message) error {
+			return a.tgClient.RefreshPoll(ctx, selectedChat.ID, msg)
 		}
+	}
+	return plan, nil
+}
+
+func (a *App) buildModePlan(selectedChat telegram.Chat, selectedTopic *telegram.Topic, opts RunOptions) (fetchPlan, error) {
+	if opts.AdminLog {
+		return a.adminLogPlan(selectedChat, opts)
+	}
+	if opts.Saved {
+		return a.savedPlan(selectedChat, opts), nil
+	}
+	if opts.AllTopics && selectedTopic == nil {
+		return a.allTopicsPlan(selectedChat, opts)
+	}
+	if selectedChat.IsForum && selectedTopic == nil {
+		return fetchPlan{}, fmt.Errorf("forum chat requires --topic-id or --topic")
+	}
+	if opts.Pinned {
+		return a.pinnedPlan(selectedChat, selectedTopic), nil
+	}
+	if opts.Last > 0 {
+		return a.lastMessagesPlan(selectedChat, selectedTopic, opts), nil
+	}
+	if opts.idRange() {
+		return a.idRangePlan(selectedChat, selectedTopic, opts), nil
+	}
+	if opts.UnreadMentions || opts.UnreadReactions {
+		return a.unreadMarksPlan(selectedChat, selectedTopic, opts), nil
+	}
+
+	if selectedChat.IsForum {
 		if opts.UseDateRange {
 			progressTitle := fmt.Sprintf("%s / %s (%s to %s)", selectedChat.Title, selectedTopic.Title, opts.Since.Format("2006-01-02"), opts.Until.Format("2006-01-02"))
 			return fetchPlan{
 				progressTitle: progressTitle,
 				exportTitle:   selectedChat.Title + " - " + selectedTopic.Title,
-				fetch: func(ctx context.Context, progress telegram.ProgressFunc) ([]telegram.Message, error) {
-					return a.tgClient.GetTopicMessagesByDate(ctx, selectedChat.ID, selectedTopic.ID, opts.Since, opts.Until, progress)
+				checkpoint:    dateRangeCheckpointKey(selectedChat.ID, selectedTopic.ID, opts),
+				fetch: func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+					if opts.Parallel > 1 {
+						return a.tgClient.GetTopicMessagesByDateParallel(ctx, selectedChat.ID, selectedTopic.ID, opts.Since, opts.Until, opts.Parallel, progress)
+					}
+					return a.tgClient.GetTopicMessagesByDate(ctx, selectedChat.ID, selectedTopic.ID, opts.Since, opts.Until, resume, progress)
+				},
+				stream: func(ctx context.Context, progress telegram.ProgressFunc) iter.Seq2[telegram.Message, error] {
+					return a.tgClient.StreamTopicMessagesByDate(ctx, selectedChat.ID, selectedTopic.ID, opts.Since, opts.Until, progress)
 				},
 			}, nil
 		}
@@ -32,9 +117,16 @@ func (a *App) buildFetchPlan(selectedChat telegram.Chat, selectedTopic *telegram
 		return fetchPlan{
 			progressTitle: progressTitle,
 			exportTitle:   selectedChat.Title + " - " + selectedTopic.Title,
-			fetch: func(ctx context.Context, progress telegram.ProgressFunc) ([]telegram.Message, error) {
-				return a.tgClient.GetTopicMessages(ctx, selectedChat.ID, selectedTopic.ID, selectedTopic.LastReadID, progress)
+			checkpoint:    checkpointKey{ChatID: selectedChat.ID, TopicID: selectedTopic.ID, Mode: "unread", LastReadID: selectedTopic.LastReadID},
+			fetch: func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+				return a.tgClient.GetTopicMessages(ctx, selectedChat.ID, selectedTopic.ID, selectedTopic.LastReadID, resume, progress)
+			},
+			stream: func(ctx context.Context, progress telegram.ProgressFunc) iter.Seq2[telegram.Message, error] {
+				return a.tgClient.StreamTopicMessages(ctx, selectedChat.ID, selectedTopic.ID, selectedTopic.LastReadID, progress)
 			},
+			readContext: contextFetch(opts, func(ctx context.Context, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+				return a.tgClient.GetTopicContextMessages(ctx, selectedChat.ID, selectedTopic.ID, selectedTopic.LastReadID, opts.Context, progress)
+			}),
 		}, nil
 	}
 
@@ -43,8 +135,15 @@ func (a *App) buildFetchPlan(selectedChat telegram.Chat, selectedTopic *telegram
 		return fetchPlan{
 			progressTitle: progressTitle,
 			exportTitle:   selectedChat.Title,
-			fetch: func(ctx context.Context, progress telegram.ProgressFunc) ([]telegram.Message, error) {
-				return a.tgClient.GetMessagesByDate(ctx, selectedChat.ID, opts.Since, opts.Until, progress)
+			checkpoint:    dateRangeCheckpointKey(selectedChat.ID, 0, opts),
+			fetch: func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+				if opts.Parallel > 1 {
+					return a.tgClient.GetMessagesByDateParallel(ctx, selectedChat.ID, opts.Since, opts.Until, opts.Parallel, progress)
+				}
+				return a.tgClient.GetMessagesByDate(ctx, selectedChat.ID, opts.Since, opts.Until, resume, progress)
+			},
+			stream: func(ctx context.Context, progress telegram.ProgressFunc) iter.Seq2[telegram.Message, error] {
+				return a.tgClient.StreamMessagesByDate(ctx, selectedChat.ID, opts.Since, opts.Until, progress)
 			},
 		}, nil
 	}
@@ -52,8 +151,323 @@ func (a *App) buildFetchPlan(selectedChat telegram.Chat, selectedTopic *telegram
 	return fetchPlan{
 		progressTitle: progressTitle,
 		exportTitle:   selectedChat.Title,
-		fetch: func(ctx context.Context, progress telegram.ProgressFunc) ([]telegram.Message, error) {
-			return a.tgClient.GetUnreadMessages(ctx, selectedChat.ID, selectedChat.LastReadID, progress)
+		checkpoint:    checkpointKey{ChatID: selectedChat.ID, Mode: "unread", LastReadID: selectedChat.LastReadID},
+		fetch: func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetUnreadMessages(ctx, selectedChat.ID, selectedChat.LastReadID, resume, progress)
+		},
+		stream: func(ctx context.Context, progress telegram.ProgressFunc) iter.Seq2[telegram.Message, error] {
+			return a.tgClient.StreamUnreadMessages(ctx, selectedChat.ID, selectedChat.LastReadID, progress)
+		},
+		readContext: contextFetch(opts, func(ctx context.Context, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetContextMessages(ctx, selectedChat.ID, selectedChat.LastReadID, opts.Context, progress)
+		}),
+	}, nil
+}
+
+// contextFetch returns fetch when --context was requested, nil otherwise.
+func contextFetch(opts RunOptions, fetch func(context.Context, telegram.ProgressFunc) ([]telegram.Message, error)) func(context.Context, telegram.ProgressFunc) ([]telegram.Message, error) {
+	if opts.Context <= 0 {
+		return nil
+	}
+	return fetch
+}
+
+func dateRangeCheckpointKey(chatID int64, topicID int, opts RunOptions) checkpointKey {
+	return checkpointKey{
+		ChatID:  chatID,
+		TopicID: topicID,
+		Mode:    "date-range",
+		Since:   opts.Since.Format("2006-01-02"),
+		Until:   opts.Until.Format("2006-01-02"),
+	}
+}
+
+func (a *App) lastMessagesPlan(selectedChat telegram.Chat, selectedTopic *telegram.Topic, opts RunOptions) fetchPlan {
+	plan := fetchPlan{
+		progressTitle: fmt.Sprintf("%s (last %d)", planTitle(selectedChat, selectedTopic, " / "), opts.Last),
+		exportTitle:   planTitle(selectedChat, selectedTopic, " - "),
+		checkpoint:    checkpointKey{ChatID: selectedChat.ID, Mode: "last", Limit: opts.Last, TopMessageID: selectedChat.TopMessageID},
+	}
+	if selectedTopic != nil {
+		plan.checkpoint.TopicID = selectedTopic.ID
+		plan.checkpoint.TopMessageID = selectedTopic.TopMessageID
+		plan.fetch = func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetTopicLastMessages(ctx, selectedChat.ID, selectedTopic.ID, opts.Last, resume, progress)
+		}
+		return plan
+	}
+	plan.fetch = func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+		return a.tgClient.GetLastMessages(ctx, selectedChat.ID, opts.Last, resume, progress)
+	}
+	return plan
+}
+
+func (a *App) idRangePlan(selectedChat telegram.Chat, selectedTopic *telegram.Topic, opts RunOptions) fetchPlan {
+	plan := fetchPlan{
+		progressTitle: fmt.Sprintf("%s (messages %s)", planTitle(selectedChat, selectedTopic, " / "), formatIDRange(opts, "-")),
+		exportTitle:   planTitle(selectedChat, selectedTopic, " - "),
+		checkpoint:    checkpointKey{ChatID: selectedChat.ID, Mode: "id-range", FromID: opts.FromID, ToID: opts.ToID},
+	}
+	if selectedTopic != nil {
+		plan.checkpoint.TopicID = selectedTopic.ID
+		plan.fetch = func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetTopicMessagesByIDRange(ctx, selectedChat.ID, selectedTopic.ID, opts.FromID, opts.ToID, resume, progress)
+		}
+		return plan
+	}
+	plan.fetch = func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+		return a.tgClient.GetMessagesByIDRange(ctx, selectedChat.ID, opts.FromID, opts.ToID, resume, progress)
+	}
+	return plan
+}
+
+// unreadMarksPlan fetches unread mentions or unread reactions, scoped to the
+// topic when one is selected.
+func (a *App) unreadMarksPlan(selectedChat telegram.Chat, selectedTopic *telegram.Topic, opts RunOptions) fetchPlan {
+	mode := "mentions"
+	get := a.tgClient.GetUnreadMentions
+	if opts.UnreadReactions {
+		mode = "reactions"
+		get = a.tgClient.GetUnreadReactions
+	}
+	topicID := topicIDOf(selectedTopic)
+	return fetchPlan{
+		progressTitle: fmt.Sprintf("%s (unread %s)", planTitle(selectedChat, selectedTopic, " / "), mode),
+		exportTitle:   planTitle(selectedChat, selectedTopic, " - "),
+		checkpoint:    checkpointKey{ChatID: selectedChat.ID, TopicID: topicID, Mode: mode},
+		fetch: func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return get(ctx, selectedChat.ID, topicID, resume, progress)
+		},
+	}
+}
+
+// pinnedPlan fetches the pinned messages of the chat or topic.
+func (a *App) pinnedPlan(selectedChat telegram.Chat, selectedTopic *telegram.Topic) fetchPlan {
+	topicID := topicIDOf(selectedTopic)
+	return fetchPlan{
+		progressTitle: fmt.Sprintf("%s (pinned)", planTitle(selectedChat, selectedTopic, " / ")),
+		exportTitle:   planTitle(selectedChat, selectedTopic, " - "),
+		checkpoint:    checkpointKey{ChatID: selectedChat.ID, TopicID: topicID, Mode: "pinned"},
+		fetch: func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetPinnedMessages(ctx, selectedChat.ID, topicID, resume, progress)
+		},
+	}
+}
+
+// adminLogPlan fetches the admin log of a supergroup or channel. The log
+// covers the whole chat, so forum topics are ignored.
+func (a *App) adminLogPlan(selectedChat telegram.Chat, opts RunOptions) (fetchPlan, error) {
+	if !selectedChat.IsChannel {
+		return fetchPlan{}, fmt.Errorf("admin log is only available for supergroups and channels")
+	}
+	var since, until time.Time
+	checkpoint := checkpointKey{ChatID: selectedChat.ID, Mode: "admin-log", Events: adminLogEventsKey(opts.AdminLogEvents)}
+	if opts.UseDateRange {
+		since, until = opts.Since, opts.Until
+		checkpoint.Since = opts.Since.Format("2006-01-02")
+		checkpoint.Until = opts.Until.Format("2006-01-02")
+	}
+	return fetchPlan{
+		progressTitle: fmt.Sprintf("%s (admin log)", selectedChat.Title),
+		exportTitle:   selectedChat.Title,
+		checkpoint:    checkpoint,
+		fetch: func(ctx context.Context, resume *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetAdminLog(ctx, selectedChat.ID, opts.AdminLogEvents, since, until, resume, progress)
+		},
+	}, nil
+}
+
+// savedPlan fetches the Saved Messages digest. Items are fetched per source,
+// so the digest is not checkpointed.
+func (a *App) savedPlan(selectedChat telegram.Chat, opts RunOptions) fetchPlan {
+	var since, until time.Time
+	checkpoint := checkpointKey{ChatID: selectedChat.ID, Mode: "saved"}
+	if opts.UseDateRange {
+		since, until = opts.Since, opts.Until
+		checkpoint.Since = opts.Since.Format("2006-01-02")
+		checkpoint.Until = opts.Until.Format("2006-01-02")
+	}
+	return fetchPlan{
+		progressTitle: fmt.Sprintf("%s (by source)", telegram.SavedMessagesTitle),
+		exportTitle:   telegram.SavedMessagesTitle,
+		checkpoint:    checkpoint,
+		fetch: func(ctx context.Context, _ *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetSavedMessages(ctx, since, until, progress)
+		},
+	}
+}
+
+// searchPlan runs a global search across all chats. Hits come from many
+// chats, so the plan has no chat metadata and is not checkpointed.
+func (a *App) searchPlan(opts RunOptions) fetchPlan {
+	var since, until time.Time
+	progressTitle := fmt.Sprintf("Search %q", opts.Search)
+	if opts.UseDateRange {
+		since, until = opts.Since, opts.Until
+		progressTitle += fmt.Sprintf(" (%s to %s)", opts.Since.Format("2006-01-02"), opts.Until.Format("2006-01-02"))
+	}
+	return fetchPlan{
+		progressTitle: progressTitle,
+		exportTitle:   "Search: " + opts.Search,
+		checkpoint:    checkpointKey{Mode: "search"},
+		fetch: func(ctx context.Context, _ *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.SearchGlobal(ctx, opts.Search, opts.SearchScope, since, until, progress)
+		},
+		senderName: a.senderNameFunc(opts.nameSource()),
+	}
+}
+
+// senderPlan fetches the messages one user sent in all chats shared with
+// them, merged by date. Like searchPlan it spans many chats and is not
+// checkpointed.
+func (a *App) senderPlan(opts RunOptions) fetchPlan {
+	var since, until time.Time
+	progressTitle := "Messages from " + opts.Sender
+	if opts.UseDateRange {
+		since, until = opts.Since, opts.Until
+		progressTitle += fmt.Sprintf(" (%s to %s)", opts.Since.Format("2006-01-02"), opts.Until.Format("2006-01-02"))
+	}
+	return fetchPlan{
+		progressTitle: progressTitle,
+		exportTitle:   "Messages from " + opts.Sender,
+		checkpoint:    checkpointKey{Mode: "sender"},
+		fetch: func(ctx context.Context, _ *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetSenderMessages(ctx, opts.Sender, since, until, progress)
+		},
+		senderName: a.senderNameFunc(opts.nameSource()),
+	}
+}
+
+// hashtagPlan fetches the messages tagged with opts.Hashtags in chats, or in
+// all chats when chats is nil, grouped by tag. Like searchPlan it spans many
+// chats and is not checkpointed.
+func (a *App) hashtagPlan(chats []telegram.Chat, opts RunOptions) fetchPlan {
+	var since, until time.Time
+	tags := strings.Join(opts.Hashtags, " ")
+	progressTitle := "Hashtags " + tags
+	if opts.UseDateRange {
+		since, until = opts.Since, opts.Until
+		progressTitle += fmt.Sprintf(" (%s to %s)", opts.Since.Format("2006-01-02"), opts.Until.Format("2006-01-02"))
+	}
+	exportTitle := "Hashtags: " + tags
+	switch {
+	case len(chats) == 1 && opts.Folder == "":
+		exportTitle += " in " + chats[0].Title
+	case opts.Folder != "":
+		exportTitle += " in folder " + opts.Folder
+	}
+	return fetchPlan{
+		progressTitle: progressTitle,
+		exportTitle:   exportTitle,
+		checkpoint:    checkpointKey{Mode: "hashtags"},
+		fetch: func(ctx context.Context, _ *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			return a.tgClient.GetHashtagMessages(ctx, opts.Hashtags, chats, since, until, progress)
+		},
+		senderName: a.senderNameFunc(opts.nameSource()),
+	}
+}
+
+// allTopicsPlan fetches the messages of every forum topic with activity, a
+// section per topic: the topics with unread messages, or all topics for
+// date range exports. Topics are fetched and marked as read one by one, so
+// the plan is not checkpointed.
+func (a *App) allTopicsPlan(selectedChat telegram.Chat, opts RunOptions) (fetchPlan, error) {
+	if !selectedChat.IsForum {
+		return fetchPlan{}, fmt.Errorf("chat %q is not a forum", selectedChat.Title)
+	}
+	if !opts.unreadMode() && !opts.UseDateRange {
+		return fetchPlan{}, fmt.Errorf("all topics only applies to unread and date range exports")
+	}
+	if opts.Stream {
+		return fetchPlan{}, fmt.Errorf("all topics cannot be streamed")
+	}
+	progressTitle := fmt.Sprintf("%s / all topics (unread)", selectedChat.Title)
+	if opts.UseDateRange {
+		progressTitle = fmt.Sprintf("%s / all topics (%s to %s)", selectedChat.Title, opts.Since.Format("2006-01-02"), opts.Until.Format("2006-01-02"))
+	}
+	digest := &chatDigest{}
+	return fetchPlan{
+		progressTitle: progressTitle,
+		exportTitle:   selectedChat.Title + " - All topics",
+		checkpoint:    checkpointKey{ChatID: selectedChat.ID, Mode: "topics"},
+		fetch: func(ctx context.Context, _ *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			topics, err := a.tgClient.GetForumTopics(ctx, selectedChat.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get forum topics: %w", err)
+			}
+			digest.entries = topicEntries(selectedChat, topics, opts)
+			return a.fetchDigest(ctx, digest, opts, progress)
 		},
+		digest: digest,
 	}, nil
 }
+
+// topicEntries returns the digest entries of the forum topics with
+// activity: for date range exports the topics whose newest message is not
+// older than the range, the ones with unread messages otherwise.
+func topicEntries(forum telegram.Chat, topics []telegram.Topic, opts RunOptions) []digestEntry {
+	var entries []digestEntry
+	for i := range topics {
+		if topicActive(topics[i], opts) {
+			entries = append(entries, digestEntry{chat: forum, topic: &topics[i], title: topics[i].Title})
+		}
+	}
+	return entries
+}
+
+// topicActive reports whether topic may have messages to export. Topics
+// without a known top message date are kept in date range mode.
+func topicActive(topic telegram.Topic, opts RunOptions) bool {
+	if !opts.UseDateRange {
+		return topic.UnreadCount > 0
+	}
+	return topic.TopMessageDate.IsZero() || !topic.TopMessageDate.Before(opts.Since)
+}
+
+// senderNameFunc returns a function that sets sender display names from
+// the cached users and the address book.
+func (a *App) senderNameFunc(names telegram.NameSource) func(context.Context, *telegram.Message) error {
+	return func(ctx context.Context, msg *telegram.Message) error {
+		// Without the address book, names fall back to the profile.
+		if err := a.tgClient.LoadContacts(ctx); err != nil && ctx.Err() != nil {
+			return err
+		}
+		msg.SenderName = a.tgClient.SenderName(msg.SenderID, names)
+		return nil
+	}
+}
+
+// adminLogEventsKey joins the event categories for the checkpoint key.
+func adminLogEventsKey(events []telegram.AdminLogEvent) string {
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		names = append(names, string(event))
+	}
+	return strings.Join(names, ",")
+}
+
+// topicIDOf returns the topic ID, or 0 when no topic is selected.
+func topicIDOf(selectedTopic *telegram.Topic) int {
+	if selectedTopic == nil {
+		return 0
+	}
+	return selectedTopic.ID
+}
+
+// planTitle joins the chat and topic titles with sep.
+func planTitle(selectedChat telegram.Chat, selectedTopic *telegram.Topic, sep string) string {
+	if selectedTopic == nil {
+		return selectedChat.Title
+	}
+	return selectedChat.Title + sep + selectedTopic.Title
+}
+
+// formatIDRange renders the requested message ID range, using "latest" for
+// an open upper bound.
+func formatIDRange(opts RunOptions, sep string) string {
+	to := "latest"
+	if opts.ToID > 0 {
+		to = fmt.Sprintf("%d", opts.ToID)
+	}
+	return fmt.Sprintf("%d%s%s", opts.FromID, sep, to)
+}
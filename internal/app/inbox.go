@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+// runInbox exports the unread messages of all chats into one digest and
+// marks them as read only after the file was written.
+func (a *App) runInbox(ctx context.Context, opts RunOptions) error {
+	chats, err := a.tgClient.GetDialogs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get dialogs: %w", err)
+	}
+	plan := a.inboxPlan(chats, opts)
+	messages, err := plan.fetch(ctx, nil, nil)
+	if err != nil {
+		return err
+	}
+	sections := ExportSections{warnings: plan.digest.failures}
+	for _, line := range sections.warningLines() {
+		fmt.Fprintln(os.Stderr, line)
+	}
+	if len(plan.digest.entries) == 0 {
+		fmt.Fprintln(os.Stderr, "No unread chats found.")
+		return nil
+	}
+	if len(messages) == 0 {
+		fmt.Fprintln(os.Stderr, "No text messages found to export.")
+		return nil
+	}
+	if err := a.nameSenders(ctx, plan, messages); err != nil {
+		return err
+	}
+	filename, err := a.exportMessages(plan.exportTitle, messages, sections, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Successfully exported %d messages to %s\n", countMessages(messages), filename)
+	fmt.Fprintln(os.Stderr, a.markDigestAsRead(ctx, plan.digest, opts))
+	return nil
+}
+
+// inboxPlan fetches the unread messages of the chats that pass the inbox
+// filters, a section per chat or forum topic in order of priority. Like
+// searchPlan it spans many chats and is not checkpointed.
+func (a *App) inboxPlan(chats []telegram.Chat, opts RunOptions) fetchPlan {
+	title := "Inbox"
+	if opts.Folder != "" {
+		title += ": " + opts.Folder
+	}
+	digest := &chatDigest{}
+	return fetchPlan{
+		progressTitle: title + " (unread)",
+		exportTitle:   title,
+		checkpoint:    checkpointKey{Mode: "inbox"},
+		fetch: func(ctx context.Context, _ *telegram.Cursor, progress telegram.ProgressFunc) ([]telegram.Message, error) {
+			entries, failures, err := a.inboxEntries(ctx, chats, opts)
+			if err != nil {
+				return nil, err
+			}
+			digest.entries = entries
+			digest.failures = failures
+			return a.fetchDigest(ctx, digest, opts, progress)
+		},
+		senderName: a.senderNameFunc(opts.nameSource()),
+		digest:     digest,
+	}
+}
+
+// inboxEntries lists the chats and forum topics with unread messages that
+// pass the inbox filters, ordered by priority. Forums whose topics cannot
+// be listed are described in failures and left out.
+func (a *App) inboxEntries(ctx context.Context, chats []telegram.Chat, opts RunOptions) ([]digestEntry, []string, error) {
+	var folder *telegram.Folder
+	if opts.Folder != "" {
+		var err error
+		folder, err = a.findFolder(ctx, opts.Folder)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var entries []digestEntry
+	var failures []string
+	for _, chat := range inboxChats(chats, folder, opts) {
+		if !chat.IsForum {
+			entries = append(entries, digestEntry{chat: chat, title: chat.Title})
+			continue
+		}
+		topics, err := a.tgClient.GetForumTopics(ctx, chat.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, fmt.Errorf("failed to get forum topics: %w", err)
+			}
+			failures = append(failures, fmt.Sprintf("skipped %s: failed to get forum topics: %v", chat.Title, err))
+			continue
+		}
+		for _, entry := range topicEntries(chat, topics, opts) {
+			entry.title = chat.Title + " / " + entry.title
+			entries = append(entries, entry)
+		}
+	}
+	sortInbox(entries)
+	return entries, failures, nil
+}
+
+// inboxChats returns the chats with unread messages that can be exported,
+// belong to folder when it is set and are neither muted nor archived
+// unless opts includes them.
+func inboxChats(chats []telegram.Chat, folder *telegram.Folder, opts RunOptions) []telegram.Chat {
+	var selected []telegram.Chat
+	for _, chat := range chats {
+		switch {
+		case chat.UnreadCount == 0 || chat.IsSelf || chat.ExportError() != nil:
+		case chat.Muted && !opts.InboxMuted:
+		case chat.Archived && !opts.InboxArchived:
+		case folder != nil && !folder.Contains(chat):
+		default:
+			selected = append(selected, chat)
+		}
+	}
+	return selected
+}
+
+// sortInbox orders entries by priority, keeping the dialog order, which is
+// the most recent activity first, within the same priority.
+func sortInbox(entries []digestEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return inboxPriority(entries[i]) < inboxPriority(entries[j])
+	})
+}
+
+// inboxPriority ranks chats and topics with unread mentions of the user or
+// reactions to the user's messages first, then private chats, groups and
+// finally channels and bots.
+func inboxPriority(entry digestEntry) int {
+	mentions, reactions := entry.chat.UnreadMentions, entry.chat.UnreadReactions
+	if entry.topic != nil {
+		mentions, reactions = entry.topic.UnreadMentions, entry.topic.UnreadReactions
+	}
+	switch {
+	case mentions > 0 || reactions > 0:
+		return 0
+	case entry.chat.IsBot || entry.chat.IsBroadcast:
+		return 3
+	case entry.chat.IsUser:
+		return 1
+	default:
+		return 2
+	}
+}
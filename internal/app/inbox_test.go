@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+func TestInboxChats(t *testing.T) {
+	chats := []telegram.Chat{
+		{ID: 1, Title: "Read", IsUser: true},
+		{ID: 2, Title: "Alice", UnreadCount: 3, IsUser: true},
+		{ID: 3, Title: "Muted", UnreadCount: 5, Muted: true},
+		{ID: 4, Title: "Archived", UnreadCount: 1, Archived: true},
+		{ID: 5, Title: "Banned", UnreadCount: 2, Forbidden: true},
+		{ID: 6, Title: "Saved Messages", UnreadCount: 1, IsSelf: true},
+	}
+
+	got := inboxChats(chats, nil, RunOptions{Inbox: true})
+	if len(got) != 1 || got[0].ID != 2 {
+		t.Fatalf("unexpected inbox chats: %+v", got)
+	}
+
+	got = inboxChats(chats, nil, RunOptions{Inbox: true, InboxMuted: true, InboxArchived: true})
+	if len(got) != 3 || got[1].ID != 3 || got[2].ID != 4 {
+		t.Fatalf("unexpected inbox chats with muted and archived: %+v", got)
+	}
+}
+
+func TestSortInbox(t *testing.T) {
+	entries := []digestEntry{
+		{chat: telegram.Chat{ID: 1, Title: "News", IsBroadcast: true}, title: "News"},
+		{chat: telegram.Chat{ID: 2, Title: "Team"}, title: "Team"},
+		{chat: telegram.Chat{ID: 3, Title: "Bot", IsUser: true, IsBot: true}, title: "Bot"},
+		{chat: telegram.Chat{ID: 4, Title: "Alice", IsUser: true}, title: "Alice"},
+		{chat: telegram.Chat{ID: 5, Title: "Forum", IsForum: true}, topic: &telegram.Topic{ID: 7, Title: "Releases", UnreadMentions: 1}, title: "Forum / Releases"},
+		{chat: telegram.Chat{ID: 6, Title: "Ops"}, title: "Ops"},
+	}
+	sortInbox(entries)
+
+	var titles []string
+	for _, entry := range entries {
+		titles = append(titles, entry.title)
+	}
+	want := "Forum / Releases,Alice,Team,Ops,News,Bot"
+	if got := strings.Join(titles, ","); got != want {
+		t.Fatalf("unexpected order: %s, want %s", got, want)
+	}
+}
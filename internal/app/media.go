@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+// zeroDecimalCurrencies lists currencies whose smallest unit is the whole
+// unit. Telegram Stars (XTR) are counted in whole stars too.
+var zeroDecimalCurrencies = map[string]bool{
+	"CLP": true, "ISK": true, "JPY": true, "KRW": true, "PYG": true,
+	"UGX": true, "VND": true, "XAF": true, "XOF": true, "XTR": true,
+}
+
+// mediaLine renders media as one concise line, e.g.
+// "link: <title> — <description>".
+func mediaLine(media *TemplateMedia) string {
+	summary := mediaSummary(media)
+	if summary == "" {
+		return ""
+	}
+	return media.Kind + ": " + summary
+}
+
+// mediaSummary renders media without its kind prefix.
+func mediaSummary(media *TemplateMedia) string {
+	if media == nil {
+		return ""
+	}
+	switch media.Kind {
+	case telegram.MediaLink:
+		return joinNonEmpty(" — ", oneLine(firstNonEmpty(media.Title, media.SiteName, media.URL)), oneLine(media.Description))
+	case telegram.MediaLocation:
+		return formatCoordinates(media)
+	case telegram.MediaVenue:
+		return fmt.Sprintf("%s (%s)", joinNonEmpty(", ", media.Title, media.Address), formatCoordinates(media))
+	case telegram.MediaContact:
+		return joinNonEmpty(", ", media.Title, media.Phone)
+	case telegram.MediaDice:
+		return fmt.Sprintf("%s %d", media.Emoji, media.Value)
+	case telegram.MediaInvoice:
+		return joinNonEmpty(" — ", oneLine(media.Title), formatAmount(media.Amount, media.Currency))
+	case telegram.MediaGame:
+		return joinNonEmpty(" — ", oneLine(media.Title), oneLine(media.Description))
+	default:
+		return ""
+	}
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// oneLine collapses whitespace, including newlines, into single spaces.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func formatCoordinates(media *TemplateMedia) string {
+	return fmt.Sprintf("%.5f, %.5f", media.Latitude, media.Longitude)
+}
+
+// formatAmount renders an amount given in the smallest currency units.
+func formatAmount(amount int64, currency string) string {
+	if currency == "" {
+		return ""
+	}
+	if zeroDecimalCurrencies[currency] {
+		return fmt.Sprintf("%d %s", amount, currency)
+	}
+	return fmt.Sprintf("%d.%02d %s", amount/100, amount%100, currency)
+}
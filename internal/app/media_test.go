@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+
+	"cli-tg-chat-summary/internal/telegram"
+)
+
+func TestMediaLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		media TemplateMedia
+		want  string
+	}{
+		{
+			name:  "link with title",
+			media: TemplateMedia{Kind: telegram.MediaLink, URL: "https://example.com", Title: "Go 1.25", Description: "Release\nnotes"},
+			want:  "link: Go 1.25 — Release notes",
+		},
+		{
+			name:  "bare link",
+			media: TemplateMedia{Kind: telegram.MediaLink, URL: "https://example.com"},
+			want:  "link: https://example.com",
+		},
+		{
+			name:  "venue",
+			media: TemplateMedia{Kind: telegram.MediaVenue, Title: "Office", Address: "Main St 1", Latitude: 52.52, Longitude: 13.405},
+			want:  "venue: Office, Main St 1 (52.52000, 13.40500)",
+		},
+		{
+			name:  "contact",
+			media: TemplateMedia{Kind: telegram.MediaContact, Title: "Ada Lovelace", Phone: "+100"},
+			want:  "contact: Ada Lovelace, +100",
+		},
+		{
+			name:  "dice",
+			media: TemplateMedia{Kind: telegram.MediaDice, Emoji: "🎯", Value: 6},
+			want:  "dice: 🎯 6",
+		},
+		{
+			name:  "invoice",
+			media: TemplateMedia{Kind: telegram.MediaInvoice, Title: "Ticket", Currency: "EUR", Amount: 4950},
+			want:  "invoice: Ticket — 49.50 EUR",
+		},
+		{
+			name:  "zero decimal invoice",
+			media: TemplateMedia{Kind: telegram.MediaInvoice, Title: "Ticket", Currency: "JPY", Amount: 500},
+			want:  "invoice: Ticket — 500 JPY",
+		},
+		{
+			name:  "game",
+			media: TemplateMedia{Kind: telegram.MediaGame, Title: "Lumberjack", Description: "Chop wood"},
+			want:  "game: Lumberjack — Chop wood",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mediaLine(&tt.media); got != tt.want {
+				t.Fatalf("mediaLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
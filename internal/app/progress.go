@@ -11,6 +11,10 @@ import (
 
 type fetchResult struct {
 	messages []telegram.Message
+	sections ExportSections
+	// streamed is set when messages were written to the export file while
+	// fetching; messages is empty in that case.
+	streamed *streamedExport
 	err      error
 }
 
@@ -26,6 +30,24 @@ type fetchHandle struct {
 }
 
 func (a *App) startFetchWithProgress(opts FetchOpts, fetch func(context.Context, telegram.ProgressFunc) ([]telegram.Message, error)) fetchHandle {
+	return a.runWithProgress(opts, func(ctx context.Context, progress telegram.ProgressFunc) fetchResult {
+		messages, err := fetch(ctx, progress)
+		return fetchResult{messages: messages, err: err}
+	})
+}
+
+// startStreamWithProgress runs a streamed export of plan in the background.
+func (a *App) startStreamWithProgress(opts FetchOpts, plan fetchPlan, runOpts RunOptions) fetchHandle {
+	return a.runWithProgress(opts, func(ctx context.Context, progress telegram.ProgressFunc) fetchResult {
+		result, err := a.exportStream(ctx, plan, runOpts, progress)
+		if err != nil {
+			return fetchResult{err: err}
+		}
+		return fetchResult{streamed: &result}
+	})
+}
+
+func (a *App) runWithProgress(opts FetchOpts, run func(context.Context, telegram.ProgressFunc) fetchResult) fetchHandle {
 	msgCh := make(chan tea.Msg, 128)
 	resultCh := make(chan fetchResult, 1)
 	fetchCtx, cancel := context.WithCancel(opts.Ctx)
@@ -46,8 +68,7 @@ func (a *App) startFetchWithProgress(opts FetchOpts, fetch func(context.Context,
 			}
 		}
 
-		messages, err := fetch(fetchCtx, progressFn)
-		resultCh <- fetchResult{messages: messages, err: err}
+		resultCh <- run(fetchCtx, progressFn)
 		close(msgCh)
 	}()
 
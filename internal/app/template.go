@@ -14,12 +14,93 @@ type Template interface {
 	Render(w io.Writer, input TemplateInput) error
 }
 
+// StreamTemplate is implemented by templates that can write messages as they
+// arrive. The header is written by Stream, so input.Messages is ignored and
+// the total message count is written by MessageWriter.Close instead.
+type StreamTemplate interface {
+	Template
+	Stream(w io.Writer, input TemplateInput) (MessageWriter, error)
+}
+
+// MessageWriter receives messages in chronological order.
+type MessageWriter interface {
+	WriteMessage(msg TemplateMessage) error
+	Close() error
+}
+
 type TemplateInput struct {
 	ExportTitle   string
 	ExportDate    time.Time
 	TotalMessages int
 	Messages      []TemplateMessage
 	Options       RunOptions
+	// Partial is set when the fetch was canceled and the export only
+	// covers the messages collected before that.
+	Partial *TemplateRange
+	// Context holds already read messages preceding Messages, rendered in
+	// a separate section and not counted in TotalMessages.
+	Context []TemplateMessage
+	// Attention lists the messages that mention the user or reply to the
+	// user's messages, for the optional "Needs your attention" section.
+	Attention []TemplateMessage
+	// Pinned holds the chat's pinned messages for the "Pinned" section.
+	Pinned []TemplateMessage
+	// TopPosts ranks the exported messages by engagement for the optional
+	// "Top posts" section.
+	TopPosts []TemplateMessage
+	// Chat holds the chat metadata and participant directory rendered in
+	// the header. It is nil for private chats.
+	Chat *TemplateChatInfo
+}
+
+// TemplateChatInfo mirrors telegram.ChatInfo.
+type TemplateChatInfo struct {
+	Description     string
+	MemberCount     int
+	LinkedChatID    int64
+	LinkedChatTitle string
+	SlowModeSeconds int
+	Created         time.Time
+	Participants    []TemplateParticipant
+}
+
+// TemplateParticipant is one entry of the participant directory.
+type TemplateParticipant struct {
+	ID         int64
+	Name       string
+	Username   string
+	Bot        bool
+	AdminTitle string
+}
+
+// summary describes the participant in one line, e.g.
+// "Jane Doe, @jane, admin: Moderator".
+func (p TemplateParticipant) summary() string {
+	var parts []string
+	if p.Name != "" {
+		parts = append(parts, p.Name)
+	}
+	if p.Username != "" && p.Name != "@"+p.Username {
+		parts = append(parts, "@"+p.Username)
+	}
+	if p.Bot {
+		parts = append(parts, "bot")
+	}
+	if p.AdminTitle != "" {
+		parts = append(parts, "admin: "+p.AdminTitle)
+	}
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// TemplateRange describes the message IDs and times covered by an export.
+type TemplateRange struct {
+	FirstID int
+	LastID  int
+	From    time.Time
+	To      time.Time
 }
 
 type TemplateMessage struct {
@@ -30,6 +111,104 @@ type TemplateMessage struct {
 	SenderName string
 	ReplyTo    *TemplateReply
 	Reactions  []TemplateReaction
+	Mentioned  bool
+	ReplyToMe  bool
+	Poll       *TemplatePoll
+	Media      *TemplateMedia
+	Buttons    []TemplateButton
+	Views      int
+	Forwards   int
+	Replies    int
+	// Event is set for marker entries such as telegram.EventMigration;
+	// they are rendered as a single line and not counted as messages.
+	Event string
+	// AdminAction is the kind of an admin log entry, e.g.
+	// telegram.AdminActionBan.
+	AdminAction string
+	Forward     *TemplateForward
+	// Group is the section heading of the message in digests that combine
+	// several sources; a new section starts whenever it changes.
+	Group string
+	Tags  []string
+	// Chat is the chat of a message in digests whose sections are not
+	// chats, e.g. hashtag digests; it is empty when Group already names it.
+	Chat string
+	// Link is the permalink of a message found by a global search.
+	Link string
+}
+
+// TemplateForward mirrors telegram.Forward.
+type TemplateForward struct {
+	FromID     int64
+	FromName   string
+	Date       time.Time
+	PostID     int
+	PostAuthor string
+}
+
+// reactionCount returns the total number of reactions on the message.
+func (m TemplateMessage) reactionCount() int {
+	total := 0
+	for _, reaction := range m.Reactions {
+		total += reaction.Count
+	}
+	return total
+}
+
+type TemplateButton struct {
+	Kind  string
+	Text  string
+	Value string
+}
+
+// TemplateMedia mirrors telegram.Media; only the fields that apply to Kind
+// are set.
+type TemplateMedia struct {
+	Kind        string
+	URL         string
+	SiteName    string
+	Title       string
+	Description string
+	Latitude    float64
+	Longitude   float64
+	Address     string
+	Phone       string
+	Emoji       string
+	Value       int
+	Currency    string
+	Amount      int64
+}
+
+type TemplatePoll struct {
+	Question    string
+	Options     []TemplatePollOption
+	TotalVoters int
+	Quiz        bool
+	Closed      bool
+}
+
+type TemplatePollOption struct {
+	Text    string
+	Voters  int
+	Correct bool
+}
+
+// needsAttention reports whether the message mentions the user or replies
+// to the user's messages.
+func (m TemplateMessage) needsAttention() bool {
+	return m.Mentioned || m.ReplyToMe
+}
+
+// attentionReason describes why the message needs attention.
+func (m TemplateMessage) attentionReason() string {
+	switch {
+	case m.Mentioned && m.ReplyToMe:
+		return "mention, reply"
+	case m.Mentioned:
+		return "mention"
+	default:
+		return "reply"
+	}
 }
 
 type TemplateReply struct {
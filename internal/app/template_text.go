@@ -21,18 +21,257 @@ func (t textTemplate) Extension() string {
 }
 
 func (t textTemplate) Render(w io.Writer, input TemplateInput) error {
+	if err := writeTextHeader(w, input); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Total Messages: %d\n\n", input.TotalMessages); err != nil {
+		return fmt.Errorf("failed to write count: %w", err)
+	}
+	if err := writeTextPinned(w, input); err != nil {
+		return err
+	}
+	if err := writeTextTopPosts(w, input); err != nil {
+		return err
+	}
+	if err := writeTextAttention(w, input); err != nil {
+		return err
+	}
+	if err := writeTextContext(w, input); err != nil {
+		return err
+	}
+
+	blocks := buildMessageBlocks(input.Messages, newTextDetails(input.Options))
+	if err := writeMessageBlocks(w, blocks); err != nil {
+		return fmt.Errorf("failed to write message blocks: %w", err)
+	}
+	return nil
+}
+
+func (t textTemplate) Stream(w io.Writer, input TemplateInput) (MessageWriter, error) {
+	if err := writeTextHeader(w, input); err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	if err := writeTextPinned(w, input); err != nil {
+		return nil, err
+	}
+	if err := writeTextContext(w, input); err != nil {
+		return nil, err
+	}
+	return &textMessageWriter{w: w, details: newTextDetails(input.Options)}, nil
+}
+
+// writeTextPinned writes the pinned messages section. Pinned messages are
+// usually far apart, so each one is listed with its full date.
+func writeTextPinned(w io.Writer, input TemplateInput) error {
+	if len(input.Pinned) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "Pinned (%d):\n", len(input.Pinned)); err != nil {
+		return fmt.Errorf("failed to write pinned section: %w", err)
+	}
+	for _, msg := range input.Pinned {
+		lines := messageLines(msg, newTextDetails(input.Options))
+		if len(lines) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "[%s] %s:\n", msg.Date.Format("2006-01-02 15:04"), formatSender(msg.SenderID, msg.SenderName)); err != nil {
+			return fmt.Errorf("failed to write pinned section: %w", err)
+		}
+		for _, line := range lines {
+			if _, err := fmt.Fprintf(w, "  %s\n", line); err != nil {
+				return fmt.Errorf("failed to write pinned section: %w", err)
+			}
+		}
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return fmt.Errorf("failed to write pinned section: %w", err)
+	}
+	return nil
+}
+
+// writeTextTopPosts ranks the most engaging posts, one line each.
+func writeTextTopPosts(w io.Writer, input TemplateInput) error {
+	if len(input.TopPosts) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "Top posts (%d):\n", len(input.TopPosts)); err != nil {
+		return fmt.Errorf("failed to write top posts: %w", err)
+	}
+	for i, msg := range input.TopPosts {
+		if _, err := fmt.Fprintf(w, "  %d. [%s] %s (%s): %s\n", i+1,
+			msg.Date.Format("2006-01-02 15:04"), formatSender(msg.SenderID, msg.SenderName),
+			engagementSummary(msg, true), firstLine(msg)); err != nil {
+			return fmt.Errorf("failed to write top posts: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return fmt.Errorf("failed to write top posts: %w", err)
+	}
+	return nil
+}
+
+// writeTextAttention lists messages that mention the user or reply to the
+// user's messages, one line each.
+func writeTextAttention(w io.Writer, input TemplateInput) error {
+	if len(input.Attention) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "Needs your attention (%d):\n", len(input.Attention)); err != nil {
+		return fmt.Errorf("failed to write attention section: %w", err)
+	}
+	for _, msg := range input.Attention {
+		if _, err := fmt.Fprintf(w, "  [%s] %s (%s): %s\n",
+			msg.Date.Format("2006-01-02 15:04"), formatSender(msg.SenderID, msg.SenderName),
+			msg.attentionReason(), normalizeLines(msg.Text)[0]); err != nil {
+			return fmt.Errorf("failed to write attention section: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return fmt.Errorf("failed to write attention section: %w", err)
+	}
+	return nil
+}
+
+// writeTextContext writes the already read context section and the heading
+// of the unread messages that follow it.
+func writeTextContext(w io.Writer, input TemplateInput) error {
+	if len(input.Context) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "Context (already read):"); err != nil {
+		return fmt.Errorf("failed to write context: %w", err)
+	}
+	if err := writeMessageBlocks(w, buildMessageBlocks(input.Context, newTextDetails(input.Options))); err != nil {
+		return fmt.Errorf("failed to write context: %w", err)
+	}
+	if _, err := fmt.Fprint(w, "\nUnread:\n"); err != nil {
+		return fmt.Errorf("failed to write context: %w", err)
+	}
+	return nil
+}
+
+func writeTextHeader(w io.Writer, input TemplateInput) error {
 	if _, err := fmt.Fprintf(w, "Chat Summary: %s\n", input.ExportTitle); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
 	if _, err := fmt.Fprintf(w, "Export Date: %s\n", input.ExportDate.Format(time.RFC1123)); err != nil {
 		return fmt.Errorf("failed to write date: %w", err)
 	}
-	if _, err := fmt.Fprintf(w, "Total Messages: %d\n\n", input.TotalMessages); err != nil {
+	if input.Partial != nil {
+		if _, err := fmt.Fprintf(w, "Partial Export: messages %d-%d (%s to %s), fetch was canceled\n",
+			input.Partial.FirstID, input.Partial.LastID,
+			input.Partial.From.Format(time.RFC1123), input.Partial.To.Format(time.RFC1123)); err != nil {
+			return fmt.Errorf("failed to write partial marker: %w", err)
+		}
+	}
+	if err := writeTextChatInfo(w, input.Chat); err != nil {
+		return fmt.Errorf("failed to write chat info: %w", err)
+	}
+	return nil
+}
+
+// writeTextChatInfo writes the chat metadata lines that are set, followed
+// by the participant directory.
+func writeTextChatInfo(w io.Writer, info *TemplateChatInfo) error {
+	if info == nil {
+		return nil
+	}
+	var lines []string
+	if info.Description != "" {
+		lines = append(lines, "Description: "+oneLine(info.Description))
+	}
+	if info.MemberCount > 0 {
+		lines = append(lines, fmt.Sprintf("Members: %d", info.MemberCount))
+	}
+	if info.LinkedChatID != 0 {
+		lines = append(lines, fmt.Sprintf("Linked Chat: %s (id=%d)", info.LinkedChatTitle, info.LinkedChatID))
+	}
+	if info.SlowModeSeconds > 0 {
+		lines = append(lines, fmt.Sprintf("Slow Mode: %s", time.Duration(info.SlowModeSeconds)*time.Second))
+	}
+	if !info.Created.IsZero() {
+		lines = append(lines, "Created: "+info.Created.Format("2006-01-02"))
+	}
+	if len(info.Participants) > 0 {
+		lines = append(lines, fmt.Sprintf("Participants (%d):", len(info.Participants)))
+		for _, p := range info.Participants {
+			lines = append(lines, fmt.Sprintf("  %s: %s", formatSenderID(p.ID), p.summary()))
+		}
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textMessageWriter keeps only the current sender block in memory and
+// writes it out once the sender changes.
+type textMessageWriter struct {
+	w       io.Writer
+	details textDetails
+	block   *messageBlock
+	group   string
+	total   int
+}
+
+func (t *textMessageWriter) WriteMessage(msg TemplateMessage) error {
+	if msg.Group != t.group && msg.Group != "" {
+		if err := t.flush(); err != nil {
+			return err
+		}
+		t.block = &messageBlock{Heading: msg.Group}
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+	t.group = msg.Group
+	if msg.Event != "" {
+		if err := t.flush(); err != nil {
+			return err
+		}
+		block := eventBlock(msg)
+		t.block = &block
+		return t.flush()
+	}
+	t.total++
+	lines := messageLines(msg, t.details)
+	if len(lines) == 0 {
+		return nil
+	}
+	if t.block != nil && t.block.SenderID == msg.SenderID {
+		t.block.End = msg.Date
+		t.block.Lines = append(t.block.Lines, lines...)
+		return nil
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+	t.block = &messageBlock{SenderID: msg.SenderID, SenderName: msg.SenderName, Start: msg.Date, End: msg.Date, Lines: lines}
+	return nil
+}
+
+func (t *textMessageWriter) Close() error {
+	if err := t.flush(); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(t.w, "\nTotal Messages: %d\n", t.total); err != nil {
 		return fmt.Errorf("failed to write count: %w", err)
 	}
+	return nil
+}
 
-	blocks := buildMessageBlocks(input.Messages)
-	if err := writeMessageBlocks(w, blocks); err != nil {
+func (t *textMessageWriter) flush() error {
+	if t.block == nil {
+		return nil
+	}
+	block := *t.block
+	t.block = nil
+	if err := writeMessageBlocks(t.w, []messageBlock{block}); err != nil {
 		return fmt.Errorf("failed to write message blocks: %w", err)
 	}
 	return nil
@@ -4,8 +4,11 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
+
+	"cli-tg-chat-summary/internal/telegram"
 )
 
 type xmlTemplate struct{}
@@ -23,10 +26,71 @@ func (t xmlTemplate) Extension() string {
 }
 
 func (t xmlTemplate) Render(w io.Writer, input TemplateInput) error {
+	doc := newXMLChat(input)
+	doc.TotalMessages = input.TotalMessages
+	for _, msg := range input.Messages {
+		if xmlMsg, ok := newXMLMessage(msg); ok {
+			doc.Messages = append(doc.Messages, xmlMsg)
+		}
+	}
+
+	exporter := xml.NewEncoder(w)
+	exporter.Indent("", "  ")
+	if err := exporter.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write xml: %w", err)
+	}
+	if err := exporter.Flush(); err != nil {
+		return fmt.Errorf("failed to flush xml: %w", err)
+	}
+	return nil
+}
+
+func (t xmlTemplate) Stream(w io.Writer, input TemplateInput) (MessageWriter, error) {
+	doc := newXMLChat(input)
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	root := xml.StartElement{
+		Name: xml.Name{Local: "chat"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "title"}, Value: doc.Title}},
+	}
+	stream := &xmlMessageWriter{
+		encoder:    encoder,
+		root:       root,
+		messageTag: "message",
+		totalTag:   "total_messages",
+		message: func(msg TemplateMessage) (any, bool) {
+			return newXMLMessage(msg)
+		},
+	}
+	header := []xmlElement{{"export_date", doc.ExportDate}}
+	if doc.Since != nil {
+		header = append(header, xmlElement{"since", *doc.Since}, xmlElement{"until", *doc.Until})
+	}
+	if doc.Info != nil {
+		header = append(header, xmlElement{"info", doc.Info})
+	}
+	if doc.Partial != nil {
+		header = append(header, xmlElement{"partial", doc.Partial})
+	}
+	if doc.Pinned != nil {
+		header = append(header, xmlElement{"pinned", doc.Pinned})
+	}
+	if doc.Attention != nil {
+		header = append(header, xmlElement{"attention", doc.Attention})
+	}
+	if doc.Context != nil {
+		header = append(header, xmlElement{"context", doc.Context})
+	}
+	if err := stream.begin(header); err != nil {
+		return nil, fmt.Errorf("failed to write xml: %w", err)
+	}
+	return stream, nil
+}
+
+func newXMLChat(input TemplateInput) xmlChat {
 	doc := xmlChat{
-		Title:         input.ExportTitle,
-		ExportDate:    input.ExportDate.Format(time.RFC3339),
-		TotalMessages: input.TotalMessages,
+		Title:      input.ExportTitle,
+		ExportDate: input.ExportDate.Format(time.RFC3339),
 	}
 	if input.Options.UseDateRange {
 		since := input.Options.Since.Format(time.RFC3339)
@@ -34,71 +98,408 @@ func (t xmlTemplate) Render(w io.Writer, input TemplateInput) error {
 		doc.Since = &since
 		doc.Until = &until
 	}
+	doc.Info = newXMLChatInfo(input.Chat)
+	if input.Partial != nil {
+		doc.Partial = &xmlPartial{
+			FirstID: input.Partial.FirstID,
+			LastID:  input.Partial.LastID,
+			From:    input.Partial.From.Format(time.RFC3339),
+			To:      input.Partial.To.Format(time.RFC3339),
+		}
+	}
+	if len(input.Pinned) > 0 {
+		doc.Pinned = &xmlPinned{}
+		for _, msg := range input.Pinned {
+			if xmlMsg, ok := newXMLMessage(msg); ok {
+				doc.Pinned.Messages = append(doc.Pinned.Messages, xmlMsg)
+			}
+		}
+	}
+	if len(input.TopPosts) > 0 {
+		doc.TopPosts = &xmlTopPosts{}
+		for i, msg := range input.TopPosts {
+			doc.TopPosts.Posts = append(doc.TopPosts.Posts, xmlTopPost{
+				Rank:      i + 1,
+				MessageID: msg.ID,
+				Time:      msg.Date.Format(time.RFC3339),
+				Views:     msg.Views,
+				Forwards:  msg.Forwards,
+				Reactions: msg.reactionCount(),
+				Comments:  msg.Replies,
+				Text:      firstLine(msg),
+			})
+		}
+	}
+	if len(input.Attention) > 0 {
+		doc.Attention = &xmlAttention{}
+		for _, msg := range input.Attention {
+			doc.Attention.Items = append(doc.Attention.Items, xmlAttentionItem{
+				MessageID: msg.ID,
+				SenderID:  msg.SenderID,
+				Time:      msg.Date.Format(time.RFC3339),
+				Reason:    msg.attentionReason(),
+				Text:      normalizeLines(msg.Text)[0],
+			})
+		}
+	}
+	if len(input.Context) > 0 {
+		doc.Context = &xmlContext{}
+		for _, msg := range input.Context {
+			if xmlMsg, ok := newXMLMessage(msg); ok {
+				doc.Context.Messages = append(doc.Context.Messages, xmlMsg)
+			}
+		}
+	}
+	return doc
+}
 
-	for _, msg := range input.Messages {
-		lines := normalizeLines(msg.Text)
-		if len(lines) == 0 {
-			continue
+func newXMLChatInfo(info *TemplateChatInfo) *xmlChatInfo {
+	if info == nil {
+		return nil
+	}
+	result := &xmlChatInfo{
+		Members:         info.MemberCount,
+		SlowModeSeconds: info.SlowModeSeconds,
+		Description:     info.Description,
+	}
+	if !info.Created.IsZero() {
+		result.Created = info.Created.Format(time.RFC3339)
+	}
+	if info.LinkedChatID != 0 {
+		result.LinkedChat = &xmlLinkedChat{ID: info.LinkedChatID, Title: info.LinkedChatTitle}
+	}
+	if len(info.Participants) > 0 {
+		result.Participants = &xmlParticipants{}
+		for _, p := range info.Participants {
+			result.Participants.Items = append(result.Participants.Items, xmlParticipant{
+				ID:         p.ID,
+				Name:       p.Name,
+				Username:   p.Username,
+				Bot:        p.Bot,
+				AdminTitle: p.AdminTitle,
+			})
 		}
-		text := strings.Join(lines, "\n")
-		xmlMsg := xmlMessage{
+	}
+	return result
+}
+
+func newXMLMessage(msg TemplateMessage) (xmlMessage, bool) {
+	lines := normalizeLines(msg.Text)
+	if len(lines) == 0 && msg.Poll == nil && msg.Media == nil {
+		return xmlMessage{}, false
+	}
+	xmlMsg := xmlMessage{
+		Sender: xmlSender{
+			ID:   msg.SenderID,
+			Name: msg.SenderName,
+		},
+		Time:      msg.Date.Format(time.RFC3339),
+		Text:      strings.Join(lines, "\n"),
+		Event:     msg.Event,
+		Action:    msg.AdminAction,
+		Group:     msg.Group,
+		Tags:      strings.Join(msg.Tags, ", "),
+		Chat:      msg.Chat,
+		Link:      msg.Link,
+		Mentioned: msg.Mentioned,
+		ReplyToMe: msg.ReplyToMe,
+		Views:     msg.Views,
+		Forwards:  msg.Forwards,
+		Comments:  msg.Replies,
+	}
+
+	if msg.ReplyTo != nil {
+		xmlMsg.Reply = &xmlReply{
+			MessageID: msg.ReplyTo.MessageID,
 			Sender: xmlSender{
-				ID:   msg.SenderID,
-				Name: msg.SenderName,
+				ID:   msg.ReplyTo.SenderID,
+				Name: msg.ReplyTo.SenderName,
 			},
-			Time: msg.Date.Format(time.RFC3339),
-			Text: text,
+			Text: msg.ReplyTo.Text,
 		}
-
-		if msg.ReplyTo != nil {
-			xmlMsg.Reply = &xmlReply{
-				MessageID: msg.ReplyTo.MessageID,
-				Sender: xmlSender{
-					ID:   msg.ReplyTo.SenderID,
-					Name: msg.ReplyTo.SenderName,
-				},
-				Text: msg.ReplyTo.Text,
-			}
+	}
+	if msg.Forward != nil {
+		xmlMsg.Forward = &xmlForward{
+			FromID:     msg.Forward.FromID,
+			FromName:   msg.Forward.FromName,
+			Date:       msg.Forward.Date.Format(time.RFC3339),
+			PostID:     msg.Forward.PostID,
+			PostAuthor: msg.Forward.PostAuthor,
 		}
-		if len(msg.Reactions) > 0 {
-			reactions := make([]xmlReaction, 0, len(msg.Reactions))
-			for _, reaction := range msg.Reactions {
-				reactions = append(reactions, xmlReaction(reaction))
-			}
-			xmlMsg.Reactions = &xmlReactions{Items: reactions}
+	}
+	if msg.Poll != nil {
+		xmlMsg.Poll = newXMLPoll(*msg.Poll)
+	}
+	if msg.Media != nil {
+		xmlMsg.Media = newXMLMedia(*msg.Media)
+	}
+	if len(msg.Buttons) > 0 {
+		xmlMsg.Buttons = &xmlButtons{}
+		for _, button := range msg.Buttons {
+			xmlMsg.Buttons.Items = append(xmlMsg.Buttons.Items, xmlButton(button))
 		}
-		doc.Messages = append(doc.Messages, xmlMsg)
 	}
+	if len(msg.Reactions) > 0 {
+		reactions := make([]xmlReaction, 0, len(msg.Reactions))
+		for _, reaction := range msg.Reactions {
+			reactions = append(reactions, xmlReaction(reaction))
+		}
+		xmlMsg.Reactions = &xmlReactions{Items: reactions}
+	}
+	return xmlMsg, true
+}
 
-	exporter := xml.NewEncoder(w)
-	exporter.Indent("", "  ")
-	if err := exporter.Encode(doc); err != nil {
-		return fmt.Errorf("failed to write xml: %w", err)
+func newXMLPoll(poll TemplatePoll) *xmlPoll {
+	xmlPoll := &xmlPoll{
+		Question:    poll.Question,
+		TotalVoters: poll.TotalVoters,
+		Quiz:        poll.Quiz,
+		Closed:      poll.Closed,
 	}
-	if err := exporter.Flush(); err != nil {
+	for _, option := range poll.Options {
+		xmlPoll.Options = append(xmlPoll.Options, xmlPollOption(option))
+	}
+	return xmlPoll
+}
+
+func newXMLMedia(media TemplateMedia) *xmlMedia {
+	xmlMedia := &xmlMedia{
+		Kind:        media.Kind,
+		URL:         media.URL,
+		SiteName:    media.SiteName,
+		Title:       media.Title,
+		Address:     media.Address,
+		Phone:       media.Phone,
+		Emoji:       media.Emoji,
+		Description: oneLine(media.Description),
+	}
+	switch media.Kind {
+	case telegram.MediaLocation, telegram.MediaVenue:
+		xmlMedia.Coordinates = formatCoordinates(&media)
+	case telegram.MediaDice:
+		xmlMedia.Value = strconv.Itoa(media.Value)
+	case telegram.MediaInvoice:
+		xmlMedia.Amount = formatAmount(media.Amount, media.Currency)
+	}
+	return xmlMedia
+}
+
+type xmlElement struct {
+	tag   string
+	value any
+}
+
+// xmlMessageWriter streams one element per message inside an open root
+// element and writes the total count just before closing it.
+type xmlMessageWriter struct {
+	encoder    *xml.Encoder
+	root       xml.StartElement
+	messageTag string
+	totalTag   string
+	message    func(TemplateMessage) (any, bool)
+	total      int
+}
+
+func (x *xmlMessageWriter) begin(header []xmlElement) error {
+	if err := x.encoder.EncodeToken(x.root); err != nil {
+		return err
+	}
+	for _, el := range header {
+		if err := x.encoder.EncodeElement(el.value, xml.StartElement{Name: xml.Name{Local: el.tag}}); err != nil {
+			return err
+		}
+	}
+	return x.encoder.Flush()
+}
+
+func (x *xmlMessageWriter) WriteMessage(msg TemplateMessage) error {
+	if msg.Event == "" {
+		x.total++
+	}
+	value, ok := x.message(msg)
+	if !ok {
+		return nil
+	}
+	if err := x.encoder.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: x.messageTag}}); err != nil {
+		return fmt.Errorf("failed to write xml message: %w", err)
+	}
+	return nil
+}
+
+func (x *xmlMessageWriter) Close() error {
+	if err := x.encoder.EncodeElement(x.total, xml.StartElement{Name: xml.Name{Local: x.totalTag}}); err != nil {
+		return fmt.Errorf("failed to write xml total: %w", err)
+	}
+	if err := x.encoder.EncodeToken(x.root.End()); err != nil {
+		return fmt.Errorf("failed to close xml: %w", err)
+	}
+	if err := x.encoder.Flush(); err != nil {
 		return fmt.Errorf("failed to flush xml: %w", err)
 	}
 	return nil
 }
 
 type xmlChat struct {
-	XMLName       xml.Name     `xml:"chat"`
-	Title         string       `xml:"title,attr"`
-	ExportDate    string       `xml:"export_date"`
-	TotalMessages int          `xml:"total_messages"`
-	Since         *string      `xml:"since,omitempty"`
-	Until         *string      `xml:"until,omitempty"`
-	Messages      []xmlMessage `xml:"message"`
+	XMLName       xml.Name      `xml:"chat"`
+	Title         string        `xml:"title,attr"`
+	ExportDate    string        `xml:"export_date"`
+	TotalMessages int           `xml:"total_messages"`
+	Since         *string       `xml:"since,omitempty"`
+	Until         *string       `xml:"until,omitempty"`
+	Info          *xmlChatInfo  `xml:"info,omitempty"`
+	Partial       *xmlPartial   `xml:"partial,omitempty"`
+	Pinned        *xmlPinned    `xml:"pinned,omitempty"`
+	TopPosts      *xmlTopPosts  `xml:"top_posts,omitempty"`
+	Attention     *xmlAttention `xml:"attention,omitempty"`
+	Context       *xmlContext   `xml:"context,omitempty"`
+	Messages      []xmlMessage  `xml:"message"`
+}
+
+// xmlChatInfo holds the chat metadata and the participant directory.
+type xmlChatInfo struct {
+	Members         int              `xml:"members,attr,omitempty"`
+	SlowModeSeconds int              `xml:"slow_mode_seconds,attr,omitempty"`
+	Created         string           `xml:"created,attr,omitempty"`
+	Description     string           `xml:"description,omitempty"`
+	LinkedChat      *xmlLinkedChat   `xml:"linked_chat,omitempty"`
+	Participants    *xmlParticipants `xml:"participants,omitempty"`
+}
+
+type xmlLinkedChat struct {
+	ID    int64  `xml:"id,attr"`
+	Title string `xml:",chardata"`
+}
+
+type xmlParticipants struct {
+	Items []xmlParticipant `xml:"participant"`
+}
+
+type xmlParticipant struct {
+	ID         int64  `xml:"id,attr"`
+	Name       string `xml:"name,attr,omitempty"`
+	Username   string `xml:"username,attr,omitempty"`
+	Bot        bool   `xml:"bot,attr,omitempty"`
+	AdminTitle string `xml:"admin_title,attr,omitempty"`
+}
+
+// xmlPinned holds the chat's pinned messages.
+type xmlPinned struct {
+	Messages []xmlMessage `xml:"message"`
+}
+
+// xmlTopPosts ranks the most engaging messages of the export.
+type xmlTopPosts struct {
+	Posts []xmlTopPost `xml:"post"`
+}
+
+type xmlTopPost struct {
+	Rank      int    `xml:"rank,attr"`
+	MessageID int    `xml:"message_id,attr"`
+	Time      string `xml:"time,attr"`
+	Views     int    `xml:"views,attr,omitempty"`
+	Forwards  int    `xml:"forwards,attr,omitempty"`
+	Reactions int    `xml:"reactions,attr,omitempty"`
+	Comments  int    `xml:"comments,attr,omitempty"`
+	Text      string `xml:",chardata"`
+}
+
+// xmlAttention lists messages that mention the user or reply to them.
+type xmlAttention struct {
+	Items []xmlAttentionItem `xml:"item"`
+}
+
+type xmlAttentionItem struct {
+	MessageID int    `xml:"message_id,attr"`
+	SenderID  int64  `xml:"sender_id,attr"`
+	Time      string `xml:"time,attr"`
+	Reason    string `xml:"reason,attr"`
+	Text      string `xml:",chardata"`
+}
+
+// xmlContext holds already read messages shown before the unread ones.
+type xmlContext struct {
+	Messages []xmlMessage `xml:"message"`
+}
+
+type xmlPartial struct {
+	FirstID int    `xml:"first_id,attr"`
+	LastID  int    `xml:"last_id,attr"`
+	From    string `xml:"from,attr"`
+	To      string `xml:"to,attr"`
 }
 
 type xmlMessage struct {
+	Event     string        `xml:"event,attr,omitempty"`
+	Action    string        `xml:"action,attr,omitempty"`
+	Group     string        `xml:"group,attr,omitempty"`
+	Tags      string        `xml:"tags,attr,omitempty"`
+	Chat      string        `xml:"chat,attr,omitempty"`
+	Link      string        `xml:"link,attr,omitempty"`
+	Mentioned bool          `xml:"mentioned,attr,omitempty"`
+	ReplyToMe bool          `xml:"reply_to_me,attr,omitempty"`
+	Views     int           `xml:"views,attr,omitempty"`
+	Forwards  int           `xml:"forwards,attr,omitempty"`
+	Comments  int           `xml:"comments,attr,omitempty"`
 	Sender    xmlSender     `xml:"sender"`
 	Time      string        `xml:"time"`
-	Text      string        `xml:"text"`
+	Text      string        `xml:"text,omitempty"`
+	Forward   *xmlForward   `xml:"forward,omitempty"`
+	Poll      *xmlPoll      `xml:"poll,omitempty"`
+	Media     *xmlMedia     `xml:"media,omitempty"`
+	Buttons   *xmlButtons   `xml:"buttons,omitempty"`
 	Reply     *xmlReply     `xml:"reply,omitempty"`
 	Reactions *xmlReactions `xml:"reactions,omitempty"`
 }
 
+// xmlForward describes the origin of a forwarded message.
+type xmlForward struct {
+	FromID     int64  `xml:"from_id,attr,omitempty"`
+	FromName   string `xml:"from_name,attr,omitempty"`
+	Date       string `xml:"date,attr"`
+	PostID     int    `xml:"post_id,attr,omitempty"`
+	PostAuthor string `xml:"post_author,attr,omitempty"`
+}
+
+// xmlMedia describes a link preview, location or other attachment. Only
+// the attributes that apply to its kind are written.
+type xmlMedia struct {
+	Kind        string `xml:"kind,attr"`
+	URL         string `xml:"url,attr,omitempty"`
+	SiteName    string `xml:"site,attr,omitempty"`
+	Title       string `xml:"title,attr,omitempty"`
+	Address     string `xml:"address,attr,omitempty"`
+	Coordinates string `xml:"coordinates,attr,omitempty"`
+	Phone       string `xml:"phone,attr,omitempty"`
+	Emoji       string `xml:"emoji,attr,omitempty"`
+	Value       string `xml:"value,attr,omitempty"`
+	Amount      string `xml:"amount,attr,omitempty"`
+	Description string `xml:",chardata"`
+}
+
+type xmlButtons struct {
+	Items []xmlButton `xml:"button"`
+}
+
+type xmlButton struct {
+	Kind  string `xml:"kind,attr"`
+	Text  string `xml:",chardata"`
+	Value string `xml:"value,attr,omitempty"`
+}
+
+type xmlPoll struct {
+	TotalVoters int             `xml:"total_voters,attr"`
+	Quiz        bool            `xml:"quiz,attr,omitempty"`
+	Closed      bool            `xml:"closed,attr,omitempty"`
+	Question    string          `xml:"question"`
+	Options     []xmlPollOption `xml:"option"`
+}
+
+type xmlPollOption struct {
+	Text    string `xml:",chardata"`
+	Voters  int    `xml:"voters,attr"`
+	Correct bool   `xml:"correct,attr,omitempty"`
+}
+
 type xmlSender struct {
 	ID   int64  `xml:"id,attr"`
 	Name string `xml:"name,omitempty"`
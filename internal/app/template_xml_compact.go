@@ -23,10 +23,76 @@ func (t xmlCompactTemplate) Extension() string {
 }
 
 func (t xmlCompactTemplate) Render(w io.Writer, input TemplateInput) error {
+	doc := newXMLCompactChat(input)
+	doc.TotalMessages = input.TotalMessages
+	for _, msg := range input.Messages {
+		if xmlMsg, ok := newXMLCompactMessage(msg); ok {
+			doc.Messages = append(doc.Messages, xmlMsg)
+		}
+	}
+
+	exporter := xml.NewEncoder(w)
+	if err := exporter.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write xml compact: %w", err)
+	}
+	if err := exporter.Flush(); err != nil {
+		return fmt.Errorf("failed to flush xml compact: %w", err)
+	}
+	return nil
+}
+
+// Stream writes the compact root without the n attribute, since the count
+// is unknown up front; it is written as a trailing n element instead.
+func (t xmlCompactTemplate) Stream(w io.Writer, input TemplateInput) (MessageWriter, error) {
+	doc := newXMLCompactChat(input)
+	root := xml.StartElement{
+		Name: xml.Name{Local: "c"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "t"}, Value: doc.Title},
+			{Name: xml.Name{Local: "d"}, Value: doc.ExportDate},
+		},
+	}
+	if doc.Since != nil {
+		root.Attr = append(root.Attr,
+			xml.Attr{Name: xml.Name{Local: "s"}, Value: *doc.Since},
+			xml.Attr{Name: xml.Name{Local: "u"}, Value: *doc.Until},
+		)
+	}
+	stream := &xmlMessageWriter{
+		encoder:    xml.NewEncoder(w),
+		root:       root,
+		messageTag: "m",
+		totalTag:   "n",
+		message: func(msg TemplateMessage) (any, bool) {
+			return newXMLCompactMessage(msg)
+		},
+	}
+	var header []xmlElement
+	if doc.Info != nil {
+		header = append(header, xmlElement{"ci", doc.Info})
+	}
+	if doc.Partial != nil {
+		header = append(header, xmlElement{"p", doc.Partial})
+	}
+	if doc.Pinned != nil {
+		header = append(header, xmlElement{"pn", doc.Pinned})
+	}
+	if doc.Attention != nil {
+		header = append(header, xmlElement{"at", doc.Attention})
+	}
+	if doc.Context != nil {
+		header = append(header, xmlElement{"cx", doc.Context})
+	}
+	if err := stream.begin(header); err != nil {
+		return nil, fmt.Errorf("failed to write xml compact: %w", err)
+	}
+	return stream, nil
+}
+
+func newXMLCompactChat(input TemplateInput) xmlCompactChat {
 	doc := xmlCompactChat{
-		Title:         input.ExportTitle,
-		ExportDate:    input.ExportDate.Format(time.RFC3339),
-		TotalMessages: input.TotalMessages,
+		Title:      input.ExportTitle,
+		ExportDate: input.ExportDate.Format(time.RFC3339),
 	}
 	if input.Options.UseDateRange {
 		since := input.Options.Since.Format(time.RFC3339)
@@ -34,67 +100,313 @@ func (t xmlCompactTemplate) Render(w io.Writer, input TemplateInput) error {
 		doc.Since = &since
 		doc.Until = &until
 	}
-
-	for _, msg := range input.Messages {
-		lines := normalizeLines(msg.Text)
-		if len(lines) == 0 {
-			continue
-		}
-		text := strings.Join(lines, "\n")
-		xmlMsg := xmlCompactMessage{
-			SenderID:   msg.SenderID,
-			SenderName: msg.SenderName,
-			Time:       msg.Date.Format(time.RFC3339),
-			Text:       text,
+	doc.Info = newXMLCompactChatInfo(input.Chat)
+	if input.Partial != nil {
+		doc.Partial = &xmlCompactPartial{
+			FirstID: input.Partial.FirstID,
+			LastID:  input.Partial.LastID,
+			From:    input.Partial.From.Format(time.RFC3339),
+			To:      input.Partial.To.Format(time.RFC3339),
 		}
-
-		if msg.ReplyTo != nil {
-			xmlMsg.Reply = &xmlCompactReply{
-				MessageID: msg.ReplyTo.MessageID,
-				SenderID:  msg.ReplyTo.SenderID,
-				Name:      msg.ReplyTo.SenderName,
-				Text:      msg.ReplyTo.Text,
+	}
+	if len(input.Pinned) > 0 {
+		doc.Pinned = &xmlCompactPinned{}
+		for _, msg := range input.Pinned {
+			if xmlMsg, ok := newXMLCompactMessage(msg); ok {
+				doc.Pinned.Messages = append(doc.Pinned.Messages, xmlMsg)
 			}
 		}
-		if len(msg.Reactions) > 0 {
-			reactions := make([]xmlCompactReaction, 0, len(msg.Reactions))
-			for _, reaction := range msg.Reactions {
-				reactions = append(reactions, xmlCompactReaction(reaction))
+	}
+	if len(input.TopPosts) > 0 {
+		doc.TopPosts = &xmlCompactTopPosts{}
+		for i, msg := range input.TopPosts {
+			doc.TopPosts.Posts = append(doc.TopPosts.Posts, xmlCompactTopPost{
+				Rank:      i + 1,
+				MessageID: msg.ID,
+				Time:      msg.Date.Format(time.RFC3339),
+				Views:     msg.Views,
+				Forwards:  msg.Forwards,
+				Reactions: msg.reactionCount(),
+				Comments:  msg.Replies,
+				Text:      firstLine(msg),
+			})
+		}
+	}
+	if len(input.Attention) > 0 {
+		doc.Attention = &xmlCompactAttention{}
+		for _, msg := range input.Attention {
+			doc.Attention.Items = append(doc.Attention.Items, xmlCompactAttentionItem{
+				MessageID: msg.ID,
+				SenderID:  msg.SenderID,
+				Time:      msg.Date.Format(time.RFC3339),
+				Reason:    msg.attentionReason(),
+				Text:      normalizeLines(msg.Text)[0],
+			})
+		}
+	}
+	if len(input.Context) > 0 {
+		doc.Context = &xmlCompactContext{}
+		for _, msg := range input.Context {
+			if xmlMsg, ok := newXMLCompactMessage(msg); ok {
+				doc.Context.Messages = append(doc.Context.Messages, xmlMsg)
 			}
-			xmlMsg.Reactions = &xmlCompactReactions{Items: reactions}
 		}
-		doc.Messages = append(doc.Messages, xmlMsg)
 	}
+	return doc
+}
 
-	exporter := xml.NewEncoder(w)
-	if err := exporter.Encode(doc); err != nil {
-		return fmt.Errorf("failed to write xml compact: %w", err)
+func newXMLCompactChatInfo(info *TemplateChatInfo) *xmlCompactChatInfo {
+	if info == nil {
+		return nil
 	}
-	if err := exporter.Flush(); err != nil {
-		return fmt.Errorf("failed to flush xml compact: %w", err)
+	result := &xmlCompactChatInfo{
+		Members:         info.MemberCount,
+		SlowModeSeconds: info.SlowModeSeconds,
+		Description:     info.Description,
 	}
-	return nil
+	if !info.Created.IsZero() {
+		result.Created = info.Created.Format(time.RFC3339)
+	}
+	if info.LinkedChatID != 0 {
+		result.LinkedChat = &xmlCompactLinkedChat{ID: info.LinkedChatID, Title: info.LinkedChatTitle}
+	}
+	if len(info.Participants) > 0 {
+		result.Participants = &xmlCompactParticipants{}
+		for _, p := range info.Participants {
+			result.Participants.Items = append(result.Participants.Items, xmlCompactParticipant{
+				ID:         p.ID,
+				Name:       p.Name,
+				Username:   p.Username,
+				Bot:        p.Bot,
+				AdminTitle: p.AdminTitle,
+			})
+		}
+	}
+	return result
+}
+
+func newXMLCompactMessage(msg TemplateMessage) (xmlCompactMessage, bool) {
+	lines := normalizeLines(msg.Text)
+	if len(lines) == 0 && msg.Poll == nil && msg.Media == nil {
+		return xmlCompactMessage{}, false
+	}
+	xmlMsg := xmlCompactMessage{
+		SenderID:   msg.SenderID,
+		SenderName: msg.SenderName,
+		Time:       msg.Date.Format(time.RFC3339),
+		Text:       strings.Join(lines, "\n"),
+		Event:      msg.Event,
+		Action:     msg.AdminAction,
+		Group:      msg.Group,
+		Tags:       strings.Join(msg.Tags, ", "),
+		Chat:       msg.Chat,
+		Link:       msg.Link,
+		Mentioned:  msg.Mentioned,
+		ReplyToMe:  msg.ReplyToMe,
+		Views:      msg.Views,
+		Forwards:   msg.Forwards,
+		Comments:   msg.Replies,
+	}
+
+	if msg.ReplyTo != nil {
+		xmlMsg.Reply = &xmlCompactReply{
+			MessageID: msg.ReplyTo.MessageID,
+			SenderID:  msg.ReplyTo.SenderID,
+			Name:      msg.ReplyTo.SenderName,
+			Text:      msg.ReplyTo.Text,
+		}
+	}
+	if msg.Forward != nil {
+		xmlMsg.Forward = &xmlCompactForward{
+			FromID:     msg.Forward.FromID,
+			FromName:   msg.Forward.FromName,
+			Date:       msg.Forward.Date.Format(time.RFC3339),
+			PostID:     msg.Forward.PostID,
+			PostAuthor: msg.Forward.PostAuthor,
+		}
+	}
+	if msg.Poll != nil {
+		xmlMsg.Poll = newXMLCompactPoll(*msg.Poll)
+	}
+	if msg.Media != nil {
+		xmlMsg.Media = &xmlCompactMedia{Kind: msg.Media.Kind, Text: mediaSummary(msg.Media)}
+	}
+	if len(msg.Buttons) > 0 {
+		xmlMsg.Buttons = &xmlCompactButtons{}
+		for _, button := range msg.Buttons {
+			xmlMsg.Buttons.Items = append(xmlMsg.Buttons.Items, xmlCompactButton(button))
+		}
+	}
+	if len(msg.Reactions) > 0 {
+		reactions := make([]xmlCompactReaction, 0, len(msg.Reactions))
+		for _, reaction := range msg.Reactions {
+			reactions = append(reactions, xmlCompactReaction(reaction))
+		}
+		xmlMsg.Reactions = &xmlCompactReactions{Items: reactions}
+	}
+	return xmlMsg, true
+}
+
+func newXMLCompactPoll(poll TemplatePoll) *xmlCompactPoll {
+	xmlPoll := &xmlCompactPoll{
+		Question:    poll.Question,
+		TotalVoters: poll.TotalVoters,
+		Quiz:        poll.Quiz,
+		Closed:      poll.Closed,
+	}
+	for _, option := range poll.Options {
+		xmlPoll.Options = append(xmlPoll.Options, xmlCompactPollOption(option))
+	}
+	return xmlPoll
 }
 
 type xmlCompactChat struct {
-	XMLName       xml.Name            `xml:"c"`
-	Title         string              `xml:"t,attr"`
-	ExportDate    string              `xml:"d,attr"`
-	TotalMessages int                 `xml:"n,attr"`
-	Since         *string             `xml:"s,attr,omitempty"`
-	Until         *string             `xml:"u,attr,omitempty"`
-	Messages      []xmlCompactMessage `xml:"m"`
+	XMLName       xml.Name             `xml:"c"`
+	Title         string               `xml:"t,attr"`
+	ExportDate    string               `xml:"d,attr"`
+	TotalMessages int                  `xml:"n,attr"`
+	Since         *string              `xml:"s,attr,omitempty"`
+	Until         *string              `xml:"u,attr,omitempty"`
+	Info          *xmlCompactChatInfo  `xml:"ci,omitempty"`
+	Partial       *xmlCompactPartial   `xml:"p,omitempty"`
+	Pinned        *xmlCompactPinned    `xml:"pn,omitempty"`
+	TopPosts      *xmlCompactTopPosts  `xml:"tp,omitempty"`
+	Attention     *xmlCompactAttention `xml:"at,omitempty"`
+	Context       *xmlCompactContext   `xml:"cx,omitempty"`
+	Messages      []xmlCompactMessage  `xml:"m"`
+}
+
+type xmlCompactChatInfo struct {
+	Members         int                     `xml:"m,attr,omitempty"`
+	SlowModeSeconds int                     `xml:"sl,attr,omitempty"`
+	Created         string                  `xml:"cr,attr,omitempty"`
+	Description     string                  `xml:"ds,omitempty"`
+	LinkedChat      *xmlCompactLinkedChat   `xml:"lc,omitempty"`
+	Participants    *xmlCompactParticipants `xml:"ps,omitempty"`
+}
+
+type xmlCompactLinkedChat struct {
+	ID    int64  `xml:"i,attr"`
+	Title string `xml:",chardata"`
+}
+
+type xmlCompactParticipants struct {
+	Items []xmlCompactParticipant `xml:"u"`
+}
+
+type xmlCompactParticipant struct {
+	ID         int64  `xml:"i,attr"`
+	Name       string `xml:"n,attr,omitempty"`
+	Username   string `xml:"un,attr,omitempty"`
+	Bot        bool   `xml:"b,attr,omitempty"`
+	AdminTitle string `xml:"a,attr,omitempty"`
+}
+
+type xmlCompactPinned struct {
+	Messages []xmlCompactMessage `xml:"m"`
+}
+
+type xmlCompactTopPosts struct {
+	Posts []xmlCompactTopPost `xml:"p"`
+}
+
+type xmlCompactTopPost struct {
+	Rank      int    `xml:"k,attr"`
+	MessageID int    `xml:"i,attr"`
+	Time      string `xml:"t,attr"`
+	Views     int    `xml:"vw,attr,omitempty"`
+	Forwards  int    `xml:"fw,attr,omitempty"`
+	Reactions int    `xml:"rc,attr,omitempty"`
+	Comments  int    `xml:"cm,attr,omitempty"`
+	Text      string `xml:",chardata"`
+}
+
+type xmlCompactAttention struct {
+	Items []xmlCompactAttentionItem `xml:"a"`
+}
+
+type xmlCompactAttentionItem struct {
+	MessageID int    `xml:"i,attr"`
+	SenderID  int64  `xml:"s,attr"`
+	Time      string `xml:"t,attr"`
+	Reason    string `xml:"r,attr"`
+	Text      string `xml:",chardata"`
+}
+
+type xmlCompactContext struct {
+	Messages []xmlCompactMessage `xml:"m"`
+}
+
+type xmlCompactPartial struct {
+	FirstID int    `xml:"f,attr"`
+	LastID  int    `xml:"l,attr"`
+	From    string `xml:"s,attr"`
+	To      string `xml:"u,attr"`
 }
 
 type xmlCompactMessage struct {
 	Time       string               `xml:"t,attr"`
 	SenderID   int64                `xml:"s,attr"`
+	Event      string               `xml:"ev,attr,omitempty"`
+	Action     string               `xml:"ac,attr,omitempty"`
+	Group      string               `xml:"g,attr,omitempty"`
+	Tags       string               `xml:"tg,attr,omitempty"`
+	Chat       string               `xml:"ch,attr,omitempty"`
+	Link       string               `xml:"lk,attr,omitempty"`
 	SenderName string               `xml:"n,attr,omitempty"`
+	Mentioned  bool                 `xml:"me,attr,omitempty"`
+	ReplyToMe  bool                 `xml:"rm,attr,omitempty"`
+	Views      int                  `xml:"vw,attr,omitempty"`
+	Forwards   int                  `xml:"fw,attr,omitempty"`
+	Comments   int                  `xml:"cm,attr,omitempty"`
 	Text       string               `xml:",chardata"`
+	Forward    *xmlCompactForward   `xml:"fo,omitempty"`
+	Poll       *xmlCompactPoll      `xml:"pl,omitempty"`
+	Media      *xmlCompactMedia     `xml:"md,omitempty"`
+	Buttons    *xmlCompactButtons   `xml:"bt,omitempty"`
 	Reply      *xmlCompactReply     `xml:"r,omitempty"`
 	Reactions  *xmlCompactReactions `xml:"rx,omitempty"`
 }
 
+type xmlCompactForward struct {
+	FromID     int64  `xml:"i,attr,omitempty"`
+	FromName   string `xml:"n,attr,omitempty"`
+	Date       string `xml:"t,attr"`
+	PostID     int    `xml:"p,attr,omitempty"`
+	PostAuthor string `xml:"a,attr,omitempty"`
+}
+
+// xmlCompactMedia keeps only the kind and the one-line summary the text
+// template renders after the kind.
+type xmlCompactMedia struct {
+	Kind string `xml:"k,attr"`
+	Text string `xml:",chardata"`
+}
+
+type xmlCompactButtons struct {
+	Items []xmlCompactButton `xml:"b"`
+}
+
+type xmlCompactButton struct {
+	Kind  string `xml:"k,attr"`
+	Text  string `xml:",chardata"`
+	Value string `xml:"v,attr,omitempty"`
+}
+
+type xmlCompactPoll struct {
+	Question    string                 `xml:"q,attr"`
+	TotalVoters int                    `xml:"v,attr"`
+	Quiz        bool                   `xml:"z,attr,omitempty"`
+	Closed      bool                   `xml:"c,attr,omitempty"`
+	Options     []xmlCompactPollOption `xml:"o"`
+}
+
+type xmlCompactPollOption struct {
+	Text    string `xml:",chardata"`
+	Voters  int    `xml:"v,attr"`
+	Correct bool   `xml:"k,attr,omitempty"`
+}
+
 type xmlCompactReply struct {
 	MessageID int    `xml:"i,attr,omitempty"`
 	SenderID  int64  `xml:"s,attr"`
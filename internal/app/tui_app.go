@@ -2,7 +2,9 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"cli-tg-chat-summary/internal/telegram"
 	"cli-tg-chat-summary/internal/tui"
@@ -17,7 +19,11 @@ const (
 	stateChatList
 	stateLoadingTopics
 	stateTopicList
+	stateSearch
+	stateResumePrompt
 	stateProgress
+	stateBatch
+	statePartialPrompt
 	stateSummary
 	stateMessage
 	stateExit
@@ -35,6 +41,8 @@ type topicsLoadedMsg struct {
 
 type fetchResultMsg struct {
 	messages []telegram.Message
+	sections ExportSections
+	streamed *streamedExport
 	err      error
 }
 
@@ -51,10 +59,18 @@ type appModel struct {
 	topic    tui.TopicModel
 	progress tui.ProgressModel
 	summary  tui.SummaryModel
+	confirm  tui.ConfirmModel
+	search   tui.SearchModel
+	// batchView shows the progress of a multi-select export of batch.
+	batchView tui.BatchModel
+	batch     []batchJob
 
 	selectedChat  *telegram.Chat
 	selectedTopic *telegram.Topic
 	exportTitle   string
+	plan          fetchPlan
+	resume        *telegram.Cursor
+	partial       []telegram.Message
 	fetchHandle   *fetchHandle
 	err           error
 }
@@ -70,6 +86,22 @@ func (m appModel) Init() tea.Cmd {
 }
 
 func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.state == stateProgress && isCancelKey(msg) && !m.progress.Canceling() {
+		// Stop the fetch but keep waiting for its result so the messages
+		// gathered so far can still be exported.
+		if m.fetchHandle != nil {
+			m.fetchHandle.cancel()
+		}
+		m.progress = m.progress.Cancel()
+		return m, nil
+	}
+	if m.state == stateBatch && m.fetchHandle != nil && isCancelKey(msg) && !m.batchView.Canceling() {
+		// The current job reports the cancellation, which stops the batch.
+		m.fetchHandle.cancel()
+		m.batchView = m.batchView.Cancel()
+		return m, nil
+	}
+
 	if isCtrlC(msg) {
 		m.cancelFetch()
 		m.state = stateExit
@@ -101,6 +133,9 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case fetchResultMsg:
 		return m.handleFetchResult(msg)
+
+	case batchResultMsg:
+		return m.handleBatchResult(msg)
 	}
 
 	switch m.state {
@@ -121,6 +156,20 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateExit
 				return m, tea.Quit
 			}
+			if m.chat.SearchRequested() {
+				since, until, _ := m.chat.GetDateRange()
+				m.search = tui.NewSearchModel(since, until)
+				m.state = stateSearch
+				return m, m.search.Init()
+			}
+			if m.chat.InboxRequested() {
+				return m.startInbox(m.chat.VisibleChats())
+			}
+			if chats := m.chat.MultiSelected(); len(chats) > 0 {
+				m.selectedChat = nil
+				m.applyExportMode()
+				return m.startBatch(fmt.Sprintf("Exporting %d chats", len(chats)), chatJobs(chats, m.opts))
+			}
 			selected := m.chat.GetSelected()
 			if selected == nil {
 				return m.setMessage("", "No chat selected.", "Press Enter to exit.", stateExit, nil), nil
@@ -128,7 +177,7 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.selectedChat = selected
 			m.selectedTopic = nil
 			m.applyExportMode()
-			if selected.IsForum {
+			if selected.IsForum && !m.opts.AdminLog {
 				m.loading = tui.NewLoadingModel(fmt.Sprintf("Fetching topics for forum %s...", selected.Title))
 				m.state = stateLoadingTopics
 				return m, tea.Batch(m.loading.Init(), fetchTopicsCmd(m.ctx, m.app.tgClient, selected.ID))
@@ -148,6 +197,15 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateChatList
 				return m, nil
 			}
+			if topics := m.topic.MultiSelected(); len(topics) > 0 {
+				title := fmt.Sprintf("Exporting %d topics of %s", len(topics), m.selectedChat.Title)
+				return m.startBatch(title, topicJobs(*m.selectedChat, topics, m.opts))
+			}
+			if m.topic.AllTopicsSelected() {
+				m.selectedTopic = nil
+				m.opts.AllTopics = true
+				return m.startFetch()
+			}
 			selected := m.topic.GetSelected()
 			if selected == nil {
 				return m.setMessage("", "No topic selected.", "Press Enter to return.", stateLoadingChats, nil), nil
@@ -157,6 +215,34 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, cmd
 
+	case stateSearch:
+		var cmd tea.Cmd
+		var updated tea.Model
+		updated, cmd = m.search.Update(msg)
+		m.search = updated.(tui.SearchModel)
+		if m.search.Done() {
+			if m.search.Canceled() {
+				m.loading = tui.NewLoadingModel("Fetching chats...")
+				m.state = stateLoadingChats
+				return m, tea.Batch(m.loading.Init(), fetchChatsCmd(m.ctx, m.app.tgClient))
+			}
+			return m.startSearch()
+		}
+		return m, cmd
+
+	case stateResumePrompt:
+		var cmd tea.Cmd
+		var updated tea.Model
+		updated, cmd = m.confirm.Update(msg)
+		m.confirm = updated.(tui.ConfirmModel)
+		if m.confirm.Done() {
+			if !m.confirm.Confirmed() {
+				m.resume = nil
+			}
+			return m.runFetchPlan()
+		}
+		return m, cmd
+
 	case stateProgress:
 		var cmd tea.Cmd
 		var updated tea.Model
@@ -164,6 +250,36 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.progress = updated.(tui.ProgressModel)
 		return m, cmd
 
+	case stateBatch:
+		var cmd tea.Cmd
+		var updated tea.Model
+		updated, cmd = m.batchView.Update(msg)
+		m.batchView = updated.(tui.BatchModel)
+		if m.batchView.Done() {
+			m.batch = nil
+			m.loading = tui.NewLoadingModel("Fetching chats...")
+			m.state = stateLoadingChats
+			return m, tea.Batch(m.loading.Init(), fetchChatsCmd(m.ctx, m.app.tgClient))
+		}
+		return m, cmd
+
+	case statePartialPrompt:
+		var cmd tea.Cmd
+		var updated tea.Model
+		updated, cmd = m.confirm.Update(msg)
+		m.confirm = updated.(tui.ConfirmModel)
+		if m.confirm.Done() {
+			messages := m.partial
+			m.partial = nil
+			if m.confirm.Confirmed() {
+				return m.exportPartial(messages)
+			}
+			m.loading = tui.NewLoadingModel("Fetching chats...")
+			m.state = stateLoadingChats
+			return m, tea.Batch(m.loading.Init(), fetchChatsCmd(m.ctx, m.app.tgClient))
+		}
+		return m, cmd
+
 	case stateSummary:
 		var cmd tea.Cmd
 		var updated tea.Model
@@ -205,8 +321,16 @@ func (m appModel) View() string {
 		return m.chat.View()
 	case stateTopicList:
 		return m.topic.View()
+	case stateSearch:
+		return m.search.View()
+	case stateResumePrompt:
+		return m.confirm.View()
 	case stateProgress:
 		return m.progress.View()
+	case stateBatch:
+		return m.batchView.View()
+	case statePartialPrompt:
+		return m.confirm.View()
 	case stateSummary:
 		return m.summary.View()
 	case stateMessage:
@@ -221,7 +345,80 @@ func (m appModel) startFetch() (tea.Model, tea.Cmd) {
 	if err != nil {
 		return m.setMessage("Error", err.Error(), "Press Enter to exit.", stateExit, err), nil
 	}
-	handle := m.app.startFetchWithProgress(FetchOpts{Ctx: m.ctx, Title: plan.progressTitle}, plan.fetch)
+	cp, err := m.app.checkpoints.Load(plan.checkpoint)
+	if err != nil {
+		return m.setMessage("Error", err.Error(), "Press Enter to exit.", stateExit, err), nil
+	}
+	m.plan = plan
+	m.resume = nil
+	if cp == nil || !m.opts.checkpointed() {
+		return m.runFetchPlan()
+	}
+	m.resume = cp.cursor()
+	if m.opts.Resume {
+		return m.runFetchPlan()
+	}
+	body := fmt.Sprintf("An interrupted export of %s was found with %d messages already fetched (saved %s).\nContinue from where it stopped?",
+		plan.progressTitle, len(cp.Messages), cp.UpdatedAt.Format("2006-01-02 15:04"))
+	m.confirm = tui.NewConfirmModel("Resume export?", body)
+	m.state = stateResumePrompt
+	return m, nil
+}
+
+// startSearch runs a global search for the query from the search screen,
+// limited to the date range chosen in the chat list.
+func (m appModel) startSearch() (tea.Model, tea.Cmd) {
+	m.applyExportMode()
+	m.opts.Search = m.search.Query()
+	m.opts.SearchScope = m.search.Scope()
+	m.selectedChat = nil
+	m.selectedTopic = nil
+	m.plan = m.app.searchPlan(m.opts)
+	m.resume = nil
+	return m.runFetchPlan()
+}
+
+// startInbox exports the unread messages of the listed chats into one
+// digest, whatever export mode is chosen in the chat list.
+func (m appModel) startInbox(chats []telegram.Chat) (tea.Model, tea.Cmd) {
+	m.applyExportMode()
+	m.opts.UseDateRange = false
+	m.opts.Last = 0
+	m.opts.UnreadMentions = false
+	m.opts.UnreadReactions = false
+	m.opts.Pinned = false
+	m.opts.AdminLog = false
+	m.opts.Inbox = true
+	m.selectedChat = nil
+	m.selectedTopic = nil
+	m.plan = m.app.inboxPlan(chats, m.opts)
+	m.resume = nil
+	return m.runFetchPlan()
+}
+
+func (m appModel) runFetchPlan() (tea.Model, tea.Cmd) {
+	plan := m.plan
+	resume := m.resume
+	fetchOpts := FetchOpts{Ctx: m.ctx, Title: plan.progressTitle}
+	var handle fetchHandle
+	if m.opts.Stream {
+		handle = m.app.startStreamWithProgress(fetchOpts, plan, m.opts)
+	} else {
+		handle = m.app.runWithProgress(fetchOpts, func(ctx context.Context, progress telegram.ProgressFunc) fetchResult {
+			messages, err := m.app.fetchWithCheckpoint(ctx, plan, resume, progress)
+			if err != nil || len(messages) == 0 {
+				return fetchResult{messages: messages, err: err}
+			}
+			if err := m.app.refreshPolls(ctx, plan, messages); err != nil {
+				return fetchResult{messages: messages, err: err}
+			}
+			if err := m.app.nameSenders(ctx, plan, messages); err != nil {
+				return fetchResult{messages: messages, err: err}
+			}
+			sections, err := m.app.fetchSections(ctx, plan, filterMinViews(messages, m.opts.MinViews), progress)
+			return fetchResult{messages: messages, sections: sections, err: err}
+		})
+	}
 	m.fetchHandle = &handle
 	m.exportTitle = plan.exportTitle
 	m.progress = tui.NewProgressModel(plan.progressTitle, handle.msgCh)
@@ -231,20 +428,96 @@ func (m appModel) startFetch() (tea.Model, tea.Cmd) {
 
 func (m appModel) handleFetchResult(msg fetchResultMsg) (tea.Model, tea.Cmd) {
 	m.cancelFetch()
+	if m.opts.Stream {
+		return m.handleStreamResult(msg)
+	}
+	if errors.Is(msg.err, telegram.ErrFetchCanceled) {
+		if len(msg.messages) == 0 {
+			return m.setMessage("", "Fetch canceled before any messages were collected.", "Press Enter to return.", stateLoadingChats, nil), nil
+		}
+		m.partial = msg.messages
+		body := fmt.Sprintf("Fetch canceled after collecting %d messages.\nExport what was fetched so far? The file will be marked as partial and nothing will be marked as read.", len(msg.messages))
+		m.confirm = tui.NewConfirmModel("Partial export", body)
+		m.state = statePartialPrompt
+		return m, nil
+	}
 	if msg.err != nil {
 		return m.setMessage("Error", msg.err.Error(), "Press Enter to exit.", stateExit, msg.err), nil
 	}
 	if len(msg.messages) == 0 {
+		if err := m.app.checkpoints.Remove(m.plan.checkpoint); err != nil {
+			return m.setMessage("Error", err.Error(), "Press Enter to exit.", stateExit, err), nil
+		}
 		return m.setMessage("", "No text messages found to export.", "Press Enter to return.", stateLoadingChats, nil), nil
 	}
 
-	filename, err := m.app.exportMessages(m.exportTitle, msg.messages, m.opts)
+	exported := filterMinViews(msg.messages, m.opts.MinViews)
+	if len(exported) == 0 {
+		body := fmt.Sprintf("No messages with at least %d views found to export.", m.opts.MinViews)
+		if m.selectedChat != nil {
+			body = strings.TrimSpace(body + "\n" + formatMarkReadStatus(m.app.markMessagesAsRead(m.ctx, *m.selectedChat, m.selectedTopic, msg.messages, m.opts)))
+		}
+		if err := m.app.checkpoints.Remove(m.plan.checkpoint); err != nil {
+			body += "\nWarning: " + err.Error()
+		}
+		return m.setMessage("", body, "Press Enter to return.", stateLoadingChats, nil), nil
+	}
+
+	filename, err := m.app.exportMessages(m.exportTitle, exported, msg.sections, m.opts)
 	if err != nil {
 		return m.setMessage("Error", err.Error(), "Press Enter to exit.", stateExit, err), nil
 	}
 
-	markResult := m.app.markMessagesAsRead(m.ctx, *m.selectedChat, m.selectedTopic, msg.messages, m.opts)
-	m.summary = tui.NewSummaryModel(m.exportTitle, filename, len(msg.messages), formatMarkReadStatus(markResult))
+	var status string
+	switch {
+	case m.plan.digest != nil:
+		status = m.app.markDigestAsRead(m.ctx, m.plan.digest, m.opts)
+	case m.selectedChat != nil:
+		markResult := m.app.markMessagesAsRead(m.ctx, *m.selectedChat, m.selectedTopic, msg.messages, m.opts)
+		status = formatMarkReadStatus(markResult)
+	}
+	if err := m.app.checkpoints.Remove(m.plan.checkpoint); err != nil {
+		status = strings.TrimSpace(status + "\nWarning: " + err.Error())
+	}
+	status = strings.Join(append([]string{status}, msg.sections.warningLines()...), "\n")
+	m.summary = tui.NewSummaryModel(m.exportTitle, filename, len(exported), strings.TrimSpace(status))
+	m.state = stateSummary
+	return m, nil
+}
+
+func (m appModel) handleStreamResult(msg fetchResultMsg) (tea.Model, tea.Cmd) {
+	if errors.Is(msg.err, telegram.ErrFetchCanceled) {
+		return m.setMessage("", "Export canceled; the incomplete file was removed.", "Press Enter to return.", stateLoadingChats, nil), nil
+	}
+	if msg.err != nil {
+		return m.setMessage("Error", msg.err.Error(), "Press Enter to exit.", stateExit, msg.err), nil
+	}
+	if msg.streamed == nil || msg.streamed.count == 0 {
+		return m.setMessage("", "No text messages found to export.", "Press Enter to return.", stateLoadingChats, nil), nil
+	}
+
+	markResult := m.app.markAsReadUpTo(m.ctx, *m.selectedChat, m.selectedTopic, msg.streamed.maxID, m.opts)
+	status := strings.Join(append([]string{formatMarkReadStatus(markResult)}, msg.streamed.warnings...), "\n")
+	m.summary = tui.NewSummaryModel(m.exportTitle, msg.streamed.filename, msg.streamed.count, strings.TrimSpace(status))
+	m.state = stateSummary
+	return m, nil
+}
+
+// exportPartial writes messages from a canceled fetch. The checkpoint is kept
+// so the full export can still be resumed later.
+func (m appModel) exportPartial(messages []telegram.Message) (tea.Model, tea.Cmd) {
+	opts := m.opts
+	opts.Partial = true
+	messages = filterMinViews(messages, opts.MinViews)
+	if len(messages) == 0 {
+		body := fmt.Sprintf("No messages with at least %d views found to export.", opts.MinViews)
+		return m.setMessage("", body, "Press Enter to return.", stateLoadingChats, nil), nil
+	}
+	filename, err := m.app.exportMessages(m.exportTitle, messages, ExportSections{}, opts)
+	if err != nil {
+		return m.setMessage("Error", err.Error(), "Press Enter to exit.", stateExit, err), nil
+	}
+	m.summary = tui.NewSummaryModel(m.exportTitle, filename, len(messages), "Partial export; messages were not marked as read.")
 	m.state = stateSummary
 	return m, nil
 }
@@ -261,6 +534,12 @@ func (m appModel) setMessage(title, body, footer string, next appState, err erro
 
 func (m appModel) newChatModel(chats []telegram.Chat) tui.Model {
 	markReadFunc := func(chat telegram.Chat) error {
+		if err := chat.ExportError(); err != nil {
+			return err
+		}
+		if chat.ReadOnly() {
+			return fmt.Errorf("chat is read-only (%s)", chat.Status())
+		}
 		if chat.IsForum {
 			return markForumAsRead(m.ctx, m.app.tgClient, chat)
 		}
@@ -270,17 +549,47 @@ func (m appModel) newChatModel(chats []telegram.Chat) tui.Model {
 		return m.app.tgClient.MarkAsRead(m.ctx, chat, chat.TopMessageID)
 	}
 
-	modelOpts := tui.ModelOptions{}
+	modelOpts := tui.ModelOptions{Context: m.opts.Context}
 	if m.opts.UseDateRange {
 		modelOpts.Mode = tui.ModeDateRange
 		modelOpts.Since = m.opts.Since
 		modelOpts.Until = m.opts.Until
 	}
+	if m.opts.Last > 0 {
+		modelOpts.Mode = tui.ModeLastN
+		modelOpts.Last = m.opts.Last
+	}
+	if m.opts.UnreadMentions {
+		modelOpts.Mode = tui.ModeMentions
+	}
+	if m.opts.UnreadReactions {
+		modelOpts.Mode = tui.ModeReactions
+	}
+	if m.opts.Pinned {
+		modelOpts.Mode = tui.ModePinned
+	}
+	if m.opts.AdminLog {
+		modelOpts.Mode = tui.ModeAdminLog
+	}
 	return tui.NewModel(chats, markReadFunc, modelOpts)
 }
 
 func (m *appModel) applyExportMode() {
 	mode := m.chat.GetExportMode()
+	m.opts.Context = m.chat.GetContextSize()
+	m.opts.Last = 0
+	m.opts.UnreadMentions = mode == tui.ModeMentions
+	m.opts.UnreadReactions = mode == tui.ModeReactions
+	m.opts.Pinned = mode == tui.ModePinned
+	m.opts.AdminLog = mode == tui.ModeAdminLog
+	m.opts.Saved = false
+	m.opts.Search = ""
+	m.opts.Inbox = false
+	m.opts.AllTopics = false
+	if m.opts.AdminLog {
+		// The admin log keeps the time window given on the command line.
+		return
+	}
 	if mode == tui.ModeDateRange {
 		since, until, ok := m.chat.GetDateRange()
 		if ok {
@@ -288,9 +597,13 @@ func (m *appModel) applyExportMode() {
 			m.opts.Since = since
 			m.opts.Until = until
 		}
-		return
+	} else {
+		m.opts.UseDateRange = false
+		if count, ok := m.chat.GetLastCount(); ok {
+			m.opts.Last = count
+		}
 	}
-	m.opts.UseDateRange = false
+	m.opts.Saved = m.selectedChat != nil && savedDigest(*m.selectedChat, m.opts)
 }
 
 func (m *appModel) cancelFetch() {
@@ -346,6 +659,14 @@ func waitForFetchResult(resultCh <-chan fetchResult) tea.Cmd {
 	}
 }
 
+func isCancelKey(msg tea.Msg) bool {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+	return key.Type == tea.KeyCtrlC || key.Type == tea.KeyEsc
+}
+
 func isCtrlC(msg tea.Msg) bool {
 	key, ok := msg.(tea.KeyMsg)
 	if !ok {
@@ -3,6 +3,8 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"cli-tg-chat-summary/internal/telegram"
@@ -77,3 +79,92 @@ func TestMarkForumAsRead(t *testing.T) {
 		t.Errorf("unexpected second call: %+v", client.calls[1])
 	}
 }
+
+func TestAppModel_EscOnProgressCancelsFetch(t *testing.T) {
+	canceled := false
+	model := appModel{
+		state:       stateProgress,
+		progress:    tui.NewProgressModel("Chat", nil),
+		fetchHandle: &fetchHandle{cancel: func() { canceled = true }},
+	}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil {
+		t.Error("expected no command while waiting for the fetch to stop")
+	}
+	m := updated.(appModel)
+	if !canceled {
+		t.Error("expected fetch to be canceled")
+	}
+	if m.state != stateProgress {
+		t.Errorf("expected to stay in stateProgress, got %v", m.state)
+	}
+	if !m.progress.Canceling() {
+		t.Error("expected progress view to show canceling")
+	}
+}
+
+func TestAppModel_CanceledFetchOffersPartialExport(t *testing.T) {
+	model := appModel{state: stateProgress}
+
+	msg := fetchResultMsg{
+		messages: []telegram.Message{{ID: 1, Text: "a"}},
+		err:      fmt.Errorf("%w: %w", telegram.ErrFetchCanceled, context.Canceled),
+	}
+	updated, _ := model.Update(msg)
+	m := updated.(appModel)
+	if m.state != statePartialPrompt {
+		t.Fatalf("expected statePartialPrompt, got %v", m.state)
+	}
+	if len(m.partial) != 1 {
+		t.Fatalf("expected partial messages to be kept, got %d", len(m.partial))
+	}
+}
+
+func TestMarkMessagesAsRead_SkipsPartial(t *testing.T) {
+	a := &App{}
+	messages := []telegram.Message{{ID: 10}}
+	result := a.markMessagesAsRead(context.Background(), telegram.Chat{ID: 1}, nil, messages, RunOptions{Partial: true})
+	if result.Attempted {
+		t.Fatal("expected partial export to skip mark as read")
+	}
+}
+
+func TestMarkMessagesAsRead_MentionsSkipPartialAndEmpty(t *testing.T) {
+	a := &App{}
+	if result := a.markMessagesAsRead(context.Background(), telegram.Chat{ID: 1}, nil, []telegram.Message{{ID: 10}}, RunOptions{UnreadReactions: true, Partial: true}); result.Attempted {
+		t.Fatal("expected partial reactions export to skip mark as read")
+	}
+	if result := a.markMessagesAsRead(context.Background(), telegram.Chat{ID: 1}, nil, nil, RunOptions{UnreadMentions: true}); result.Attempted {
+		t.Fatal("expected empty mentions export to skip mark as read")
+	}
+}
+
+func TestMarkMessagesAsRead_SkipsNonUnreadModes(t *testing.T) {
+	a := &App{}
+	messages := []telegram.Message{{ID: 10}}
+	for _, opts := range []RunOptions{{Last: 100}, {FromID: 1, ToID: 50}, {UseDateRange: true}, {Pinned: true}} {
+		result := a.markMessagesAsRead(context.Background(), telegram.Chat{ID: 1}, nil, messages, opts)
+		if result.Attempted {
+			t.Fatalf("expected %+v to skip mark as read", opts)
+		}
+	}
+}
+
+func TestMarkMessagesAsRead_SkipsReadOnlyChats(t *testing.T) {
+	a := &App{}
+	messages := []telegram.Message{{ID: 10}}
+	for _, chat := range []telegram.Chat{{ID: 1, Left: true}, {ID: 2, Deactivated: true}} {
+		result := a.markMessagesAsRead(context.Background(), chat, nil, messages, RunOptions{UnreadMentions: true})
+		if result.Attempted || !strings.Contains(result.Skipped, "read-only") {
+			t.Fatalf("expected mentions of %+v to skip mark as read, got %+v", chat, result)
+		}
+		result = a.markMessagesAsRead(context.Background(), chat, nil, messages, RunOptions{})
+		if result.Attempted {
+			t.Fatalf("expected %+v to skip mark as read", chat)
+		}
+		if !strings.Contains(result.Skipped, "read-only ("+chat.Status()+")") {
+			t.Fatalf("expected skipped note, got %q", result.Skipped)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package telegram
+
+import (
+	"fmt"
+
+	"github.com/gotd/td/tg"
+)
+
+// Status names the access state of a chat: "forbidden", "restricted",
+// "deactivated", "left", or "" for chats the user is a member of.
+func (c Chat) Status() string {
+	switch {
+	case c.Forbidden:
+		return "forbidden"
+	case c.Restricted:
+		return "restricted"
+	case c.Deactivated:
+		return "deactivated"
+	case c.Left:
+		return "left"
+	}
+	return ""
+}
+
+// ExportError explains why the chat cannot be exported, or returns nil.
+func (c Chat) ExportError() error {
+	switch {
+	case c.Forbidden:
+		return fmt.Errorf("cannot export %q: you were removed or banned from this chat", c.Title)
+	case c.Restricted:
+		return fmt.Errorf("cannot export %q: chat is restricted by Telegram: %s", c.Title, c.RestrictionReason)
+	}
+	return nil
+}
+
+// ReadOnly reports whether the chat can be exported but not marked as read,
+// because the user left it or it was deactivated.
+func (c Chat) ReadOnly() bool {
+	return c.Left || c.Deactivated
+}
+
+// restrictionReason returns the reason a channel is restricted on all
+// platforms. Restrictions that only apply to other platforms, such as iOS
+// app store rules, are ignored.
+func restrictionReason(channel *tg.Channel) (string, bool) {
+	if !channel.Restricted {
+		return "", false
+	}
+	for _, reason := range channel.RestrictionReason {
+		if reason.Platform == "all" {
+			return reason.Text, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,366 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// AdminLogEvent selects a category of admin log events to export.
+type AdminLogEvent string
+
+const (
+	AdminLogBans      AdminLogEvent = "bans"
+	AdminLogEdits     AdminLogEvent = "edits"
+	AdminLogDeletions AdminLogEvent = "deletions"
+	AdminLogSettings  AdminLogEvent = "settings"
+	AdminLogInvites   AdminLogEvent = "invites"
+)
+
+// Admin actions set on messages converted from admin log events. Events
+// outside the filter categories use the remaining kinds.
+const (
+	AdminActionBan      = "ban"
+	AdminActionEdit     = "edit"
+	AdminActionDelete   = "delete"
+	AdminActionSettings = "settings"
+	AdminActionInvite   = "invite"
+	AdminActionPin      = "pin"
+	AdminActionJoin     = "join"
+	AdminActionLeave    = "leave"
+	AdminActionAdmin    = "admin"
+	AdminActionOther    = "other"
+)
+
+// ParseAdminLogEvents parses a comma separated list of event categories,
+// e.g. "bans,edits". An empty list selects all events.
+func ParseAdminLogEvents(value string) ([]AdminLogEvent, error) {
+	var events []AdminLogEvent
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		event := AdminLogEvent(name)
+		switch event {
+		case AdminLogBans, AdminLogEdits, AdminLogDeletions, AdminLogSettings, AdminLogInvites:
+			events = append(events, event)
+		default:
+			return nil, fmt.Errorf("unknown admin log event %q (use bans, edits, deletions, settings or invites)", name)
+		}
+	}
+	return events, nil
+}
+
+// GetAdminLog fetches the admin log of a supergroup or channel, newest
+// first, as messages sent by the acting admin with a readable description
+// of the action. events limits the log to those categories and since and
+// until to that time window; zero values select everything Telegram keeps.
+func (c *Client) GetAdminLog(ctx context.Context, chatID int64, events []AdminLogEvent, since, until time.Time, resume *Cursor, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+	peer, ok := inputPeer.(*tg.InputPeerChannel)
+	if !ok {
+		return nil, fmt.Errorf("admin log is only available for supergroups and channels")
+	}
+
+	request := &tg.ChannelsGetAdminLogRequest{
+		Channel: &tg.InputChannel{ChannelID: peer.ChannelID, AccessHash: peer.AccessHash},
+	}
+	if filter, ok := adminLogFilter(events); ok {
+		request.SetEventsFilter(filter)
+	}
+	until = resumeUntil(resume, until)
+	return c.pageAdminLog(ctx, progress, since, until, resume, func(maxID int64, limit int) (*tg.ChannelsAdminLogResults, error) {
+		request.MaxID = maxID
+		request.Limit = limit
+		result, err := c.ctx.Raw.ChannelsGetAdminLog(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get admin log: %w", err)
+		}
+		return result, nil
+	})
+}
+
+// pageAdminLog pages backwards through admin log events below maxID until
+// the log ends or an event is older than since.
+func (c *Client) pageAdminLog(
+	ctx context.Context,
+	progress ProgressFunc,
+	since, until time.Time,
+	resume *Cursor,
+	fetch func(maxID int64, limit int) (*tg.ChannelsAdminLogResults, error),
+) ([]Message, error) {
+	const batchSize = 100
+	maxID, collected := resumePosition(progress, resume)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return collected, fmt.Errorf("%w: %w", ErrFetchCanceled, err)
+		}
+
+		result, err := fetch(int64(maxID), batchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return collected, fmt.Errorf("%w: %w", ErrFetchCanceled, ctx.Err())
+			}
+			return nil, err
+		}
+		c.rememberUsers(result.Users)
+		users := usersByID(result.Users)
+
+		parsed := 0
+		stop := false
+		lastID := maxID
+		for _, event := range result.Events {
+			id := int(event.ID)
+			if maxID != 0 && id >= maxID {
+				continue
+			}
+			lastID = id
+			date := time.Unix(int64(event.Date), 0)
+			if !until.IsZero() && date.After(until) {
+				continue
+			}
+			if !since.IsZero() && date.Before(since) {
+				stop = true
+				break
+			}
+			collected = append(collected, adminLogMessage(event, users))
+			parsed++
+		}
+		reportProgress(progress, ProgressUpdate{
+			Phase:   "admin-log",
+			Parsed:  parsed,
+			Scanned: len(result.Events),
+			Batch:   1,
+			Cursor:  &Cursor{OffsetID: lastID, Messages: collected, Until: until},
+		})
+
+		if stop || len(result.Events) < batchSize || lastID == maxID {
+			return collected, nil
+		}
+		maxID = lastID
+	}
+}
+
+// adminLogFilter maps event categories to the API filter. It reports false
+// when no category is selected, so that all events are returned.
+func adminLogFilter(events []AdminLogEvent) (tg.ChannelAdminLogEventsFilter, bool) {
+	var filter tg.ChannelAdminLogEventsFilter
+	for _, event := range events {
+		switch event {
+		case AdminLogBans:
+			filter.Ban, filter.Unban, filter.Kick, filter.Unkick = true, true, true, true
+		case AdminLogEdits:
+			filter.Edit = true
+		case AdminLogDeletions:
+			filter.Delete = true
+		case AdminLogSettings:
+			filter.Info, filter.Settings = true, true
+		case AdminLogInvites:
+			filter.Invite, filter.Invites = true, true
+		}
+	}
+	return filter, len(events) > 0
+}
+
+// adminLogMessage converts an admin log event into a message from the
+// acting admin whose text describes the action and its target.
+func adminLogMessage(event tg.ChannelAdminLogEvent, users map[int64]*tg.User) Message {
+	action, text := describeAdminAction(event.Action, users)
+	return Message{
+		ID:          int(event.ID),
+		Date:        time.Unix(int64(event.Date), 0),
+		Text:        text,
+		SenderID:    event.UserID,
+		AdminAction: action,
+	}
+}
+
+func describeAdminAction(action tg.ChannelAdminLogEventActionClass, users map[int64]*tg.User) (string, string) {
+	switch a := action.(type) {
+	case *tg.ChannelAdminLogEventActionParticipantToggleBan:
+		target := userLabel(participantID(a.NewParticipant), users)
+		banned, ok := a.NewParticipant.(*tg.ChannelParticipantBanned)
+		switch {
+		case !ok:
+			return AdminActionBan, "unbanned " + target
+		case banned.BannedRights.ViewMessages:
+			return AdminActionBan, "banned " + target
+		default:
+			return AdminActionBan, "restricted " + target
+		}
+	case *tg.ChannelAdminLogEventActionEditMessage:
+		return AdminActionEdit, fmt.Sprintf("edited message %d: %s -> %s",
+			a.NewMessage.GetID(), quoteMessage(a.PrevMessage), quoteMessage(a.NewMessage))
+	case *tg.ChannelAdminLogEventActionDeleteMessage:
+		return AdminActionDelete, fmt.Sprintf("deleted message %d from %s: %s",
+			a.Message.GetID(), userLabel(messageSenderID(a.Message), users), quoteMessage(a.Message))
+	case *tg.ChannelAdminLogEventActionChangeTitle:
+		return AdminActionSettings, fmt.Sprintf("changed title from %q to %q", a.PrevValue, a.NewValue)
+	case *tg.ChannelAdminLogEventActionChangeAbout:
+		return AdminActionSettings, fmt.Sprintf("changed description to %q", oneLineText(a.NewValue))
+	case *tg.ChannelAdminLogEventActionChangeUsername:
+		return AdminActionSettings, fmt.Sprintf("changed username from %q to %q", a.PrevValue, a.NewValue)
+	case *tg.ChannelAdminLogEventActionChangePhoto:
+		return AdminActionSettings, "changed photo"
+	case *tg.ChannelAdminLogEventActionToggleSlowMode:
+		return AdminActionSettings, fmt.Sprintf("set slow mode to %s", time.Duration(a.NewValue)*time.Second)
+	case *tg.ChannelAdminLogEventActionDefaultBannedRights:
+		return AdminActionSettings, "changed default member permissions"
+	case *tg.ChannelAdminLogEventActionToggleInvites:
+		return AdminActionSettings, fmt.Sprintf("set member invites to %s", onOff(a.NewValue))
+	case *tg.ChannelAdminLogEventActionTogglePreHistoryHidden:
+		return AdminActionSettings, fmt.Sprintf("set history hidden for new members to %s", onOff(a.NewValue))
+	case *tg.ChannelAdminLogEventActionToggleNoForwards:
+		return AdminActionSettings, fmt.Sprintf("set content protection to %s", onOff(a.NewValue))
+	case *tg.ChannelAdminLogEventActionParticipantInvite:
+		return AdminActionInvite, "invited " + userLabel(participantID(a.Participant), users)
+	case *tg.ChannelAdminLogEventActionParticipantJoinByInvite:
+		return AdminActionInvite, "joined via invite link " + inviteLink(a.Invite)
+	case *tg.ChannelAdminLogEventActionParticipantJoinByRequest:
+		return AdminActionInvite, "joined after approval by " + userLabel(a.ApprovedBy, users)
+	case *tg.ChannelAdminLogEventActionExportedInviteRevoke:
+		return AdminActionInvite, "revoked invite link " + inviteLink(a.Invite)
+	case *tg.ChannelAdminLogEventActionExportedInviteDelete:
+		return AdminActionInvite, "deleted invite link " + inviteLink(a.Invite)
+	case *tg.ChannelAdminLogEventActionExportedInviteEdit:
+		return AdminActionInvite, "edited invite link " + inviteLink(a.NewInvite)
+	case *tg.ChannelAdminLogEventActionUpdatePinned:
+		if msg, ok := a.Message.(*tg.Message); ok && !msg.Pinned {
+			return AdminActionPin, fmt.Sprintf("unpinned message %d", msg.ID)
+		}
+		return AdminActionPin, fmt.Sprintf("pinned message %d: %s", a.Message.GetID(), quoteMessage(a.Message))
+	case *tg.ChannelAdminLogEventActionParticipantJoin:
+		return AdminActionJoin, "joined"
+	case *tg.ChannelAdminLogEventActionParticipantLeave:
+		return AdminActionLeave, "left"
+	case *tg.ChannelAdminLogEventActionParticipantToggleAdmin:
+		target := userLabel(participantID(a.NewParticipant), users)
+		switch a.NewParticipant.(type) {
+		case *tg.ChannelParticipantAdmin, *tg.ChannelParticipantCreator:
+			return AdminActionAdmin, "promoted " + target
+		default:
+			return AdminActionAdmin, "demoted " + target
+		}
+	}
+	// Remaining actions are named after their type, e.g.
+	// "channelAdminLogEventActionToggleForum" becomes "toggle forum".
+	name := strings.TrimPrefix(action.TypeName(), "channelAdminLogEventAction")
+	return AdminActionOther, splitCamelCase(name)
+}
+
+// participantID returns the user ID of a channel participant, or 0.
+func participantID(participant tg.ChannelParticipantClass) int64 {
+	switch p := participant.(type) {
+	case *tg.ChannelParticipant:
+		return p.UserID
+	case *tg.ChannelParticipantSelf:
+		return p.UserID
+	case *tg.ChannelParticipantCreator:
+		return p.UserID
+	case *tg.ChannelParticipantAdmin:
+		return p.UserID
+	case *tg.ChannelParticipantBanned:
+		return peerUserID(p.Peer)
+	case *tg.ChannelParticipantLeft:
+		return peerUserID(p.Peer)
+	}
+	return 0
+}
+
+func peerUserID(peer tg.PeerClass) int64 {
+	if user, ok := peer.(*tg.PeerUser); ok {
+		return user.UserID
+	}
+	return 0
+}
+
+func messageSenderID(msg tg.MessageClass) int64 {
+	if m, ok := msg.(*tg.Message); ok && m.FromID != nil {
+		return peerUserID(m.FromID)
+	}
+	return 0
+}
+
+// userLabel names a user as "Jane Doe (@jane, id=5)", falling back to the
+// ID for users missing from the response.
+func userLabel(id int64, users map[int64]*tg.User) string {
+	user, ok := users[id]
+	if !ok {
+		return fmt.Sprintf("id=%d", id)
+	}
+	details := []string{fmt.Sprintf("id=%d", id)}
+	if user.Username != "" {
+		details = append([]string{"@" + user.Username}, details...)
+	}
+	name := userDisplayName(user)
+	if name == "" {
+		return strings.Join(details, ", ")
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(details, ", "))
+}
+
+func usersByID(users []tg.UserClass) map[int64]*tg.User {
+	byID := make(map[int64]*tg.User, len(users))
+	for _, u := range users {
+		if user, ok := u.(*tg.User); ok {
+			byID[user.ID] = user
+		}
+	}
+	return byID
+}
+
+// quoteMessage quotes the text of a message on one line, shortened to keep
+// log lines readable.
+func quoteMessage(msg tg.MessageClass) string {
+	m, ok := msg.(*tg.Message)
+	if !ok || m.Message == "" {
+		return "(no text)"
+	}
+	const maxRunes = 120
+	text := []rune(oneLineText(m.Message))
+	if len(text) > maxRunes {
+		return fmt.Sprintf("%q", string(text[:maxRunes])+"…")
+	}
+	return fmt.Sprintf("%q", string(text))
+}
+
+func inviteLink(invite tg.ExportedChatInviteClass) string {
+	if exported, ok := invite.(*tg.ChatInviteExported); ok {
+		return exported.Link
+	}
+	return "(public join requests)"
+}
+
+func oneLineText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
+
+// splitCamelCase turns "ToggleForum" into "toggle forum".
+func splitCamelCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestParseAdminLogEvents(t *testing.T) {
+	events, err := ParseAdminLogEvents("bans, edits,,invites")
+	if err != nil {
+		t.Fatalf("ParseAdminLogEvents error: %v", err)
+	}
+	if len(events) != 3 || events[0] != AdminLogBans || events[2] != AdminLogInvites {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if _, err := ParseAdminLogEvents("bans,polls"); err == nil {
+		t.Fatal("expected unknown event to be rejected")
+	}
+	if events, _ := ParseAdminLogEvents(""); events != nil {
+		t.Fatalf("expected no events, got %v", events)
+	}
+}
+
+func TestPageAdminLog_PagesWithinWindow(t *testing.T) {
+	client := &Client{}
+	users := []tg.UserClass{&tg.User{ID: 7, FirstName: "Bob", Username: "bob"}}
+
+	var seenMaxIDs []int64
+	fetch := func(maxID int64, limit int) (*tg.ChannelsAdminLogResults, error) {
+		seenMaxIDs = append(seenMaxIDs, maxID)
+		if maxID == 0 {
+			events := make([]tg.ChannelAdminLogEvent, 0, limit)
+			for i := 0; i < limit; i++ {
+				events = append(events, tg.ChannelAdminLogEvent{
+					ID:     int64(500 - i),
+					Date:   5000 - i,
+					UserID: 1,
+					Action: &tg.ChannelAdminLogEventActionParticipantJoin{},
+				})
+			}
+			return &tg.ChannelsAdminLogResults{Events: events, Users: users}, nil
+		}
+		return &tg.ChannelsAdminLogResults{
+			Events: []tg.ChannelAdminLogEvent{
+				// Some servers repeat the max_id event; it is skipped.
+				{ID: maxID, Date: 4901, UserID: 1, Action: &tg.ChannelAdminLogEventActionParticipantJoin{}},
+				{ID: 300, Date: 3000, UserID: 1, Action: &tg.ChannelAdminLogEventActionParticipantToggleBan{
+					PrevParticipant: &tg.ChannelParticipant{UserID: 7},
+					NewParticipant: &tg.ChannelParticipantBanned{
+						Peer:         &tg.PeerUser{UserID: 7},
+						BannedRights: tg.ChatBannedRights{ViewMessages: true},
+					},
+				}},
+				{ID: 200, Date: 1000, UserID: 1, Action: &tg.ChannelAdminLogEventActionParticipantLeave{}},
+			},
+			Users: users,
+		}, nil
+	}
+
+	var cursors []*Cursor
+	progress := func(update ProgressUpdate) { cursors = append(cursors, update.Cursor) }
+	since := time.Unix(2000, 0)
+	until := time.Unix(4950, 0)
+
+	got, err := client.pageAdminLog(context.Background(), progress, since, until, nil, fetch)
+	if err != nil {
+		t.Fatalf("pageAdminLog error: %v", err)
+	}
+	// Events 500-451 are after until; 450-401 and the ban fall in the window.
+	if len(got) != 51 {
+		t.Fatalf("expected 51 events, got %d", len(got))
+	}
+	if len(seenMaxIDs) != 2 || seenMaxIDs[1] != 401 {
+		t.Fatalf("unexpected max IDs: %v", seenMaxIDs)
+	}
+	ban := got[len(got)-1]
+	if ban.ID != 300 || ban.SenderID != 1 || ban.AdminAction != AdminActionBan || ban.Text != "banned Bob (@bob, id=7)" {
+		t.Fatalf("unexpected ban entry: %+v", ban)
+	}
+	if len(cursors) != 2 || cursors[0] == nil || cursors[0].OffsetID != 401 {
+		t.Fatalf("expected a cursor per page, got %v", cursors)
+	}
+}
+
+func TestDescribeAdminAction(t *testing.T) {
+	users := map[int64]*tg.User{5: {ID: 5, FirstName: "Jane", LastName: "Doe"}}
+	tests := []struct {
+		action     tg.ChannelAdminLogEventActionClass
+		wantAction string
+		wantText   string
+	}{
+		{
+			action: &tg.ChannelAdminLogEventActionParticipantToggleBan{
+				PrevParticipant: &tg.ChannelParticipantBanned{Peer: &tg.PeerUser{UserID: 5}},
+				NewParticipant:  &tg.ChannelParticipant{UserID: 5},
+			},
+			wantAction: AdminActionBan,
+			wantText:   "unbanned Jane Doe (id=5)",
+		},
+		{
+			action: &tg.ChannelAdminLogEventActionEditMessage{
+				PrevMessage: &tg.Message{ID: 9, Message: "helo"},
+				NewMessage:  &tg.Message{ID: 9, Message: "hello"},
+			},
+			wantAction: AdminActionEdit,
+			wantText:   `edited message 9: "helo" -> "hello"`,
+		},
+		{
+			action: &tg.ChannelAdminLogEventActionDeleteMessage{
+				Message: &tg.Message{ID: 10, FromID: &tg.PeerUser{UserID: 5}, Message: "spam\nlink"},
+			},
+			wantAction: AdminActionDelete,
+			wantText:   `deleted message 10 from Jane Doe (id=5): "spam link"`,
+		},
+		{
+			action:     &tg.ChannelAdminLogEventActionChangeTitle{PrevValue: "Old", NewValue: "New"},
+			wantAction: AdminActionSettings,
+			wantText:   `changed title from "Old" to "New"`,
+		},
+		{
+			action:     &tg.ChannelAdminLogEventActionExportedInviteRevoke{Invite: &tg.ChatInviteExported{Link: "https://t.me/+abc"}},
+			wantAction: AdminActionInvite,
+			wantText:   "revoked invite link https://t.me/+abc",
+		},
+		{
+			action:     &tg.ChannelAdminLogEventActionToggleForum{NewValue: true},
+			wantAction: AdminActionOther,
+			wantText:   "toggle forum",
+		},
+	}
+
+	for _, tt := range tests {
+		action, text := describeAdminAction(tt.action, users)
+		if action != tt.wantAction || text != tt.wantText {
+			t.Errorf("describeAdminAction(%T) = %q, %q; want %q, %q", tt.action, action, text, tt.wantAction, tt.wantText)
+		}
+	}
+}
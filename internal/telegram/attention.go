@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/gotd/td/tg"
+)
+
+// self returns the logged in user, or nil before login.
+func (c *Client) self() *tg.User {
+	if c.ctx == nil {
+		return nil
+	}
+	return c.ctx.Self
+}
+
+// addOutgoingIDs adds the IDs of the user's own messages in a page to ids,
+// so replies to them can be recognized without extra requests.
+func addOutgoingIDs(ids map[int]bool, msgs []tg.MessageClass) {
+	for _, m := range msgs {
+		if msg, ok := m.(*tg.Message); ok && msg.Out {
+			ids[msg.ID] = true
+		}
+	}
+}
+
+// markRepliesToMe marks the messages replying to one of the outgoing
+// messages. Pages come newest first, so a reply is often fetched pages
+// before the message it replies to.
+func markRepliesToMe(messages []Message, outgoing map[int]bool) {
+	for i := range messages {
+		if messages[i].ReplyToID != 0 && outgoing[messages[i].ReplyToID] {
+			messages[i].ReplyToMe = true
+		}
+	}
+}
+
+// markAttention sets Mentioned and ReplyToMe on m. Telegram raises the
+// mentioned flag both for explicit mentions and for replies to the user's
+// messages, so a flagged reply without a mention of the user counts as a
+// reply. Replies to outgoing messages fetched so far are always marked.
+func markAttention(m *Message, msg *tg.Message, self *tg.User, outgoing map[int]bool) {
+	if header, ok := msg.ReplyTo.(*tg.MessageReplyHeader); ok && header.ReplyToPeerID == nil {
+		m.ReplyToID = header.ReplyToMsgID
+	}
+	if m.ReplyToID != 0 && outgoing[m.ReplyToID] {
+		m.ReplyToMe = true
+	}
+	if !msg.Mentioned {
+		return
+	}
+	switch {
+	case mentionsSelf(msg, self):
+		m.Mentioned = true
+	case m.ReplyToID != 0:
+		m.ReplyToMe = true
+	default:
+		m.Mentioned = true
+	}
+}
+
+// mentionsSelf reports whether the message text mentions self by user ID or
+// @username.
+func mentionsSelf(msg *tg.Message, self *tg.User) bool {
+	if self == nil {
+		return false
+	}
+	for _, entity := range msg.Entities {
+		switch e := entity.(type) {
+		case *tg.MessageEntityMentionName:
+			if e.UserID == self.ID {
+				return true
+			}
+		case *tg.MessageEntityMention:
+			if self.Username != "" && strings.EqualFold(entityText(msg.Message, e.Offset, e.Length), "@"+self.Username) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// entityText returns the part of text covered by an entity. Entity offsets
+// and lengths are counted in UTF-16 code units.
+func entityText(text string, offset, length int) string {
+	units := utf16.Encode([]rune(text))
+	if offset < 0 || length < 0 || offset+length > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[offset : offset+length]))
+}
@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestMarkAttention(t *testing.T) {
+	self := &tg.User{ID: 7, Username: "alice"}
+	reply := &tg.MessageReplyHeader{ReplyToMsgID: 40}
+
+	tests := []struct {
+		name      string
+		msg       *tg.Message
+		outgoing  map[int]bool
+		mentioned bool
+		replyToMe bool
+	}{
+		{
+			name: "plain message",
+			msg:  &tg.Message{ID: 1, Message: "hello"},
+		},
+		{
+			name:      "username mention",
+			msg:       &tg.Message{ID: 2, Message: "hi @Alice", Mentioned: true, Entities: []tg.MessageEntityClass{&tg.MessageEntityMention{Offset: 3, Length: 6}}},
+			mentioned: true,
+		},
+		{
+			name:      "mention by user id",
+			msg:       &tg.Message{ID: 3, Message: "ping Alice", Mentioned: true, Entities: []tg.MessageEntityClass{&tg.MessageEntityMentionName{Offset: 5, Length: 5, UserID: 7}}},
+			mentioned: true,
+		},
+		{
+			name:      "flagged reply without mention",
+			msg:       &tg.Message{ID: 4, Message: "agreed", Mentioned: true, ReplyTo: reply},
+			replyToMe: true,
+		},
+		{
+			name:      "reply to outgoing message in page",
+			msg:       &tg.Message{ID: 5, Message: "agreed", ReplyTo: reply},
+			outgoing:  map[int]bool{40: true},
+			replyToMe: true,
+		},
+		{
+			name: "reply to someone else",
+			msg:  &tg.Message{ID: 6, Message: "agreed", ReplyTo: reply},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMessage(tt.msg)
+			markAttention(&m, tt.msg, self, tt.outgoing)
+			if m.Mentioned != tt.mentioned || m.ReplyToMe != tt.replyToMe {
+				t.Fatalf("got mentioned=%v replyToMe=%v, want %v %v", m.Mentioned, m.ReplyToMe, tt.mentioned, tt.replyToMe)
+			}
+		})
+	}
+}
+
+func TestFetchMessages_RepliesToOutgoingOnLaterPage(t *testing.T) {
+	client := &Client{}
+	reply := &tg.MessageReplyHeader{ReplyToMsgID: 50}
+	fetch := func(offsetID, _, limit int) (tg.MessagesMessagesClass, error) {
+		if offsetID == 0 {
+			msgs := []tg.MessageClass{&tg.Message{ID: 200, Message: "agreed", ReplyTo: reply}}
+			for id := 199; len(msgs) < limit; id-- {
+				msgs = append(msgs, &tg.Message{ID: id, Message: "filler"})
+			}
+			return &tg.MessagesMessages{Messages: msgs}, nil
+		}
+		return &tg.MessagesMessages{Messages: []tg.MessageClass{&tg.Message{ID: 50, Out: true, Message: "proposal"}}}, nil
+	}
+	filter := func(msg *tg.Message) (bool, bool) {
+		return !msg.Out, false
+	}
+
+	got, err := client.fetchMessages(context.Background(), nil, "phase", time.Time{}, false, nil, fetch, filter)
+	if err != nil {
+		t.Fatalf("fetchMessages error: %v", err)
+	}
+	if len(got) == 0 || got[0].ID != 200 || !got[0].ReplyToMe {
+		t.Fatalf("expected the reply on the first page to be marked, got %+v", got[:1])
+	}
+}
+
+func TestEntityText_UTF16Offsets(t *testing.T) {
+	text := "😀 hi @bob"
+	if got := entityText(text, 6, 4); got != "@bob" {
+		t.Fatalf("entityText() = %q, want %q", got, "@bob")
+	}
+	if got := entityText(text, 8, 10); got != "" {
+		t.Fatalf("expected out of range entity to be empty, got %q", got)
+	}
+}
@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"unicode/utf8"
+
+	"github.com/gotd/td/tg"
+)
+
+// Inline button kinds.
+const (
+	ButtonURL          = "url"
+	ButtonCallback     = "callback"
+	ButtonSwitchInline = "switch_inline"
+)
+
+// Button is one inline keyboard button. Value holds the URL, the callback
+// data (when it is readable text) or the switch-inline query.
+type Button struct {
+	Kind  string
+	Text  string
+	Value string
+}
+
+// messageButtons flattens the inline keyboard rows of a message, keeping
+// URL, callback and switch-inline buttons in reading order.
+func messageButtons(msg *tg.Message) []Button {
+	markup, ok := msg.ReplyMarkup.(*tg.ReplyInlineMarkup)
+	if !ok {
+		return nil
+	}
+	var buttons []Button
+	for _, row := range markup.Rows {
+		for _, button := range row.Buttons {
+			switch b := button.(type) {
+			case *tg.KeyboardButtonURL:
+				buttons = append(buttons, Button{Kind: ButtonURL, Text: b.Text, Value: b.URL})
+			case *tg.KeyboardButtonURLAuth:
+				buttons = append(buttons, Button{Kind: ButtonURL, Text: b.Text, Value: b.URL})
+			case *tg.KeyboardButtonWebView:
+				buttons = append(buttons, Button{Kind: ButtonURL, Text: b.Text, Value: b.URL})
+			case *tg.KeyboardButtonCallback:
+				data := ""
+				if utf8.Valid(b.Data) {
+					data = string(b.Data)
+				}
+				buttons = append(buttons, Button{Kind: ButtonCallback, Text: b.Text, Value: data})
+			case *tg.KeyboardButtonSwitchInline:
+				buttons = append(buttons, Button{Kind: ButtonSwitchInline, Text: b.Text, Value: b.Query})
+			}
+		}
+	}
+	return buttons
+}
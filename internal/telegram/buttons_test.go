@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestMessageButtons(t *testing.T) {
+	msg := &tg.Message{ReplyMarkup: &tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{
+		{Buttons: []tg.KeyboardButtonClass{
+			&tg.KeyboardButtonURL{Text: "Open pipeline", URL: "https://ci.example.com/p/1"},
+			&tg.KeyboardButtonCallback{Text: "Retry", Data: []byte("retry:1")},
+		}},
+		{Buttons: []tg.KeyboardButtonClass{
+			&tg.KeyboardButtonCallback{Text: "Ack", Data: []byte{0xff, 0xfe}},
+			&tg.KeyboardButtonSwitchInline{Text: "Share", Query: "build 1"},
+			&tg.KeyboardButtonBuy{Text: "Pay"},
+		}},
+	}}}
+
+	got := messageButtons(msg)
+	want := []Button{
+		{Kind: ButtonURL, Text: "Open pipeline", Value: "https://ci.example.com/p/1"},
+		{Kind: ButtonCallback, Text: "Retry", Value: "retry:1"},
+		{Kind: ButtonCallback, Text: "Ack"},
+		{Kind: ButtonSwitchInline, Text: "Share", Value: "build 1"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d buttons, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("button %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHasContent_ButtonsOnly(t *testing.T) {
+	// Bot alerts may carry nothing but their buttons.
+	msg := &tg.Message{ReplyMarkup: &tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{
+		{Buttons: []tg.KeyboardButtonClass{&tg.KeyboardButtonURL{Text: "Open incident", URL: "https://status.example.com/1"}}},
+	}}}
+	if !hasContent(msg) {
+		t.Fatal("expected a message with buttons to have content")
+	}
+	// Buttons that are not exported do not count.
+	msg = &tg.Message{ReplyMarkup: &tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{
+		{Buttons: []tg.KeyboardButtonClass{&tg.KeyboardButtonBuy{Text: "Pay"}}},
+	}}}
+	if hasContent(msg) {
+		t.Fatal("expected a message with only unexported buttons to have no content")
+	}
+}
@@ -0,0 +1,238 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// ChatInfo is the metadata of a group or channel shown in export headers.
+type ChatInfo struct {
+	Description     string
+	MemberCount     int
+	LinkedChatID    int64
+	LinkedChatTitle string
+	// SlowModeSeconds is the minimum delay between two messages of a member,
+	// or 0 when slow mode is off.
+	SlowModeSeconds int
+	// Created is the creation date of the chat. For channels the user is a
+	// member of, Telegram reports the date the user joined instead.
+	Created time.Time
+	// Participants lists the senders of the exported messages. It is only
+	// set for groups.
+	Participants []Participant
+}
+
+// Participant describes one sender in a group.
+type Participant struct {
+	ID       int64
+	Name     string
+	Username string
+	Bot      bool
+	// AdminTitle is the custom admin title, "admin" or "owner" for
+	// administrators and empty for regular members.
+	AdminTitle string
+}
+
+// GetChatInfo fetches the metadata of a group or channel. For groups, it
+// also builds the participant directory of senderIDs with names resolved by
+// names. Private chats have no metadata and return nil.
+func (c *Client) GetChatInfo(ctx context.Context, chat Chat, senderIDs []int64, names NameSource) (*ChatInfo, error) {
+	if chat.IsUser {
+		return nil, nil
+	}
+	if chat.IsChannel {
+		return c.channelInfo(ctx, chat.ID, senderIDs, names)
+	}
+	return c.groupInfo(ctx, chat.ID, senderIDs, names)
+}
+
+func (c *Client) channelInfo(ctx context.Context, chatID int64, senderIDs []int64, names NameSource) (*ChatInfo, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+	peer, ok := inputPeer.(*tg.InputPeerChannel)
+	if !ok {
+		return nil, fmt.Errorf("peer %d is not a channel", chatID)
+	}
+	channel := &tg.InputChannel{ChannelID: peer.ChannelID, AccessHash: peer.AccessHash}
+
+	full, err := c.ctx.Raw.ChannelsGetFullChannel(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get full channel: %w", err)
+	}
+	c.rememberUsers(full.Users)
+	channelFull, ok := full.FullChat.(*tg.ChannelFull)
+	if !ok {
+		return nil, fmt.Errorf("unexpected full chat type %T", full.FullChat)
+	}
+
+	info := &ChatInfo{Description: channelFull.About}
+	info.MemberCount, _ = channelFull.GetParticipantsCount()
+	info.SlowModeSeconds, _ = channelFull.GetSlowmodeSeconds()
+	info.LinkedChatID, _ = channelFull.GetLinkedChatID()
+
+	megagroup := false
+	for _, ch := range full.Chats {
+		item, ok := ch.(*tg.Channel)
+		if !ok {
+			continue
+		}
+		switch item.ID {
+		case chatID:
+			info.Created = time.Unix(int64(item.Date), 0)
+			megagroup = item.Megagroup
+		case info.LinkedChatID:
+			info.LinkedChatTitle = item.Title
+		}
+	}
+	if !megagroup {
+		return info, nil
+	}
+
+	admins, err := c.channelAdmins(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	info.Participants, err = c.participants(ctx, senderIDs, admins, names)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// channelAdmins returns the admin titles of a supergroup by user ID.
+func (c *Client) channelAdmins(ctx context.Context, channel tg.InputChannelClass) (map[int64]string, error) {
+	result, err := c.ctx.Raw.ChannelsGetParticipants(ctx, &tg.ChannelsGetParticipantsRequest{
+		Channel: channel,
+		Filter:  &tg.ChannelParticipantsAdmins{},
+		Limit:   200,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel admins: %w", err)
+	}
+	participants, ok := result.(*tg.ChannelsChannelParticipants)
+	if !ok {
+		return nil, nil
+	}
+	c.rememberUsers(participants.Users)
+
+	admins := make(map[int64]string)
+	for _, p := range participants.Participants {
+		switch item := p.(type) {
+		case *tg.ChannelParticipantCreator:
+			admins[item.UserID] = adminTitle(item.Rank, "owner")
+		case *tg.ChannelParticipantAdmin:
+			admins[item.UserID] = adminTitle(item.Rank, "admin")
+		}
+	}
+	return admins, nil
+}
+
+func (c *Client) groupInfo(ctx context.Context, chatID int64, senderIDs []int64, names NameSource) (*ChatInfo, error) {
+	full, err := c.ctx.Raw.MessagesGetFullChat(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get full chat: %w", err)
+	}
+	c.rememberUsers(full.Users)
+	chatFull, ok := full.FullChat.(*tg.ChatFull)
+	if !ok {
+		return nil, fmt.Errorf("unexpected full chat type %T", full.FullChat)
+	}
+
+	info := &ChatInfo{Description: chatFull.About}
+	for _, ch := range full.Chats {
+		if item, ok := ch.(*tg.Chat); ok && item.ID == chatID {
+			info.MemberCount = item.ParticipantsCount
+			info.Created = time.Unix(int64(item.Date), 0)
+		}
+	}
+
+	admins := make(map[int64]string)
+	if participants, ok := chatFull.Participants.(*tg.ChatParticipants); ok {
+		for _, p := range participants.Participants {
+			switch item := p.(type) {
+			case *tg.ChatParticipantCreator:
+				admins[item.UserID] = "owner"
+			case *tg.ChatParticipantAdmin:
+				admins[item.UserID] = "admin"
+			}
+		}
+	}
+	info.Participants, err = c.participants(ctx, senderIDs, admins, names)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// participants builds the directory entries of senderIDs in order. Users
+// not seen in earlier responses are looked up in one request; senders that
+// cannot be resolved keep only their ID.
+func (c *Client) participants(ctx context.Context, senderIDs []int64, admins map[int64]string, names NameSource) ([]Participant, error) {
+	var missing []tg.InputUserClass
+	for _, id := range senderIDs {
+		if c.cachedUser(id) != nil {
+			continue
+		}
+		if peer, ok := c.ctx.PeerStorage.GetInputPeerById(id).(*tg.InputPeerUser); ok {
+			missing = append(missing, &tg.InputUser{UserID: peer.UserID, AccessHash: peer.AccessHash})
+		}
+	}
+	if len(missing) > 0 {
+		users, err := c.ctx.Raw.UsersGetUsers(ctx, missing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users: %w", err)
+		}
+		c.rememberUsers(users)
+	}
+
+	result := make([]Participant, 0, len(senderIDs))
+	for _, id := range senderIDs {
+		participant := Participant{ID: id, Name: c.SenderName(id, names), AdminTitle: admins[id]}
+		if user := c.cachedUser(id); user != nil {
+			participant.Username = user.Username
+			participant.Bot = user.Bot
+		}
+		result = append(result, participant)
+	}
+	return result, nil
+}
+
+// rememberUsers caches users from API responses, so participant names can
+// be resolved without extra requests.
+func (c *Client) rememberUsers(users []tg.UserClass) {
+	c.usersMu.Lock()
+	defer c.usersMu.Unlock()
+	for _, u := range users {
+		user, ok := u.(*tg.User)
+		if !ok {
+			continue
+		}
+		if c.userCache == nil {
+			c.userCache = make(map[int64]*tg.User)
+		}
+		c.userCache[user.ID] = user
+	}
+}
+
+func (c *Client) cachedUser(id int64) *tg.User {
+	c.usersMu.Lock()
+	defer c.usersMu.Unlock()
+	return c.userCache[id]
+}
+
+func userDisplayName(user *tg.User) string {
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+func adminTitle(rank, fallback string) string {
+	if rank != "" {
+		return rank
+	}
+	return fallback
+}
@@ -0,0 +1,29 @@
+package telegram
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestParticipants_FromCachedUsers(t *testing.T) {
+	client := &Client{}
+	client.rememberUsers([]tg.UserClass{
+		&tg.User{ID: 10, FirstName: "Jane", LastName: "Doe", Username: "jane"},
+		&tg.User{ID: 11, FirstName: "CI", Username: "ci_bot", Bot: true},
+	})
+
+	got, err := client.participants(context.Background(), []int64{11, 10}, map[int64]string{10: "owner"}, NamesProfile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Participant{
+		{ID: 11, Name: "CI", Username: "ci_bot", Bot: true},
+		{ID: 10, Name: "Jane Doe", Username: "jane", AdminTitle: "owner"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
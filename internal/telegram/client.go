@@ -2,11 +2,13 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/gotd/td/bin"
@@ -30,26 +32,63 @@ type Client struct {
 	ctx          *ext.Context
 	peerCache    map[int64]tg.InputPeerClass
 	channelCache map[int64]*tg.Channel // For forum operations
+
+	// userCache holds users seen in message pages and contacts holds the
+	// address book. Partitioned fetches fill the cache concurrently, so
+	// both are guarded by usersMu.
+	usersMu   sync.Mutex
+	userCache map[int64]*tg.User
+	contacts  map[int64]Contact
+	// contactsPath overrides where the address book is cached.
+	contactsPath string
 }
 
 type Chat struct {
-	ID           int64
-	Title        string
-	UnreadCount  int
-	IsChannel    bool
-	IsForum      bool
-	IsUser       bool
-	IsBot        bool
+	ID          int64
+	Title       string
+	UnreadCount int
+	IsChannel   bool
+	IsForum     bool
+	IsUser      bool
+	IsBot       bool
+	// IsSelf marks the user's own chat, listed as Saved Messages.
+	IsSelf bool
+	// IsContact marks users in the address book and IsBroadcast channels
+	// as opposed to supergroups.
+	IsContact   bool
+	IsBroadcast bool
+	// Muted is set while notifications of the chat are muted and Archived
+	// for chats in the archive.
+	Muted        bool
+	Archived     bool
 	LastReadID   int
 	TopMessageID int
+	// UnreadMentions and UnreadReactions count mentions of the user and
+	// reactions to the user's messages that were not seen yet.
+	UnreadMentions  int
+	UnreadReactions int
+	// Forbidden is set when the user was kicked or banned, Left when the
+	// user left the chat and Deactivated for basic groups that were
+	// upgraded or deleted. Restricted chats are blocked by Telegram for
+	// RestrictionReason.
+	Forbidden         bool
+	Left              bool
+	Deactivated       bool
+	Restricted        bool
+	RestrictionReason string
 }
 
 type Topic struct {
-	ID           int
-	Title        string
-	UnreadCount  int
-	LastReadID   int
-	TopMessageID int
+	ID              int
+	Title           string
+	UnreadCount     int
+	LastReadID      int
+	TopMessageID    int
+	UnreadMentions  int
+	UnreadReactions int
+	// TopMessageDate is the date of the newest message in the topic, zero
+	// when the response did not include it.
+	TopMessageDate time.Time
 }
 
 type Message struct {
@@ -57,6 +96,53 @@ type Message struct {
 	Date     time.Time
 	Text     string
 	SenderID int64
+	// SenderName is the display name of the sender, set before export.
+	SenderName string
+	Reactions  []Reaction
+	// ReplyToID is the ID of the message this one replies to in the same
+	// chat, or 0.
+	ReplyToID int
+	// Mentioned and ReplyToMe mark messages that mention the user or
+	// reply to one of the user's messages.
+	Mentioned bool
+	ReplyToMe bool
+	// Poll is set for messages that carry a poll.
+	Poll *Poll
+	// Media is set for link previews, locations, contacts and other
+	// attachments that can be rendered as text.
+	Media *Media
+	// Buttons lists the inline keyboard buttons of bot messages.
+	Buttons []Button
+	// Views, Forwards and Replies are engagement counters of channel
+	// posts; Replies counts comments in the linked discussion group.
+	Views    int
+	Forwards int
+	Replies  int
+	// Event is set for marker entries that are not messages, such as
+	// EventMigration; Text then describes the event.
+	Event string
+	// Forward describes the origin of forwarded messages.
+	Forward *Forward
+	// Group names the section a message is listed under in exports that
+	// combine several sources, e.g. the source of a Saved Messages item.
+	Group string
+	// Tags lists the Saved Messages tags of the message.
+	Tags []string
+	// ChatID, ChatTitle and Link identify where a message was found in
+	// exports that combine several chats; Link is empty for chats without
+	// permalinks.
+	ChatID    int64
+	ChatTitle string
+	Link      string
+	// AdminAction is set for admin log entries, e.g. AdminActionBan; the
+	// sender is the acting admin and Text describes the action.
+	AdminAction string
+}
+
+// Reaction is one reaction on a message with the number of users who left it.
+type Reaction struct {
+	Emoji string
+	Count int
 }
 
 type ProgressUpdate struct {
@@ -64,10 +150,30 @@ type ProgressUpdate struct {
 	Parsed  int
 	Scanned int
 	Batch   int
+	// Cursor is set after each completed batch and describes everything
+	// fetched so far, so callers can persist it and resume later.
+	Cursor *Cursor
+}
+
+// Cursor records how far a paged fetch has progressed.
+// Passing it back to a fetch method continues paging from OffsetID
+// with Messages already collected.
+type Cursor struct {
+	OffsetID int
+	Messages []Message
+	// Until is the exact end of the range of a date range fetch. A resumed
+	// fetch keeps it instead of its own, e.g. a later "now", so it neither
+	// misses nor adds messages compared to an uninterrupted fetch.
+	Until time.Time
 }
 
 type ProgressFunc func(ProgressUpdate)
 
+// ErrFetchCanceled is returned when a fetch is interrupted by context
+// cancellation. The messages returned alongside it are the ones collected
+// before the interruption.
+var ErrFetchCanceled = errors.New("fetch canceled")
+
 func reportProgress(progress ProgressFunc, update ProgressUpdate) {
 	if progress != nil {
 		progress(update)
@@ -217,6 +323,9 @@ func (c *Client) GetDialogs(ctx context.Context) ([]Chat, error) {
 	return parsedDialogs, nil
 }
 
+// archiveFolderID is the peer folder of archived chats.
+const archiveFolderID = 1
+
 func (c *Client) processDialogs(dialogs []tg.DialogClass, chats []tg.ChatClass, users []tg.UserClass) []Chat {
 	chatMap := make(map[int64]tg.ChatClass)
 	for _, ch := range chats {
@@ -224,6 +333,10 @@ func (c *Client) processDialogs(dialogs []tg.DialogClass, chats []tg.ChatClass,
 		switch item := ch.(type) {
 		case *tg.Chat:
 			c.peerCache[item.ID] = &tg.InputPeerChat{ChatID: item.ID}
+		case *tg.ChatForbidden:
+			c.peerCache[item.ID] = &tg.InputPeerChat{ChatID: item.ID}
+		case *tg.ChannelForbidden:
+			c.peerCache[item.ID] = &tg.InputPeerChannel{ChannelID: item.ID, AccessHash: item.AccessHash}
 		case *tg.Channel:
 			c.peerCache[item.ID] = &tg.InputPeerChannel{ChannelID: item.ID, AccessHash: item.AccessHash}
 			c.channelCache[item.ID] = item // Cache for forum operations
@@ -252,6 +365,10 @@ func (c *Client) processDialogs(dialogs []tg.DialogClass, chats []tg.ChatClass,
 		var isForum bool
 		var isUser bool
 		var isBot bool
+		var isSelf bool
+		var isContact bool
+		var isBroadcast bool
+		var access Chat
 
 		switch p := dlg.Peer.(type) {
 		case *tg.PeerUser:
@@ -264,7 +381,12 @@ func (c *Client) processDialogs(dialogs []tg.DialogClass, chats []tg.ChatClass,
 					if user.Username != "" {
 						title += " (@" + user.Username + ")"
 					}
+					if user.Self {
+						title = SavedMessagesTitle
+						isSelf = true
+					}
 					isBot = user.Bot
+					isContact = user.Contact
 				}
 			}
 		case *tg.PeerChat:
@@ -273,6 +395,11 @@ func (c *Client) processDialogs(dialogs []tg.DialogClass, chats []tg.ChatClass,
 				switch chat := ch.(type) {
 				case *tg.Chat:
 					title = chat.Title
+					access.Left = chat.Left
+					access.Deactivated = chat.Deactivated
+				case *tg.ChatForbidden:
+					title = chat.Title
+					access.Forbidden = true
 				}
 			}
 		case *tg.PeerChannel:
@@ -283,6 +410,12 @@ func (c *Client) processDialogs(dialogs []tg.DialogClass, chats []tg.ChatClass,
 				case *tg.Channel:
 					title = channel.Title
 					isForum = channel.Forum
+					isBroadcast = channel.Broadcast
+					access.Left = channel.Left
+					access.RestrictionReason, access.Restricted = restrictionReason(channel)
+				case *tg.ChannelForbidden:
+					title = channel.Title
+					access.Forbidden = true
 				}
 			}
 		}
@@ -292,21 +425,33 @@ func (c *Client) processDialogs(dialogs []tg.DialogClass, chats []tg.ChatClass,
 		}
 
 		results = append(results, Chat{
-			ID:           peerID,
-			Title:        title,
-			UnreadCount:  dlg.UnreadCount,
-			IsChannel:    isChannel,
-			IsForum:      isForum,
-			IsUser:       isUser,
-			IsBot:        isBot,
-			LastReadID:   dlg.ReadInboxMaxID,
-			TopMessageID: dlg.TopMessage,
+			ID:                peerID,
+			Title:             title,
+			UnreadCount:       dlg.UnreadCount,
+			IsChannel:         isChannel,
+			IsForum:           isForum,
+			IsUser:            isUser,
+			IsBot:             isBot,
+			IsSelf:            isSelf,
+			IsContact:         isContact,
+			IsBroadcast:       isBroadcast,
+			Muted:             dlg.NotifySettings.MuteUntil > int(time.Now().Unix()),
+			Archived:          dlg.FolderID == archiveFolderID,
+			LastReadID:        dlg.ReadInboxMaxID,
+			TopMessageID:      dlg.TopMessage,
+			UnreadMentions:    dlg.UnreadMentionsCount,
+			UnreadReactions:   dlg.UnreadReactionsCount,
+			Forbidden:         access.Forbidden,
+			Left:              access.Left,
+			Deactivated:       access.Deactivated,
+			Restricted:        access.Restricted,
+			RestrictionReason: access.RestrictionReason,
 		})
 	}
 	return results
 }
 
-func (c *Client) GetUnreadMessages(ctx context.Context, chatID int64, lastReadID int, progress ProgressFunc) ([]Message, error) {
+func (c *Client) GetUnreadMessages(ctx context.Context, chatID int64, lastReadID int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
 	inputPeer, ok := c.peerCache[chatID]
 	if !ok {
 		// Fallback to storage if not in cache (though unlikely for dialogs)
@@ -323,6 +468,7 @@ func (c *Client) GetUnreadMessages(ctx context.Context, chatID int64, lastReadID
 		"unread",
 		time.Time{},
 		false,
+		resume,
 		func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
 			req := &tg.MessagesGetHistoryRequest{
 				Peer:     inputPeer,
@@ -335,7 +481,7 @@ func (c *Client) GetUnreadMessages(ctx context.Context, chatID int64, lastReadID
 			if msg.ID <= lastReadID {
 				return false, true // Stop
 			}
-			if msg.Message == "" || msg.Out {
+			if !hasContent(msg) || msg.Out {
 				return false, false // Skip
 			}
 			return true, false // Process
@@ -386,7 +532,8 @@ func (c *Client) MarkAsRead(ctx context.Context, chat Chat, maxID int) error {
 	return nil
 }
 
-// GetForumTopics fetches all topics from a forum with their unread counts.
+// GetForumTopics fetches all topics from a forum with their unread counts,
+// page by page.
 func (c *Client) GetForumTopics(ctx context.Context, chatID int64) ([]Topic, error) {
 	inputPeer, ok := c.peerCache[chatID]
 	if !ok {
@@ -396,34 +543,62 @@ func (c *Client) GetForumTopics(ctx context.Context, chatID int64) ([]Topic, err
 		return nil, fmt.Errorf("peer %d not found", chatID)
 	}
 
-	topics, err := c.ctx.Raw.MessagesGetForumTopics(ctx, &tg.MessagesGetForumTopicsRequest{
-		Peer:  inputPeer,
-		Limit: 100,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get forum topics: %w", err)
-	}
+	const batchSize = 100
+	request := &tg.MessagesGetForumTopicsRequest{Peer: inputPeer, Limit: batchSize}
 
 	var result []Topic
-	for _, t := range topics.Topics {
-		topic, ok := t.(*tg.ForumTopic)
-		if !ok {
-			continue
+	seen := make(map[int]bool)
+	for {
+		page, err := c.ctx.Raw.MessagesGetForumTopics(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get forum topics: %w", err)
+		}
+		dates := make(map[int]int, len(page.Messages))
+		for _, msg := range page.Messages {
+			if m, ok := msg.(*tg.Message); ok {
+				dates[m.ID] = m.Date
+			}
 		}
-		result = append(result, Topic{
-			ID:           topic.ID,
-			Title:        topic.Title,
-			UnreadCount:  topic.UnreadCount,
-			LastReadID:   topic.ReadInboxMaxID,
-			TopMessageID: topic.TopMessage,
-		})
-	}
 
-	return result, nil
+		var last *tg.ForumTopic
+		for _, t := range page.Topics {
+			topic, ok := t.(*tg.ForumTopic)
+			if !ok {
+				continue
+			}
+			last = topic
+			// Pinned topics are listed again on later pages.
+			if seen[topic.ID] {
+				continue
+			}
+			seen[topic.ID] = true
+			var topDate time.Time
+			if date, ok := dates[topic.TopMessage]; ok {
+				topDate = time.Unix(int64(date), 0)
+			}
+			result = append(result, Topic{
+				ID:              topic.ID,
+				Title:           topic.Title,
+				UnreadCount:     topic.UnreadCount,
+				LastReadID:      topic.ReadInboxMaxID,
+				TopMessageID:    topic.TopMessage,
+				UnreadMentions:  topic.UnreadMentionsCount,
+				UnreadReactions: topic.UnreadReactionsCount,
+				TopMessageDate:  topDate,
+			})
+		}
+
+		if last == nil || len(page.Topics) < batchSize || len(result) >= page.Count {
+			return result, nil
+		}
+		request.OffsetTopic = last.ID
+		request.OffsetID = last.TopMessage
+		request.OffsetDate = dates[last.TopMessage]
+	}
 }
 
 // GetTopicMessages fetches unread messages from a specific topic.
-func (c *Client) GetTopicMessages(ctx context.Context, chatID int64, topicID int, lastReadID int, progress ProgressFunc) ([]Message, error) {
+func (c *Client) GetTopicMessages(ctx context.Context, chatID int64, topicID int, lastReadID int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
 	inputPeer, ok := c.peerCache[chatID]
 	if !ok {
 		inputPeer = c.ctx.PeerStorage.GetInputPeerById(chatID)
@@ -438,6 +613,7 @@ func (c *Client) GetTopicMessages(ctx context.Context, chatID int64, topicID int
 		"topic-unread",
 		time.Time{},
 		false,
+		resume,
 		func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
 			if topicID == 1 {
 				req := &tg.MessagesGetHistoryRequest{
@@ -466,7 +642,7 @@ func (c *Client) GetTopicMessages(ctx context.Context, chatID int64, topicID int
 			if msg.ID <= lastReadID {
 				return false, true // Stop
 			}
-			if msg.Message == "" || msg.Out {
+			if !hasContent(msg) || msg.Out {
 				return false, false // Skip
 			}
 			return true, false // Process
@@ -496,8 +672,10 @@ func (c *Client) MarkTopicAsRead(ctx context.Context, chatID int64, topicID int,
 	return nil
 }
 
-// GetMessagesByDate fetches messages within a specific date range.
-func (c *Client) GetMessagesByDate(ctx context.Context, chatID int64, since, until time.Time, progress ProgressFunc) ([]Message, error) {
+// GetMessagesByDate fetches messages within a specific date range. For
+// supergroups upgraded from a basic group, the fetch continues into the
+// original group after a migration marker.
+func (c *Client) GetMessagesByDate(ctx context.Context, chatID int64, since, until time.Time, resume *Cursor, progress ProgressFunc) ([]Message, error) {
 	inputPeer, ok := c.peerCache[chatID]
 	if !ok {
 		inputPeer = c.ctx.PeerStorage.GetInputPeerById(chatID)
@@ -506,39 +684,25 @@ func (c *Client) GetMessagesByDate(ctx context.Context, chatID int64, since, unt
 		return nil, fmt.Errorf("peer %d not found", chatID)
 	}
 
-	return c.fetchMessages(
+	until = resumeUntil(resume, until)
+	messages, err := c.fetchMessages(
 		ctx,
 		progress,
 		"date-range",
 		until,
 		true,
-		func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
-			req := &tg.MessagesGetHistoryRequest{
-				Peer:       inputPeer,
-				Limit:      limit,
-				OffsetID:   offsetID,
-				OffsetDate: offsetDate,
-			}
-			return c.ctx.Raw.MessagesGetHistory(ctx, req)
-		},
-		func(msg *tg.Message) (bool, bool) {
-			msgTime := time.Unix(int64(msg.Date), 0)
-			if msgTime.Before(since) {
-				return false, true // Stop (tooOld)
-			}
-			if msgTime.After(until) {
-				return false, false // Skip (tooNew)
-			}
-			if msg.Message == "" || msg.Out {
-				return false, false // Skip
-			}
-			return true, false // Process
-		},
+		resume,
+		historyFetchFunc(ctx, c, inputPeer),
+		dateRangeFilter(since, until, false),
 	)
+	if err != nil {
+		return messages, err
+	}
+	return c.appendLegacyHistory(ctx, inputPeer, since, until, progress, messages)
 }
 
 // GetTopicMessagesByDate fetches topic messages within a specific date range.
-func (c *Client) GetTopicMessagesByDate(ctx context.Context, chatID int64, topicID int, since, until time.Time, progress ProgressFunc) ([]Message, error) {
+func (c *Client) GetTopicMessagesByDate(ctx context.Context, chatID int64, topicID int, since, until time.Time, resume *Cursor, progress ProgressFunc) ([]Message, error) {
 	inputPeer, ok := c.peerCache[chatID]
 	if !ok {
 		inputPeer = c.ctx.PeerStorage.GetInputPeerById(chatID)
@@ -547,54 +711,206 @@ func (c *Client) GetTopicMessagesByDate(ctx context.Context, chatID int64, topic
 		return nil, fmt.Errorf("peer %d not found", chatID)
 	}
 
+	until = resumeUntil(resume, until)
 	return c.fetchMessages(
 		ctx,
 		progress,
 		"topic-date-range",
 		until,
 		true,
-		func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
-			if topicID == 1 {
-				req := &tg.MessagesGetHistoryRequest{
-					Peer:       inputPeer,
-					Limit:      limit,
-					OffsetID:   offsetID,
-					OffsetDate: offsetDate,
-				}
-				return c.ctx.Raw.MessagesGetHistory(ctx, req)
-			}
-			req := &tg.MessagesGetRepliesRequest{
-				Peer:       inputPeer,
-				MsgID:      topicID,
-				Limit:      limit,
-				OffsetID:   offsetID,
-				OffsetDate: offsetDate,
-			}
-			return c.ctx.Raw.MessagesGetReplies(ctx, req)
-		},
-		func(msg *tg.Message) (bool, bool) {
-			if topicID == 1 {
-				if msg.ReplyTo != nil {
-					if reply, ok := msg.ReplyTo.(*tg.MessageReplyHeader); ok && reply.ReplyToTopID != 0 {
-						return false, false
-					}
-				}
-			}
-			msgTime := time.Unix(int64(msg.Date), 0)
-			if msgTime.Before(since) {
-				return false, true // Stop (tooOld)
-			}
-			if msgTime.After(until) {
-				return false, false // Skip (tooNew)
-			}
-			if msg.Message == "" || msg.Out {
-				return false, false // Skip
-			}
-			return true, false // Process
-		},
+		resume,
+		topicFetchFunc(ctx, c, inputPeer, topicID),
+		dateRangeFilter(since, until, topicID == 1),
+	)
+}
+
+// GetLastMessages fetches the newest limit text messages.
+func (c *Client) GetLastMessages(ctx context.Context, chatID int64, limit int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchMessages(
+		ctx,
+		progress,
+		"last",
+		time.Time{},
+		false,
+		resume,
+		historyFetchFunc(ctx, c, inputPeer),
+		lastMessagesFilter(limit, resume, false),
+	)
+}
+
+// GetTopicLastMessages fetches the newest limit text messages of a topic.
+func (c *Client) GetTopicLastMessages(ctx context.Context, chatID int64, topicID int, limit int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchMessages(
+		ctx,
+		progress,
+		"topic-last",
+		time.Time{},
+		false,
+		resume,
+		topicFetchFunc(ctx, c, inputPeer, topicID),
+		lastMessagesFilter(limit, resume, topicID == 1),
 	)
 }
 
+// GetMessagesByIDRange fetches text messages with IDs from fromID to toID
+// inclusive. A toID of 0 means up to the newest message.
+func (c *Client) GetMessagesByIDRange(ctx context.Context, chatID int64, fromID, toID int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchIDRange(ctx, progress, "id-range", toID, resume,
+		historyFetchFunc(ctx, c, inputPeer),
+		idRangeFilter(fromID, false),
+	)
+}
+
+// GetTopicMessagesByIDRange fetches topic messages with IDs from fromID to toID inclusive.
+func (c *Client) GetTopicMessagesByIDRange(ctx context.Context, chatID int64, topicID int, fromID, toID int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchIDRange(ctx, progress, "topic-id-range", toID, resume,
+		topicFetchFunc(ctx, c, inputPeer, topicID),
+		idRangeFilter(fromID, topicID == 1),
+	)
+}
+
+// GetContextMessages fetches up to count text messages at or before
+// lastReadID, newest first, to show what unread messages are answering.
+func (c *Client) GetContextMessages(ctx context.Context, chatID int64, lastReadID int, count int, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if lastReadID <= 0 {
+		return nil, nil
+	}
+
+	return c.fetchIDRange(ctx, progress, "context", lastReadID, nil,
+		historyFetchFunc(ctx, c, inputPeer),
+		lastMessagesFilter(count, nil, false),
+	)
+}
+
+// GetTopicContextMessages is GetContextMessages for a forum topic.
+func (c *Client) GetTopicContextMessages(ctx context.Context, chatID int64, topicID int, lastReadID int, count int, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if lastReadID <= 0 {
+		return nil, nil
+	}
+
+	return c.fetchIDRange(ctx, progress, "topic-context", lastReadID, nil,
+		topicFetchFunc(ctx, c, inputPeer, topicID),
+		lastMessagesFilter(count, nil, topicID == 1),
+	)
+}
+
+// fetchIDRange is fetchMessages starting just above toID instead of at the
+// newest message.
+func (c *Client) fetchIDRange(
+	ctx context.Context,
+	progress ProgressFunc,
+	phase string,
+	toID int,
+	resume *Cursor,
+	fetch func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error),
+	filter func(msg *tg.Message) (process bool, stop bool),
+) ([]Message, error) {
+	offsetID, allMessages := resumePosition(progress, resume)
+	if offsetID == 0 && toID > 0 {
+		offsetID = toID + 1
+	}
+	return c.pageMessages(ctx, progress, phase, offsetID, 0, allMessages, true, fetch, filter)
+}
+
+func lastMessagesFilter(limit int, resume *Cursor, generalTopic bool) func(msg *tg.Message) (bool, bool) {
+	count := 0
+	if resume != nil {
+		count = len(resume.Messages)
+	}
+	return func(msg *tg.Message) (bool, bool) {
+		if count >= limit {
+			return false, true // Stop
+		}
+		if generalTopic && isOtherTopicMessage(msg) {
+			return false, false
+		}
+		if !hasContent(msg) || msg.Out {
+			return false, false // Skip
+		}
+		count++
+		return true, false // Process
+	}
+}
+
+func idRangeFilter(fromID int, generalTopic bool) func(msg *tg.Message) (bool, bool) {
+	return func(msg *tg.Message) (bool, bool) {
+		if msg.ID < fromID {
+			return false, true // Stop
+		}
+		if generalTopic && isOtherTopicMessage(msg) {
+			return false, false
+		}
+		if !hasContent(msg) || msg.Out {
+			return false, false // Skip
+		}
+		return true, false // Process
+	}
+}
+
+func historyFetchFunc(ctx context.Context, c *Client, inputPeer tg.InputPeerClass) func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+	page := historyPageFunc(ctx, c, inputPeer)
+	return func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+		return page(offsetID, offsetDate, 0, limit)
+	}
+}
+
+func topicFetchFunc(ctx context.Context, c *Client, inputPeer tg.InputPeerClass, topicID int) func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+	page := topicPageFunc(ctx, c, inputPeer, topicID)
+	return func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+		return page(offsetID, offsetDate, 0, limit)
+	}
+}
+
+// dateRangeFilter matches messages between since and until while paging
+// newest first. In the General topic (generalTopic) messages belonging to
+// other topics are skipped.
+func dateRangeFilter(since, until time.Time, generalTopic bool) func(msg *tg.Message) (bool, bool) {
+	return func(msg *tg.Message) (bool, bool) {
+		if generalTopic && isOtherTopicMessage(msg) {
+			return false, false
+		}
+		msgTime := time.Unix(int64(msg.Date), 0)
+		if msgTime.Before(since) {
+			return false, true // Stop (tooOld)
+		}
+		if msgTime.After(until) {
+			return false, false // Skip (tooNew)
+		}
+		if !hasContent(msg) || msg.Out {
+			return false, false // Skip
+		}
+		return true, false // Process
+	}
+}
+
 func resolveSenderID(fromID tg.PeerClass) int64 {
 	if fromID == nil {
 		return 0
@@ -629,25 +945,94 @@ func (c *Client) fetchMessages(
 	phase string,
 	until time.Time,
 	useOffsetDate bool,
+	resume *Cursor,
 	fetch func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error),
 	filter func(msg *tg.Message) (process bool, stop bool),
 ) ([]Message, error) {
-	var allMessages []Message
-	offsetID := 0
+	offsetID, allMessages := resumePosition(progress, resume)
+	progress = cursorUntil(progress, until)
+
+	offsetDate := 0
+	if useOffsetDate && offsetID == 0 && !until.IsZero() {
+		// Jump close to the end of the requested range, then page backwards.
+		offsetDate = int(until.Unix())
+		reportProgress(progress, ProgressUpdate{
+			Phase: fmt.Sprintf("jumped to date %s", until.Format("2006-01-02")),
+		})
+	}
+
+	return c.pageMessages(ctx, progress, phase, offsetID, offsetDate, allMessages, true, fetch, filter)
+}
+
+// resumePosition returns the offset and messages to continue from, or zero
+// and nil when there is nothing to resume.
+func resumePosition(progress ProgressFunc, resume *Cursor) (int, []Message) {
+	if resume == nil || resume.OffsetID == 0 {
+		return 0, nil
+	}
+	messages := append([]Message(nil), resume.Messages...)
+	reportProgress(progress, ProgressUpdate{
+		Phase:  fmt.Sprintf("resumed from message %d", resume.OffsetID),
+		Parsed: len(messages),
+	})
+	return resume.OffsetID, messages
+}
+
+// resumeUntil returns the end of the range a resumed fetch started with,
+// or until for new fetches.
+func resumeUntil(resume *Cursor, until time.Time) time.Time {
+	if resume == nil || resume.Until.IsZero() {
+		return until
+	}
+	return resume.Until
+}
+
+// cursorUntil records until in the cursors reported to progress.
+func cursorUntil(progress ProgressFunc, until time.Time) ProgressFunc {
+	if progress == nil || until.IsZero() {
+		return progress
+	}
+	return func(update ProgressUpdate) {
+		if update.Cursor != nil {
+			cursor := *update.Cursor
+			cursor.Until = until
+			update.Cursor = &cursor
+		}
+		progress(update)
+	}
+}
+
+// pageMessages pages backwards from offsetID (or offsetDate on the first
+// page) and appends matching messages to collected. With checkpoint set,
+// every batch reports a Cursor describing everything collected so far.
+func (c *Client) pageMessages(
+	ctx context.Context,
+	progress ProgressFunc,
+	phase string,
+	offsetID int,
+	offsetDate int,
+	collected []Message,
+	checkpoint bool,
+	fetch func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error),
+	filter func(msg *tg.Message) (process bool, stop bool),
+) ([]Message, error) {
+	allMessages := collected
 	batchSize := 100
+	// Replies are recognized across pages once the whole range was paged.
+	outgoing := make(map[int]bool)
 
 	for {
-		offsetDate := 0
-		if useOffsetDate && offsetID == 0 && !until.IsZero() {
-			// Jump close to the end of the requested range, then page backwards.
-			offsetDate = int(until.Unix())
-			reportProgress(progress, ProgressUpdate{
-				Phase: fmt.Sprintf("jumped to date %s", until.Format("2006-01-02")),
-			})
+		if err := ctx.Err(); err != nil {
+			markRepliesToMe(allMessages, outgoing)
+			return allMessages, fmt.Errorf("%w: %w", ErrFetchCanceled, err)
 		}
 
 		result, err := fetch(offsetID, offsetDate, batchSize)
 		if err != nil {
+			if ctx.Err() != nil {
+				markRepliesToMe(allMessages, outgoing)
+				return allMessages, fmt.Errorf("%w: %w", ErrFetchCanceled, ctx.Err())
+			}
 			return nil, err
 		}
 
@@ -656,43 +1041,55 @@ func (c *Client) fetchMessages(
 			break
 		}
 
-		batchMessages, lastID, stop := c.processMessageBatch(ctx, msgs, users, filter)
+		addOutgoingIDs(outgoing, msgs)
+		batchMessages, lastID, stop := c.processMessageBatch(ctx, msgs, users, outgoing, filter)
 		allMessages = append(allMessages, batchMessages...)
-		reportProgress(progress, ProgressUpdate{
+		offsetID = lastID
+		if offsetID != 0 {
+			offsetDate = 0
+		}
+		update := ProgressUpdate{
 			Phase:   phase,
 			Parsed:  len(batchMessages),
 			Scanned: len(msgs),
 			Batch:   1,
-		})
+		}
+		if checkpoint {
+			update.Cursor = &Cursor{OffsetID: offsetID, Messages: allMessages}
+		}
+		reportProgress(progress, update)
 
 		if stop {
 			break
 		}
 
-		offsetID = lastID
 		if len(msgs) < batchSize {
 			break
 		}
 	}
 
+	markRepliesToMe(allMessages, outgoing)
 	return allMessages, nil
 }
 
-func (c *Client) processMessageBatch(ctx context.Context, msgs []tg.MessageClass, _ []tg.UserClass,
+func (c *Client) processMessageBatch(ctx context.Context, msgs []tg.MessageClass, users []tg.UserClass, outgoing map[int]bool,
 	filter func(msg *tg.Message) (process bool, stop bool)) ([]Message, int, bool) {
+	c.rememberUsers(users)
 
 	var results []Message
 	var lastID int
 	var stopLoop bool
+	self := c.self()
 
 	for _, m := range msgs {
+		// Service messages still advance the offset, otherwise a page made
+		// only of them would restart paging from the top.
+		lastID = m.GetID()
 		msg, ok := m.(*tg.Message)
 		if !ok {
 			continue
 		}
 
-		lastID = msg.ID
-
 		process, stop := filter(msg)
 		if stop {
 			stopLoop = true
@@ -702,17 +1099,80 @@ func (c *Client) processMessageBatch(ctx context.Context, msgs []tg.MessageClass
 			continue
 		}
 
-		senderID := resolveSenderID(msg.FromID)
-		results = append(results, Message{
-			ID:       msg.ID,
-			Date:     time.Unix(int64(msg.Date), 0),
-			Text:     msg.Message,
-			SenderID: senderID,
-		})
+		message := newMessage(msg)
+		markAttention(&message, msg, self, outgoing)
+		results = append(results, message)
 	}
 	return results, lastID, stopLoop
 }
 
+// hasContent reports whether a message has anything to export: text, a
+// poll, media that renders as text or buttons.
+func hasContent(msg *tg.Message) bool {
+	if msg.Message != "" {
+		return true
+	}
+	if _, ok := msg.Media.(*tg.MessageMediaPoll); ok {
+		return true
+	}
+	return messageMedia(msg) != nil || len(messageButtons(msg)) > 0
+}
+
+func newMessage(msg *tg.Message) Message {
+	views, _ := msg.GetViews()
+	forwards, _ := msg.GetForwards()
+	replies, _ := msg.GetReplies()
+	return Message{
+		ID:        msg.ID,
+		Date:      time.Unix(int64(msg.Date), 0),
+		Text:      msg.Message,
+		SenderID:  resolveSenderID(msg.FromID),
+		Reactions: messageReactions(msg),
+		Poll:      messagePoll(msg),
+		Media:     messageMedia(msg),
+		Buttons:   messageButtons(msg),
+		Forward:   messageForward(msg),
+		Views:     views,
+		Forwards:  forwards,
+		Replies:   replies.Replies,
+	}
+}
+
+func messageReactions(msg *tg.Message) []Reaction {
+	reactions, ok := msg.GetReactions()
+	if !ok || len(reactions.Results) == 0 {
+		return nil
+	}
+	result := make([]Reaction, 0, len(reactions.Results))
+	for _, rc := range reactions.Results {
+		var emoji string
+		switch r := rc.Reaction.(type) {
+		case *tg.ReactionEmoji:
+			emoji = r.Emoticon
+		case *tg.ReactionCustomEmoji:
+			emoji = "custom"
+		case *tg.ReactionPaid:
+			emoji = "⭐"
+		default:
+			continue
+		}
+		result = append(result, Reaction{Emoji: emoji, Count: rc.Count})
+	}
+	return result
+}
+
+// inputPeer looks up a peer in the dialog cache, falling back to storage.
+func (c *Client) inputPeer(chatID int64) (tg.InputPeerClass, error) {
+	inputPeer, ok := c.peerCache[chatID]
+	if !ok {
+		inputPeer = c.ctx.PeerStorage.GetInputPeerById(chatID)
+	}
+	if inputPeer == nil {
+		return nil, fmt.Errorf("peer %d not found", chatID)
+	}
+	return inputPeer, nil
+}
+
 // Helpers for middleware
 
 // Helpers for middleware
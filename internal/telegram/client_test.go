@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -397,6 +398,7 @@ func TestFetchMessages_PaginatesAndReportsProgress(t *testing.T) {
 		"test-phase",
 		time.Time{},
 		false,
+		nil,
 		fetch,
 		filter,
 	)
@@ -446,6 +448,7 @@ func TestFetchMessages_UsesOffsetDateOnFirstPage(t *testing.T) {
 		"phase",
 		until,
 		true,
+		nil,
 		fetch,
 		filter,
 	)
@@ -459,3 +462,261 @@ func TestFetchMessages_UsesOffsetDateOnFirstPage(t *testing.T) {
 		t.Fatalf("unexpected offsetDate: got %d want %d", seenOffsetDates[0], int(until.Unix()))
 	}
 }
+
+func TestFetchMessages_ResumesFromCursor(t *testing.T) {
+	client := &Client{}
+
+	var seenOffsets []int
+	var cursors []Cursor
+	fetch := func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+		seenOffsets = append(seenOffsets, offsetID)
+		if offsetDate != 0 {
+			t.Fatalf("resumed fetch should not jump by date, got offsetDate %d", offsetDate)
+		}
+		return &tg.MessagesMessages{
+			Messages: []tg.MessageClass{
+				&tg.Message{ID: 49, Date: 500, Message: "c"},
+				&tg.Message{ID: 48, Date: 400, Message: "d"},
+			},
+		}, nil
+	}
+	filter := func(msg *tg.Message) (bool, bool) {
+		return true, false
+	}
+	progress := func(update ProgressUpdate) {
+		if update.Cursor != nil {
+			cursors = append(cursors, *update.Cursor)
+		}
+	}
+
+	resume := &Cursor{
+		OffsetID: 50,
+		Messages: []Message{{ID: 51, Text: "a"}, {ID: 50, Text: "b"}},
+	}
+	until := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	got, err := client.fetchMessages(context.Background(), progress, "phase", until, true, resume, fetch, filter)
+	if err != nil {
+		t.Fatalf("fetchMessages error: %v", err)
+	}
+
+	if len(seenOffsets) != 1 || seenOffsets[0] != 50 {
+		t.Fatalf("expected a single fetch from offset 50, got %v", seenOffsets)
+	}
+	var ids []int
+	for _, msg := range got {
+		ids = append(ids, msg.ID)
+	}
+	if len(ids) != 4 || ids[0] != 51 || ids[1] != 50 || ids[2] != 49 || ids[3] != 48 {
+		t.Fatalf("unexpected message ids: %v", ids)
+	}
+	if len(cursors) != 1 || cursors[0].OffsetID != 48 || len(cursors[0].Messages) != 4 {
+		t.Fatalf("unexpected cursors: %+v", cursors)
+	}
+	if !cursors[0].Until.Equal(until) {
+		t.Fatalf("expected cursors to record the end of the range, got %v", cursors[0].Until)
+	}
+}
+
+func TestResumeUntil(t *testing.T) {
+	started := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+	now := started.Add(3 * time.Hour)
+	if got := resumeUntil(nil, now); !got.Equal(now) {
+		t.Fatalf("expected a new fetch to use its own end, got %v", got)
+	}
+	if got := resumeUntil(&Cursor{OffsetID: 10}, now); !got.Equal(now) {
+		t.Fatalf("expected a cursor without an end to keep the given one, got %v", got)
+	}
+	if got := resumeUntil(&Cursor{OffsetID: 10, Until: started}, now); !got.Equal(started) {
+		t.Fatalf("expected a resumed fetch to end where it started, got %v", got)
+	}
+}
+
+func TestFetchMessages_ReturnsPartialResultsOnCancel(t *testing.T) {
+	client := &Client{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	callCount := 0
+	fetch := func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+		callCount++
+		if callCount > 1 {
+			cancel()
+			return nil, ctx.Err()
+		}
+		msgs := make([]tg.MessageClass, 0, limit)
+		for i := 0; i < limit; i++ {
+			msgs = append(msgs, &tg.Message{ID: 300 - i, Date: 1000 - i, Message: "a"})
+		}
+		return &tg.MessagesMessages{Messages: msgs}, nil
+	}
+	filter := func(msg *tg.Message) (bool, bool) {
+		return true, false
+	}
+
+	got, err := client.fetchMessages(ctx, nil, "phase", time.Time{}, false, nil, fetch, filter)
+	if !errors.Is(err, ErrFetchCanceled) {
+		t.Fatalf("expected ErrFetchCanceled, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error to wrap context.Canceled, got %v", err)
+	}
+	if len(got) != 100 {
+		t.Fatalf("expected 100 partial messages, got %d", len(got))
+	}
+}
+
+func TestFetchMessages_LastMessagesStopsAtLimit(t *testing.T) {
+	client := &Client{}
+	fetch := fakeHistory(500)
+
+	got, err := client.fetchMessages(context.Background(), nil, "last", time.Time{}, false, nil, fetch, lastMessagesFilter(150, nil, false))
+	if err != nil {
+		t.Fatalf("fetchMessages error: %v", err)
+	}
+	if len(got) != 150 {
+		t.Fatalf("expected 150 messages, got %d", len(got))
+	}
+	if got[0].ID != 500 {
+		t.Fatalf("expected newest message first, got %d", got[0].ID)
+	}
+}
+
+func TestFetchIDRange_StartsAboveToID(t *testing.T) {
+	client := &Client{}
+	history := fakeHistory(5000)
+	var seenOffsets []int
+	fetch := func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+		seenOffsets = append(seenOffsets, offsetID)
+		return history(offsetID, offsetDate, limit)
+	}
+
+	got, err := client.fetchIDRange(context.Background(), nil, "id-range", 1250, nil, fetch, idRangeFilter(1200, false))
+	if err != nil {
+		t.Fatalf("fetchIDRange error: %v", err)
+	}
+	if seenOffsets[0] != 1251 {
+		t.Fatalf("expected first offset 1251, got %v", seenOffsets)
+	}
+	for _, msg := range got {
+		if msg.ID < 1200 || msg.ID > 1250 {
+			t.Fatalf("message %d outside requested range", msg.ID)
+		}
+	}
+	if got[0].ID != 1250 || got[len(got)-1].ID != 1200 {
+		t.Fatalf("unexpected bounds: %d..%d", got[len(got)-1].ID, got[0].ID)
+	}
+}
+
+func TestNewMessage_Reactions(t *testing.T) {
+	msg := &tg.Message{ID: 1, Message: "hi", Out: true}
+	msg.SetReactions(tg.MessageReactions{Results: []tg.ReactionCount{
+		{Reaction: &tg.ReactionEmoji{Emoticon: "👍"}, Count: 3},
+		{Reaction: &tg.ReactionCustomEmoji{DocumentID: 42}, Count: 1},
+		{Reaction: &tg.ReactionEmpty{}, Count: 5},
+	}})
+
+	got := newMessage(msg).Reactions
+	want := []Reaction{{Emoji: "👍", Count: 3}, {Emoji: "custom", Count: 1}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected reactions: %+v", got)
+	}
+	if process, _ := textMessageFilter(msg); !process {
+		t.Fatal("expected outgoing message with text to be kept")
+	}
+}
+
+func TestFetchIDRange_ContextBeforeLastRead(t *testing.T) {
+	client := &Client{}
+	history := fakeHistory(500)
+
+	got, err := client.fetchIDRange(context.Background(), nil, "context", 300, nil, history, lastMessagesFilter(5, nil, false))
+	if err != nil {
+		t.Fatalf("fetchIDRange error: %v", err)
+	}
+	want := []int{300, 299, 298, 297, 296}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d messages, got %d", len(want), len(got))
+	}
+	for i, msg := range got {
+		if msg.ID != want[i] {
+			t.Fatalf("message %d: got ID %d, want %d", i, msg.ID, want[i])
+		}
+	}
+}
+
+func TestProcessDialogs_AccessStatus(t *testing.T) {
+	client := &Client{
+		peerCache:    make(map[int64]tg.InputPeerClass),
+		channelCache: make(map[int64]*tg.Channel),
+	}
+
+	dialogs := []tg.DialogClass{
+		&tg.Dialog{Peer: &tg.PeerChat{ChatID: 1}},
+		&tg.Dialog{Peer: &tg.PeerChannel{ChannelID: 2}},
+		&tg.Dialog{Peer: &tg.PeerChat{ChatID: 3}},
+		&tg.Dialog{Peer: &tg.PeerChannel{ChannelID: 4}},
+		&tg.Dialog{Peer: &tg.PeerChannel{ChannelID: 5}},
+	}
+	chats := []tg.ChatClass{
+		&tg.ChatForbidden{ID: 1, Title: "Kicked Group"},
+		&tg.ChannelForbidden{ID: 2, Title: "Banned Channel", AccessHash: 200},
+		&tg.Chat{ID: 3, Title: "Old Group", Deactivated: true},
+		&tg.Channel{ID: 4, Title: "Left Channel", Left: true},
+		&tg.Channel{ID: 5, Title: "Blocked Channel", Restricted: true, RestrictionReason: []tg.RestrictionReason{
+			{Platform: "ios", Reason: "porn", Text: "iOS only"},
+			{Platform: "all", Reason: "copyright", Text: "Blocked for copyright"},
+		}},
+	}
+
+	result := client.processDialogs(dialogs, chats, nil)
+
+	want := []string{"forbidden", "forbidden", "deactivated", "left", "restricted"}
+	for i, chat := range result {
+		if chat.Status() != want[i] {
+			t.Errorf("%s: expected status %q, got %q", chat.Title, want[i], chat.Status())
+		}
+	}
+	if result[0].Title != "Kicked Group" || result[1].Title != "Banned Channel" {
+		t.Errorf("expected forbidden chats to keep their titles, got %q and %q", result[0].Title, result[1].Title)
+	}
+	if result[4].RestrictionReason != "Blocked for copyright" {
+		t.Errorf("expected restriction reason, got %q", result[4].RestrictionReason)
+	}
+	if len(client.peerCache) != 5 {
+		t.Errorf("expected 5 entries in peerCache, got %d", len(client.peerCache))
+	}
+}
+
+func TestChatAccess(t *testing.T) {
+	tests := []struct {
+		chat     Chat
+		exportOK bool
+		readOnly bool
+	}{
+		{chat: Chat{Title: "member"}, exportOK: true},
+		{chat: Chat{Title: "left", Left: true}, exportOK: true, readOnly: true},
+		{chat: Chat{Title: "deactivated", Deactivated: true}, exportOK: true, readOnly: true},
+		{chat: Chat{Title: "forbidden", Forbidden: true}},
+		{chat: Chat{Title: "restricted", Restricted: true, RestrictionReason: "copyright"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.chat.ExportError() == nil; got != tt.exportOK {
+			t.Errorf("%s: ExportError() == nil is %v, want %v", tt.chat.Title, got, tt.exportOK)
+		}
+		if got := tt.chat.ReadOnly(); got != tt.readOnly {
+			t.Errorf("%s: ReadOnly() = %v, want %v", tt.chat.Title, got, tt.readOnly)
+		}
+	}
+}
+
+func TestNewMessage_EngagementMetrics(t *testing.T) {
+	msg := &tg.Message{ID: 1, Message: "post", PeerID: &tg.PeerChannel{ChannelID: 5}}
+	msg.SetViews(1200)
+	msg.SetForwards(15)
+	msg.SetReplies(tg.MessageReplies{Replies: 8})
+
+	got := newMessage(msg)
+	if got.Views != 1200 || got.Forwards != 15 || got.Replies != 8 {
+		t.Fatalf("unexpected metrics: views=%d forwards=%d replies=%d", got.Views, got.Forwards, got.Replies)
+	}
+}
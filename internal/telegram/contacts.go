@@ -0,0 +1,221 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gotd/td/tg"
+)
+
+// NameSource selects how sender display names are resolved.
+type NameSource string
+
+const (
+	// NamesContact prefers the name saved in the user's address book.
+	NamesContact NameSource = "contact"
+	// NamesProfile prefers the name delivered with the messages. For the
+	// user's contacts Telegram already delivers the saved contact name
+	// there, so it only differs from NamesContact when the cached address
+	// book is out of date.
+	NamesProfile NameSource = "profile"
+	// NamesUsername prefers the @username.
+	NamesUsername NameSource = "username"
+)
+
+// defaultContactsPath is where the address book is cached between runs.
+const defaultContactsPath = "session/contacts.json"
+
+// Contact is one address book entry with the name the user saved.
+type Contact struct {
+	UserID    int64  `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+type contactsCache struct {
+	Hash     int64     `json:"hash"`
+	Contacts []Contact `json:"contacts"`
+}
+
+// LoadContacts loads the address book once per session. The cached copy is
+// sent as a hash, so Telegram only returns the contacts when they changed.
+// When the request fails, the cached copy is used for the rest of the
+// session and the error is returned.
+func (c *Client) LoadContacts(ctx context.Context) error {
+	c.usersMu.Lock()
+	loaded := c.contacts != nil
+	c.usersMu.Unlock()
+	if loaded {
+		return nil
+	}
+
+	path := c.contactsPath
+	if path == "" {
+		path = defaultContactsPath
+	}
+	cache, err := readContactsCache(path)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.ctx.Raw.ContactsGetContacts(ctx, cache.Hash)
+	if err != nil {
+		err = fmt.Errorf("failed to get contacts: %w", err)
+	} else if fresh, ok := result.(*tg.ContactsContacts); ok {
+		cache = newContactsCache(fresh)
+		err = writeContactsCache(path, cache)
+	}
+
+	contacts := make(map[int64]Contact, len(cache.Contacts))
+	for _, contact := range cache.Contacts {
+		contacts[contact.UserID] = contact
+	}
+	c.usersMu.Lock()
+	c.contacts = contacts
+	c.usersMu.Unlock()
+	return err
+}
+
+// ResolveUsers fetches the users among ids that are not cached yet, such as
+// the senders of messages restored from a checkpoint, whose pages were
+// fetched in an earlier run. Users without a known access hash are skipped.
+func (c *Client) ResolveUsers(ctx context.Context, ids []int64) error {
+	var missing []tg.InputUserClass
+	for _, id := range ids {
+		if c.cachedUser(id) != nil {
+			continue
+		}
+		peer, err := c.inputPeer(id)
+		if err != nil {
+			continue
+		}
+		if user, ok := peer.(*tg.InputPeerUser); ok {
+			missing = append(missing, &tg.InputUser{UserID: user.UserID, AccessHash: user.AccessHash})
+		}
+	}
+	const batchSize = 100
+	for start := 0; start < len(missing); start += batchSize {
+		users, err := c.ctx.Raw.UsersGetUsers(ctx, missing[start:min(start+batchSize, len(missing))])
+		if err != nil {
+			return fmt.Errorf("failed to get users: %w", err)
+		}
+		c.rememberUsers(users)
+	}
+	return nil
+}
+
+// SenderName returns the display name of a sender, trying source first and
+// falling back to the other sources. Senders that are not known return "".
+func (c *Client) SenderName(id int64, source NameSource) string {
+	c.usersMu.Lock()
+	contact, isContact := c.contacts[id]
+	user := c.userCache[id]
+	c.usersMu.Unlock()
+
+	var contactName, profileName, username string
+	if isContact {
+		contactName = strings.TrimSpace(contact.FirstName + " " + contact.LastName)
+		username = contact.Username
+	}
+	if user != nil {
+		profileName = userDisplayName(user)
+		username = firstNonEmpty(user.Username, username)
+	}
+	if username != "" {
+		username = "@" + username
+	}
+
+	switch source {
+	case NamesProfile:
+		return firstNonEmpty(profileName, contactName, username)
+	case NamesUsername:
+		return firstNonEmpty(username, contactName, profileName)
+	default:
+		return firstNonEmpty(contactName, profileName, username)
+	}
+}
+
+func newContactsCache(result *tg.ContactsContacts) contactsCache {
+	users := make(map[int64]*tg.User, len(result.Users))
+	for _, u := range result.Users {
+		if user, ok := u.(*tg.User); ok {
+			users[user.ID] = user
+		}
+	}
+
+	var cache contactsCache
+	ids := make([]int64, 0, len(result.Contacts))
+	for _, contact := range result.Contacts {
+		ids = append(ids, contact.UserID)
+		entry := Contact{UserID: contact.UserID}
+		if user, ok := users[contact.UserID]; ok {
+			entry.FirstName = user.FirstName
+			entry.LastName = user.LastName
+			entry.Username = user.Username
+		}
+		cache.Contacts = append(cache.Contacts, entry)
+	}
+	cache.Hash = contactsHash(ids)
+	return cache
+}
+
+// contactsHash implements Telegram's hash over the sorted contact user IDs.
+func contactsHash(ids []int64) int64 {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var hash uint64
+	for _, id := range sorted {
+		hash ^= hash >> 21
+		hash ^= hash << 35
+		hash ^= hash >> 4
+		hash += uint64(id)
+	}
+	return int64(hash)
+}
+
+func readContactsCache(path string) (contactsCache, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return contactsCache{}, nil
+	}
+	if err != nil {
+		return contactsCache{}, fmt.Errorf("failed to read contacts cache: %w", err)
+	}
+	var cache contactsCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		// A broken cache is refetched in full.
+		return contactsCache{}, nil
+	}
+	return cache, nil
+}
+
+func writeContactsCache(path string, cache contactsCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create contacts cache directory: %w", err)
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode contacts cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write contacts cache: %w", err)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
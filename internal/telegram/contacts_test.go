@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestSenderName(t *testing.T) {
+	client := &Client{contacts: map[int64]Contact{
+		10: {UserID: 10, FirstName: "Mom"},
+	}}
+	client.rememberUsers([]tg.UserClass{
+		&tg.User{ID: 10, FirstName: "Jane", LastName: "Doe", Username: "jane"},
+		&tg.User{ID: 11, FirstName: "Bob"},
+	})
+
+	tests := []struct {
+		id     int64
+		source NameSource
+		want   string
+	}{
+		{10, NamesContact, "Mom"},
+		{10, NamesProfile, "Jane Doe"},
+		{10, NamesUsername, "@jane"},
+		{11, NamesContact, "Bob"},
+		{11, NamesUsername, "Bob"},
+		{12, NamesContact, ""},
+	}
+	for _, tt := range tests {
+		if got := client.SenderName(tt.id, tt.source); got != tt.want {
+			t.Errorf("SenderName(%d, %s) = %q, want %q", tt.id, tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestContactsCache_RoundTrip(t *testing.T) {
+	cache := newContactsCache(&tg.ContactsContacts{
+		Contacts: []tg.Contact{{UserID: 20}, {UserID: 10}},
+		Users: []tg.UserClass{
+			&tg.User{ID: 10, FirstName: "Mom"},
+			&tg.User{ID: 20, FirstName: "Ada", LastName: "L", Username: "ada"},
+		},
+	})
+	if cache.Hash != contactsHash([]int64{10, 20}) {
+		t.Fatalf("hash should not depend on contact order")
+	}
+
+	path := filepath.Join(t.TempDir(), "session", "contacts.json")
+	if err := writeContactsCache(path, cache); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	got, err := readContactsCache(path)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if !reflect.DeepEqual(got, cache) {
+		t.Fatalf("expected %+v, got %+v", cache, got)
+	}
+}
+
+func TestResolveUsers_SkipsCachedUsersAndChannels(t *testing.T) {
+	client := &Client{peerCache: map[int64]tg.InputPeerClass{20: &tg.InputPeerChannel{ChannelID: 20}}}
+	client.rememberUsers([]tg.UserClass{&tg.User{ID: 10, FirstName: "Jane"}})
+
+	// Nothing is left to request, so no API call is made.
+	if err := client.ResolveUsers(context.Background(), []int64{10, 20}); err != nil {
+		t.Fatalf("ResolveUsers error: %v", err)
+	}
+}
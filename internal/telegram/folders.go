@@ -0,0 +1,129 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gotd/td/tg"
+)
+
+// Folder is one of the user's chat folders. Chats belong to it when they
+// are listed explicitly or match its chat types, unless they are excluded.
+type Folder struct {
+	ID    int
+	Title string
+	// Pinned and included chats, and excluded ones, by chat ID.
+	include map[int64]bool
+	exclude map[int64]bool
+	// Chat types included as a whole and the chats left out of them.
+	contacts        bool
+	nonContacts     bool
+	groups          bool
+	broadcasts      bool
+	bots            bool
+	excludeMuted    bool
+	excludeRead     bool
+	excludeArchived bool
+}
+
+// GetFolders lists the user's chat folders.
+func (c *Client) GetFolders(ctx context.Context) ([]Folder, error) {
+	result, err := c.ctx.Raw.MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folders: %w", err)
+	}
+	selfID := int64(0)
+	if self := c.self(); self != nil {
+		selfID = self.ID
+	}
+
+	var folders []Folder
+	for _, filter := range result.Filters {
+		switch f := filter.(type) {
+		case *tg.DialogFilter:
+			folders = append(folders, Folder{
+				ID:              f.ID,
+				Title:           f.Title.Text,
+				include:         peerIDs(selfID, f.PinnedPeers, f.IncludePeers),
+				exclude:         peerIDs(selfID, f.ExcludePeers),
+				contacts:        f.Contacts,
+				nonContacts:     f.NonContacts,
+				groups:          f.Groups,
+				broadcasts:      f.Broadcasts,
+				bots:            f.Bots,
+				excludeMuted:    f.ExcludeMuted,
+				excludeRead:     f.ExcludeRead,
+				excludeArchived: f.ExcludeArchived,
+			})
+		case *tg.DialogFilterChatlist:
+			folders = append(folders, Folder{
+				ID:      f.ID,
+				Title:   f.Title.Text,
+				include: peerIDs(selfID, f.PinnedPeers, f.IncludePeers),
+				exclude: map[int64]bool{},
+			})
+		}
+	}
+	return folders, nil
+}
+
+// FindFolder returns the folder with the given ID or case-insensitive
+// title.
+func FindFolder(folders []Folder, ref string) (*Folder, error) {
+	ref = strings.TrimSpace(ref)
+	id, idErr := strconv.Atoi(ref)
+	for i := range folders {
+		if (idErr == nil && folders[i].ID == id) || strings.EqualFold(folders[i].Title, ref) {
+			return &folders[i], nil
+		}
+	}
+	return nil, fmt.Errorf("folder %q not found", ref)
+}
+
+// Contains reports whether chat belongs to the folder.
+func (f Folder) Contains(chat Chat) bool {
+	if f.exclude[chat.ID] {
+		return false
+	}
+	if f.include[chat.ID] {
+		return true
+	}
+	if (f.excludeMuted && chat.Muted) || (f.excludeRead && chat.UnreadCount == 0) || (f.excludeArchived && chat.Archived) {
+		return false
+	}
+	switch {
+	case chat.IsBot:
+		return f.bots
+	case chat.IsUser && chat.IsContact:
+		return f.contacts
+	case chat.IsUser:
+		return f.nonContacts
+	case chat.IsBroadcast:
+		return f.broadcasts
+	default:
+		return f.groups
+	}
+}
+
+// peerIDs collects the chat IDs of folder peers; the user's own chat is
+// listed as InputPeerSelf.
+func peerIDs(selfID int64, lists ...[]tg.InputPeerClass) map[int64]bool {
+	ids := make(map[int64]bool)
+	for _, peers := range lists {
+		for _, peer := range peers {
+			switch p := peer.(type) {
+			case *tg.InputPeerUser:
+				ids[p.UserID] = true
+			case *tg.InputPeerChat:
+				ids[p.ChatID] = true
+			case *tg.InputPeerChannel:
+				ids[p.ChannelID] = true
+			case *tg.InputPeerSelf:
+				ids[selfID] = true
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestFindFolder(t *testing.T) {
+	folders := []Folder{{ID: 2, Title: "Work"}, {ID: 3, Title: "Family"}}
+
+	folder, err := FindFolder(folders, "work")
+	if err != nil || folder.ID != 2 {
+		t.Fatalf("unexpected folder: %+v, %v", folder, err)
+	}
+	folder, err = FindFolder(folders, "3")
+	if err != nil || folder.Title != "Family" {
+		t.Fatalf("unexpected folder: %+v, %v", folder, err)
+	}
+	if _, err := FindFolder(folders, "News"); err == nil {
+		t.Fatal("expected error for unknown folder")
+	}
+}
+
+func TestFolderContains(t *testing.T) {
+	folder := Folder{
+		include:      peerIDs(99, []tg.InputPeerClass{&tg.InputPeerChannel{ChannelID: 5}, &tg.InputPeerSelf{}}),
+		exclude:      peerIDs(99, []tg.InputPeerClass{&tg.InputPeerChat{ChatID: 6}}),
+		groups:       true,
+		contacts:     true,
+		excludeMuted: true,
+	}
+
+	tests := []struct {
+		name string
+		chat Chat
+		want bool
+	}{
+		{name: "included channel", chat: Chat{ID: 5, IsBroadcast: true, Muted: true}, want: true},
+		{name: "self", chat: Chat{ID: 99, IsUser: true, IsSelf: true}, want: true},
+		{name: "excluded group", chat: Chat{ID: 6}, want: false},
+		{name: "group", chat: Chat{ID: 7}, want: true},
+		{name: "muted group", chat: Chat{ID: 8, Muted: true}, want: false},
+		{name: "contact", chat: Chat{ID: 9, IsUser: true, IsContact: true}, want: true},
+		{name: "non-contact", chat: Chat{ID: 10, IsUser: true}, want: false},
+		{name: "channel", chat: Chat{ID: 11, IsBroadcast: true}, want: false},
+		{name: "bot", chat: Chat{ID: 12, IsUser: true, IsBot: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := folder.Contains(tt.chat); got != tt.want {
+				t.Fatalf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// ParseHashtags parses a comma separated list of hashtags and cashtags,
+// e.g. "#decision,#todo,$TSLA". Tags without a prefix become hashtags.
+func ParseHashtags(value string) ([]string, error) {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if !strings.HasPrefix(tag, "#") && !strings.HasPrefix(tag, "$") {
+			tag = "#" + tag
+		}
+		if len(tag) == 1 || strings.ContainsAny(tag[1:], " #$") {
+			return nil, fmt.Errorf("invalid hashtag %q", tag)
+		}
+		if seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// GetHashtagMessages collects the messages tagged with any of tags between
+// since and until; zero since or until leave the range open. chats limits
+// the search to those chats, nil searches all chats. Only Telegram search
+// results are matched: search hits are kept when the tag is one of the
+// message's hashtag or cashtag entities, so a message with several tags is
+// listed under each of them. Cached or checkpointed messages are not
+// scanned.
+// Each message's Group is its tag. Tags come in reverse order and messages
+// within a tag newest first, so reversing the result lists the tags in the
+// given order, oldest message first.
+func (c *Client) GetHashtagMessages(ctx context.Context, tags []string, chats []Chat, since, until time.Time, progress ProgressFunc) ([]Message, error) {
+	var all []Message
+	for i := len(tags) - 1; i >= 0; i-- {
+		tag := tags[i]
+		messages, err := c.tagMessages(ctx, tag, chats, since, until, progress)
+		for j := range messages {
+			messages[j].Group = tag
+		}
+		all = append(all, mergeByDate(messages)...)
+		if err != nil {
+			return all, err
+		}
+	}
+	return all, nil
+}
+
+// tagMessages searches for one tag in chats, or in all chats when chats is
+// nil. Paging stops at the first message older than since. Chats that
+// cannot be searched, e.g. private channels, are skipped with a progress
+// note.
+func (c *Client) tagMessages(ctx context.Context, tag string, chats []Chat, since, until time.Time, progress ProgressFunc) ([]Message, error) {
+	match := func(msg *tg.Message) bool {
+		return hasContent(msg) && hasTag(msg, tag)
+	}
+	if chats == nil {
+		return c.searchGlobal(ctx, tag, tag, SearchAll, since, until, match, progress)
+	}
+
+	var all []Message
+	for _, chat := range chats {
+		peer, err := c.inputPeer(chat.ID)
+		if err != nil {
+			reportProgress(progress, ProgressUpdate{Phase: fmt.Sprintf("skipped %s: %v", chat.Title, err)})
+			continue
+		}
+		// Like for global searches, permalinks come from the chats of the
+		// search results.
+		links := make(map[int64]string)
+		messages, err := c.pageMessages(
+			ctx,
+			progress,
+			tag+" in "+chat.Title,
+			0,
+			0,
+			nil,
+			false,
+			tagSearchFetchFunc(ctx, c, peer, tag, since, until, links),
+			tagFilter(since, match),
+		)
+		if err != nil {
+			if ctx.Err() != nil {
+				return all, err
+			}
+			reportProgress(progress, ProgressUpdate{Phase: fmt.Sprintf("skipped %s: %v", chat.Title, err)})
+			continue
+		}
+		for i := range messages {
+			locateMessage(&messages[i], chat.ID, chat.Title, links)
+		}
+		all = append(all, messages...)
+	}
+	return all, nil
+}
+
+// tagFilter keeps the messages that match and stops at the first one older
+// than since; search results come newest first.
+func tagFilter(since time.Time, match func(*tg.Message) bool) func(msg *tg.Message) (bool, bool) {
+	return func(msg *tg.Message) (bool, bool) {
+		if !since.IsZero() && time.Unix(int64(msg.Date), 0).Before(since) {
+			return false, true
+		}
+		return match(msg), false
+	}
+}
+
+// tagSearchFetchFunc searches peer for tag and adds the permalink bases of
+// the chats in each result to links.
+func tagSearchFetchFunc(ctx context.Context, c *Client, peer tg.InputPeerClass, tag string, since, until time.Time, links map[int64]string) func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+	return func(offsetID, _, limit int) (tg.MessagesMessagesClass, error) {
+		request := &tg.MessagesSearchRequest{
+			Peer:     peer,
+			Q:        tag,
+			Filter:   &tg.InputMessagesFilterEmpty{},
+			OffsetID: offsetID,
+			Limit:    limit,
+		}
+		if !since.IsZero() {
+			request.MinDate = int(since.Unix())
+		}
+		if !until.IsZero() {
+			request.MaxDate = int(until.Unix())
+		}
+		result, err := c.ctx.Raw.MessagesSearch(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search messages: %w", err)
+		}
+		if page, ok := result.AsModified(); ok {
+			addPermalinkBases(links, page.GetChats())
+		}
+		return result, nil
+	}
+}
+
+// hasTag reports whether tag is one of the hashtag or cashtag entities of
+// msg. Tags compare case-insensitively, like in Telegram.
+func hasTag(msg *tg.Message, tag string) bool {
+	for _, entity := range msg.Entities {
+		switch entity.(type) {
+		case *tg.MessageEntityHashtag, *tg.MessageEntityCashtag:
+			text := entityText(msg.Message, entity.GetOffset(), entity.GetLength())
+			// Channel hashtags such as #news@channel still match #news.
+			text, _, _ = strings.Cut(text, "@")
+			if strings.EqualFold(text, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestParseHashtags(t *testing.T) {
+	tags, err := ParseHashtags(" #decision, todo,$TSLA,,#Decision ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"#decision", "#todo", "$TSLA"}
+	if len(tags) != len(want) {
+		t.Fatalf("unexpected tags: %v", tags)
+	}
+	for i := range want {
+		if tags[i] != want[i] {
+			t.Fatalf("unexpected tags: %v", tags)
+		}
+	}
+
+	for _, value := range []string{"#", "#two words", "#a#b"} {
+		if _, err := ParseHashtags(value); err == nil {
+			t.Fatalf("expected error for %q", value)
+		}
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	msg := &tg.Message{
+		Message: "привет #Decision and $TSLA, see #news@channel",
+		Entities: []tg.MessageEntityClass{
+			&tg.MessageEntityHashtag{Offset: 7, Length: 9},
+			&tg.MessageEntityCashtag{Offset: 21, Length: 5},
+			&tg.MessageEntityHashtag{Offset: 32, Length: 13},
+		},
+	}
+	for _, tag := range []string{"#decision", "$TSLA", "#news"} {
+		if !hasTag(msg, tag) {
+			t.Fatalf("expected %s to match", tag)
+		}
+	}
+	if hasTag(msg, "#todo") {
+		t.Fatal("unexpected match for #todo")
+	}
+	// Plain text without an entity, e.g. inside a code block, is no tag.
+	if hasTag(&tg.Message{Message: "#decision"}, "#decision") {
+		t.Fatal("unexpected match without entity")
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := tagFilter(since, func(msg *tg.Message) bool { return msg.ID != 2 })
+
+	if keep, stop := filter(&tg.Message{ID: 1, Date: int(since.Add(time.Hour).Unix())}); !keep || stop {
+		t.Fatalf("expected a match in range to be kept, got keep=%v stop=%v", keep, stop)
+	}
+	if keep, stop := filter(&tg.Message{ID: 2, Date: int(since.Add(time.Hour).Unix())}); keep || stop {
+		t.Fatalf("expected a miss to be skipped, got keep=%v stop=%v", keep, stop)
+	}
+	if keep, stop := filter(&tg.Message{ID: 3, Date: int(since.Add(-time.Hour).Unix())}); keep || !stop {
+		t.Fatalf("expected paging to stop before since, got keep=%v stop=%v", keep, stop)
+	}
+	if keep, stop := tagFilter(time.Time{}, func(*tg.Message) bool { return true })(&tg.Message{ID: 4}); !keep || stop {
+		t.Fatalf("expected no bound without since, got keep=%v stop=%v", keep, stop)
+	}
+}
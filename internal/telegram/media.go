@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"strings"
+
+	"github.com/gotd/td/tg"
+)
+
+// Media kinds decoded from message attachments.
+const (
+	MediaLink     = "link"
+	MediaLocation = "location"
+	MediaVenue    = "venue"
+	MediaContact  = "contact"
+	MediaDice     = "dice"
+	MediaInvoice  = "invoice"
+	MediaGame     = "game"
+)
+
+// Media is a decoded non-file attachment. Only the fields that apply to
+// Kind are set.
+type Media struct {
+	Kind        string
+	URL         string
+	SiteName    string
+	Title       string
+	Description string
+	Latitude    float64
+	Longitude   float64
+	Address     string
+	Phone       string
+	Emoji       string
+	Value       int
+	Currency    string
+	// Amount is in the smallest units of Currency.
+	Amount int64
+}
+
+// messageMedia decodes link previews, locations, venues, contacts, dice,
+// invoices and games. Other attachments return nil.
+func messageMedia(msg *tg.Message) *Media {
+	switch m := msg.Media.(type) {
+	case *tg.MessageMediaWebPage:
+		return webPageMedia(m.Webpage)
+	case *tg.MessageMediaGeo:
+		media := &Media{Kind: MediaLocation}
+		setGeo(media, m.Geo)
+		return media
+	case *tg.MessageMediaVenue:
+		media := &Media{Kind: MediaVenue, Title: m.Title, Address: m.Address}
+		setGeo(media, m.Geo)
+		return media
+	case *tg.MessageMediaContact:
+		return &Media{
+			Kind:  MediaContact,
+			Title: strings.TrimSpace(m.FirstName + " " + m.LastName),
+			Phone: m.PhoneNumber,
+		}
+	case *tg.MessageMediaDice:
+		return &Media{Kind: MediaDice, Emoji: m.Emoticon, Value: m.Value}
+	case *tg.MessageMediaInvoice:
+		return &Media{
+			Kind:        MediaInvoice,
+			Title:       m.Title,
+			Description: m.Description,
+			Currency:    m.Currency,
+			Amount:      m.TotalAmount,
+		}
+	case *tg.MessageMediaGame:
+		return &Media{Kind: MediaGame, Title: m.Game.Title, Description: m.Game.Description}
+	default:
+		return nil
+	}
+}
+
+func webPageMedia(page tg.WebPageClass) *Media {
+	switch p := page.(type) {
+	case *tg.WebPage:
+		return &Media{
+			Kind:        MediaLink,
+			URL:         p.URL,
+			SiteName:    p.SiteName,
+			Title:       p.Title,
+			Description: p.Description,
+		}
+	case *tg.WebPagePending:
+		return &Media{Kind: MediaLink, URL: p.URL}
+	case *tg.WebPageEmpty:
+		if p.URL == "" {
+			return nil
+		}
+		return &Media{Kind: MediaLink, URL: p.URL}
+	default:
+		return nil
+	}
+}
+
+func setGeo(media *Media, geo tg.GeoPointClass) {
+	if point, ok := geo.(*tg.GeoPoint); ok {
+		media.Latitude = point.Lat
+		media.Longitude = point.Long
+	}
+}
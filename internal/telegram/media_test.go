@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestMessageMedia(t *testing.T) {
+	tests := []struct {
+		name  string
+		media tg.MessageMediaClass
+		want  *Media
+	}{
+		{
+			name: "link preview",
+			media: &tg.MessageMediaWebPage{Webpage: &tg.WebPage{
+				URL: "https://example.com/a", SiteName: "Example", Title: "Article", Description: "About things",
+			}},
+			want: &Media{Kind: MediaLink, URL: "https://example.com/a", SiteName: "Example", Title: "Article", Description: "About things"},
+		},
+		{
+			name:  "pending link preview",
+			media: &tg.MessageMediaWebPage{Webpage: &tg.WebPagePending{URL: "https://example.com/b"}},
+			want:  &Media{Kind: MediaLink, URL: "https://example.com/b"},
+		},
+		{
+			name:  "venue",
+			media: &tg.MessageMediaVenue{Geo: &tg.GeoPoint{Lat: 48.8584, Long: 2.2945}, Title: "Eiffel Tower", Address: "Champ de Mars"},
+			want:  &Media{Kind: MediaVenue, Title: "Eiffel Tower", Address: "Champ de Mars", Latitude: 48.8584, Longitude: 2.2945},
+		},
+		{
+			name:  "contact",
+			media: &tg.MessageMediaContact{FirstName: "Ada", LastName: "Lovelace", PhoneNumber: "+100"},
+			want:  &Media{Kind: MediaContact, Title: "Ada Lovelace", Phone: "+100"},
+		},
+		{
+			name:  "dice",
+			media: &tg.MessageMediaDice{Emoticon: "🎲", Value: 4},
+			want:  &Media{Kind: MediaDice, Emoji: "🎲", Value: 4},
+		},
+		{
+			name:  "invoice",
+			media: &tg.MessageMediaInvoice{Title: "Ticket", Description: "Conf pass", Currency: "EUR", TotalAmount: 4950},
+			want:  &Media{Kind: MediaInvoice, Title: "Ticket", Description: "Conf pass", Currency: "EUR", Amount: 4950},
+		},
+		{
+			name:  "unsupported photo",
+			media: &tg.MessageMediaPhoto{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &tg.Message{Media: tt.media}
+			got := messageMedia(msg)
+			if tt.want == nil {
+				if got != nil || hasContent(msg) {
+					t.Fatalf("expected no media, got %+v", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Fatalf("messageMedia() = %+v, want %+v", got, tt.want)
+			}
+			if !hasContent(msg) {
+				t.Fatal("expected media without text to have content")
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// GetUnreadMentions fetches messages that mention the user and were not read
+// yet. A non-zero topicID limits the result to that forum topic.
+func (c *Client) GetUnreadMentions(ctx context.Context, chatID int64, topicID int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchMessages(
+		ctx,
+		progress,
+		"mentions",
+		time.Time{},
+		false,
+		resume,
+		func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+			return c.ctx.Raw.MessagesGetUnreadMentions(ctx, &tg.MessagesGetUnreadMentionsRequest{
+				Peer:     inputPeer,
+				TopMsgID: topicID,
+				OffsetID: offsetID,
+				Limit:    limit,
+			})
+		},
+		textMessageFilter,
+	)
+}
+
+// GetUnreadReactions fetches the user's own messages that received reactions
+// the user has not seen yet. A non-zero topicID limits the result to that
+// forum topic.
+func (c *Client) GetUnreadReactions(ctx context.Context, chatID int64, topicID int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchMessages(
+		ctx,
+		progress,
+		"reactions",
+		time.Time{},
+		false,
+		resume,
+		func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+			return c.ctx.Raw.MessagesGetUnreadReactions(ctx, &tg.MessagesGetUnreadReactionsRequest{
+				Peer:     inputPeer,
+				TopMsgID: topicID,
+				OffsetID: offsetID,
+				Limit:    limit,
+			})
+		},
+		textMessageFilter,
+	)
+}
+
+// ReadMentions marks all mentions in the chat (or topic, when topicID is
+// non-zero) as read.
+func (c *Client) ReadMentions(ctx context.Context, chatID int64, topicID int) error {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return err
+	}
+	return readAll(func() (*tg.MessagesAffectedHistory, error) {
+		return c.ctx.Raw.MessagesReadMentions(ctx, &tg.MessagesReadMentionsRequest{
+			Peer:     inputPeer,
+			TopMsgID: topicID,
+		})
+	}, "mentions")
+}
+
+// ReadReactions marks all reactions in the chat (or topic, when topicID is
+// non-zero) as seen.
+func (c *Client) ReadReactions(ctx context.Context, chatID int64, topicID int) error {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return err
+	}
+	return readAll(func() (*tg.MessagesAffectedHistory, error) {
+		return c.ctx.Raw.MessagesReadReactions(ctx, &tg.MessagesReadReactionsRequest{
+			Peer:     inputPeer,
+			TopMsgID: topicID,
+		})
+	}, "reactions")
+}
+
+// readAll repeats a read request while Telegram reports that more messages
+// are left to process.
+func readAll(read func() (*tg.MessagesAffectedHistory, error), what string) error {
+	for {
+		affected, err := read()
+		if err != nil {
+			return fmt.Errorf("failed to mark %s as read: %w", what, err)
+		}
+		if affected.Offset <= 0 {
+			return nil
+		}
+	}
+}
+
+// ReadMessageContents marks the contents of the messages with the given IDs
+// as read, which clears their mentions. It is used when an export did not
+// cover every unread mention, so the ones it left out stay unread.
+func (c *Client) ReadMessageContents(ctx context.Context, chat Chat, ids []int) error {
+	inputPeer, err := c.inputPeer(chat.ID)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(ids); start += readContentsBatch {
+		batch := ids[start:min(start+readContentsBatch, len(ids))]
+		if channel, ok := inputPeer.(*tg.InputPeerChannel); ok {
+			_, err = c.ctx.Raw.ChannelsReadMessageContents(ctx, &tg.ChannelsReadMessageContentsRequest{
+				Channel: &tg.InputChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash},
+				ID:      batch,
+			})
+		} else {
+			_, err = c.ctx.Raw.MessagesReadMessageContents(ctx, batch)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to mark message contents as read: %w", err)
+		}
+	}
+	return nil
+}
+
+// readContentsBatch is the number of message IDs sent per read request.
+const readContentsBatch = 100
+
+// textMessageFilter keeps every message with content. Unlike the history
+// filters it keeps outgoing messages, since reactions are left on them.
+func textMessageFilter(msg *tg.Message) (bool, bool) {
+	if !hasContent(msg) {
+		return false, false // Skip
+	}
+	return true, false // Process
+}
@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// EventMigration marks the point where a basic group was upgraded to a
+// supergroup. Messages before it come from the original group.
+const EventMigration = "migration"
+
+const migrationText = "Group upgraded to a supergroup; earlier messages come from the original group"
+
+// migratedFrom returns the basic group a supergroup was upgraded from, or
+// nil for chats that were not migrated. The lookup is best-effort: when it
+// fails the supergroup history is exported without the original group, and
+// only cancellation is returned as an error.
+func (c *Client) migratedFrom(ctx context.Context, inputPeer tg.InputPeerClass, progress ProgressFunc) (tg.InputPeerClass, error) {
+	peer, ok := inputPeer.(*tg.InputPeerChannel)
+	if !ok {
+		return nil, nil
+	}
+	full, err := c.ctx.Raw.ChannelsGetFullChannel(ctx, &tg.InputChannel{ChannelID: peer.ChannelID, AccessHash: peer.AccessHash})
+	if err != nil {
+		return nil, legacySkipped(ctx, progress, fmt.Errorf("failed to get full channel: %w", err))
+	}
+	channelFull, ok := full.FullChat.(*tg.ChannelFull)
+	if !ok {
+		return nil, nil
+	}
+	chatID, ok := channelFull.GetMigratedFromChatID()
+	if !ok {
+		return nil, nil
+	}
+	return &tg.InputPeerChat{ChatID: chatID}, nil
+}
+
+// appendLegacyHistory continues a date range fetch of a migrated supergroup
+// into the original basic group. messages are the supergroup messages,
+// newest first; the legacy ones follow after a migration marker. The legacy
+// part is not checkpointed, so a resumed fetch pages it again.
+func (c *Client) appendLegacyHistory(ctx context.Context, inputPeer tg.InputPeerClass, since, until time.Time, progress ProgressFunc, messages []Message) ([]Message, error) {
+	legacyPeer, err := c.migratedFrom(ctx, inputPeer, progress)
+	if err != nil || legacyPeer == nil {
+		return messages, err
+	}
+
+	offsetDate := 0
+	if !until.IsZero() {
+		offsetDate = int(until.Unix())
+	}
+	legacy, err := c.pageMessages(
+		ctx,
+		withoutCursor(progress),
+		"legacy-date-range",
+		0,
+		offsetDate,
+		nil,
+		false,
+		historyFetchFunc(ctx, c, legacyPeer),
+		dateRangeFilter(since, until, false),
+	)
+	if err != nil && !errors.Is(err, ErrFetchCanceled) {
+		return messages, legacySkipped(ctx, progress, err)
+	}
+	if len(legacy) > 0 {
+		messages = append(messages, migrationMarker(legacy[0].Date))
+		messages = append(messages, legacy...)
+	}
+	return messages, err
+}
+
+// legacySkipped reports that the original group of a migrated supergroup
+// could not be fetched. It returns an error only when ctx was canceled, so
+// a canceled fetch can still be exported partially.
+func legacySkipped(ctx context.Context, progress ProgressFunc, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ErrFetchCanceled, ctx.Err())
+	}
+	reportProgress(progress, ProgressUpdate{Phase: fmt.Sprintf("skipped the original group: %v", err)})
+	return nil
+}
+
+// streamWithLegacyHistory yields the original basic group's messages and a
+// migration marker before the supergroup messages of stream.
+func (c *Client) streamWithLegacyHistory(ctx context.Context, inputPeer tg.InputPeerClass, since, until time.Time, progress ProgressFunc, stream iter.Seq2[Message, error]) iter.Seq2[Message, error] {
+	return func(yield func(Message, error) bool) {
+		legacyPeer, err := c.migratedFrom(ctx, inputPeer, progress)
+		if err != nil {
+			yield(Message{}, err)
+			return
+		}
+		if legacyPeer != nil {
+			var last *Message
+			legacy := c.streamMessages(
+				ctx,
+				progress,
+				"legacy-date-range",
+				0,
+				since,
+				historyPageFunc(ctx, c, legacyPeer),
+				dateRangeStreamFilter(since, until, false),
+			)
+			for msg, err := range legacy {
+				if !yield(msg, err) || err != nil {
+					return
+				}
+				last = &msg
+			}
+			if last != nil && !yield(migrationMarker(last.Date), nil) {
+				return
+			}
+		}
+		for msg, err := range stream {
+			if !yield(msg, err) {
+				return
+			}
+		}
+	}
+}
+
+func migrationMarker(date time.Time) Message {
+	return Message{Date: date, Text: migrationText, Event: EventMigration}
+}
+
+// withoutCursor drops checkpoint cursors from progress updates, for fetch
+// phases that cannot be resumed.
+func withoutCursor(progress ProgressFunc) ProgressFunc {
+	if progress == nil {
+		return nil
+	}
+	return func(update ProgressUpdate) {
+		update.Cursor = nil
+		progress(update)
+	}
+}
@@ -0,0 +1,41 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithoutCursor(t *testing.T) {
+	var got ProgressUpdate
+	progress := withoutCursor(func(update ProgressUpdate) { got = update })
+	progress(ProgressUpdate{Phase: "legacy-date-range", Parsed: 3, Cursor: &Cursor{OffsetID: 7}})
+
+	if got.Cursor != nil {
+		t.Fatalf("expected cursor to be dropped, got %+v", got.Cursor)
+	}
+	if got.Phase != "legacy-date-range" || got.Parsed != 3 {
+		t.Fatalf("unexpected update: %+v", got)
+	}
+	if withoutCursor(nil) != nil {
+		t.Fatalf("expected nil progress to stay nil")
+	}
+}
+
+func TestLegacySkipped(t *testing.T) {
+	var got ProgressUpdate
+	progress := func(update ProgressUpdate) { got = update }
+	if err := legacySkipped(context.Background(), progress, errors.New("CHANNEL_PRIVATE")); err != nil {
+		t.Fatalf("expected a failed lookup to be skipped, got %v", err)
+	}
+	if !strings.Contains(got.Phase, "CHANNEL_PRIVATE") {
+		t.Fatalf("expected the failure to be reported, got %+v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := legacySkipped(ctx, nil, errors.New("canceled")); !errors.Is(err, ErrFetchCanceled) {
+		t.Fatalf("expected ErrFetchCanceled, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// minPartitionSize keeps partitions at least one page wide, so short ranges
+// are not split into requests that each return a handful of messages.
+const minPartitionSize = 100
+
+// GetMessagesByDateParallel fetches the same messages as GetMessagesByDate,
+// but splits the range into up to partitions message-ID ranges fetched
+// concurrently. All requests still go through the client's rate limiter.
+func (c *Client) GetMessagesByDateParallel(ctx context.Context, chatID int64, since, until time.Time, partitions int, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := c.fetchPartitioned(
+		ctx,
+		progress,
+		"date-range",
+		since,
+		until,
+		partitions,
+		historyFetchFunc(ctx, c, inputPeer),
+		dateRangeFilter(since, until, false),
+	)
+	if err != nil {
+		return messages, err
+	}
+	return c.appendLegacyHistory(ctx, inputPeer, since, until, progress, messages)
+}
+
+// GetTopicMessagesByDateParallel is the partitioned variant of GetTopicMessagesByDate.
+func (c *Client) GetTopicMessagesByDateParallel(ctx context.Context, chatID int64, topicID int, since, until time.Time, partitions int, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchPartitioned(
+		ctx,
+		progress,
+		"topic-date-range",
+		since,
+		until,
+		partitions,
+		topicFetchFunc(ctx, c, inputPeer, topicID),
+		dateRangeFilter(since, until, topicID == 1),
+	)
+}
+
+// idPartition is the message ID range (Low, High].
+type idPartition struct {
+	Low  int
+	High int
+}
+
+// splitIDRange splits (low, high] into at most n partitions of at least
+// minPartitionSize IDs, newest first.
+func splitIDRange(low, high, n int) []idPartition {
+	if high <= low {
+		return nil
+	}
+	span := high - low
+	n = min(n, (span+minPartitionSize-1)/minPartitionSize)
+	n = max(n, 1)
+
+	parts := make([]idPartition, 0, n)
+	upper := high
+	for i := 0; i < n; i++ {
+		lower := high - span*(i+1)/n
+		parts = append(parts, idPartition{Low: lower, High: upper})
+		upper = lower
+	}
+	return parts
+}
+
+// probeBoundaryID returns the ID of the newest message sent before date,
+// or 0 when there is none.
+func probeBoundaryID(fetch func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error), date time.Time) (int, error) {
+	result, err := fetch(0, int(date.Unix()), 1)
+	if err != nil {
+		return 0, err
+	}
+	msgs, _ := extractMessagesAndUsers(result)
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	return msgs[0].GetID(), nil
+}
+
+// fetchPartitioned probes the message IDs at since and until, pages the
+// partitions between them concurrently and concatenates the results newest
+// first, matching the order of the sequential fetch.
+//
+// On cancellation only the newest contiguous run of messages is returned, so
+// a partial result never has gaps.
+func (c *Client) fetchPartitioned(
+	ctx context.Context,
+	progress ProgressFunc,
+	phase string,
+	since, until time.Time,
+	partitions int,
+	fetch func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error),
+	filter func(msg *tg.Message) (process bool, stop bool),
+) ([]Message, error) {
+	high, err := probeBoundaryID(fetch, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe range end: %w", err)
+	}
+	low, err := probeBoundaryID(fetch, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe range start: %w", err)
+	}
+	parts := splitIDRange(low, high, partitions)
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	reportProgress(progress, ProgressUpdate{
+		Phase: fmt.Sprintf("fetching messages %d-%d in %d partitions", low+1, high, len(parts)),
+	})
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var progressMu sync.Mutex
+	partProgress := func(update ProgressUpdate) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		reportProgress(progress, update)
+	}
+
+	results := make([][]Message, len(parts))
+	errs := make([]error, len(parts))
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		wg.Go(func() {
+			partFilter := func(msg *tg.Message) (bool, bool) {
+				if msg.ID <= part.Low {
+					return false, true
+				}
+				return filter(msg)
+			}
+			results[i], errs[i] = c.pageMessages(fetchCtx, partProgress, phase, part.High+1, 0, nil, false, fetch, partFilter)
+			if errs[i] != nil && !errors.Is(errs[i], ErrFetchCanceled) {
+				cancel()
+			}
+		})
+	}
+	wg.Wait()
+
+	var allMessages []Message
+	for i := range parts {
+		if errs[i] == nil {
+			allMessages = append(allMessages, results[i]...)
+			continue
+		}
+		if !errors.Is(errs[i], ErrFetchCanceled) || ctx.Err() == nil {
+			return nil, firstFetchError(errs)
+		}
+		return append(allMessages, results[i]...), errs[i]
+	}
+	return allMessages, nil
+}
+
+// firstFetchError prefers the error that caused the other partitions to be
+// canceled over the cancellations themselves.
+func firstFetchError(errs []error) error {
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrFetchCanceled) {
+			return err
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// fakeHistory serves GetHistory-style pages over messages with IDs 1..count,
+// where message i was sent at unix time 1000+i.
+func fakeHistory(count int) func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+	return func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+		top := count
+		if offsetID != 0 {
+			top = min(top, offsetID-1)
+		} else if offsetDate != 0 {
+			top = min(top, offsetDate-1000-1)
+		}
+		var msgs []tg.MessageClass
+		for id := top; id >= 1 && len(msgs) < limit; id-- {
+			text := "msg"
+			if id%7 == 0 {
+				text = ""
+			}
+			msgs = append(msgs, &tg.Message{ID: id, Date: 1000 + id, Message: text})
+		}
+		return &tg.MessagesMessages{Messages: msgs}, nil
+	}
+}
+
+func TestSplitIDRange(t *testing.T) {
+	got := splitIDRange(100, 1000, 3)
+	want := []idPartition{{Low: 700, High: 1000}, {Low: 400, High: 700}, {Low: 100, High: 400}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected partitions: %v", got)
+	}
+
+	if got := splitIDRange(0, 150, 8); len(got) != 2 {
+		t.Fatalf("expected small range to use 2 partitions, got %v", got)
+	}
+	if got := splitIDRange(10, 10, 4); got != nil {
+		t.Fatalf("expected no partitions for empty range, got %v", got)
+	}
+}
+
+func TestFetchPartitioned_MatchesSequential(t *testing.T) {
+	client := &Client{}
+	fetch := fakeHistory(2000)
+	since := time.Unix(1000+250, 0)
+	until := time.Unix(1000+1730, 0)
+	filter := dateRangeFilter(since, until, false)
+
+	want, err := client.fetchMessages(context.Background(), nil, "phase", until, true, nil, fetch, filter)
+	if err != nil {
+		t.Fatalf("sequential fetch error: %v", err)
+	}
+	got, err := client.fetchPartitioned(context.Background(), nil, "phase", since, until, 4, fetch, filter)
+	if err != nil {
+		t.Fatalf("partitioned fetch error: %v", err)
+	}
+
+	if len(want) == 0 {
+		t.Fatal("expected sequential fetch to return messages")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("partitioned result differs: got %d messages, want %d", len(got), len(want))
+	}
+}
+
+func TestFetchPartitioned_ReturnsFirstError(t *testing.T) {
+	client := &Client{}
+	history := fakeHistory(2000)
+	failErr := errors.New("boom")
+	var calls atomic.Int32
+	fetch := func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+		// Let the two boundary probes through, then fail one partition.
+		if calls.Add(1) == 3 {
+			return nil, failErr
+		}
+		return history(offsetID, offsetDate, limit)
+	}
+	since := time.Unix(1000, 0)
+	until := time.Unix(3000, 0)
+
+	got, err := client.fetchPartitioned(context.Background(), nil, "phase", since, until, 4, fetch, dateRangeFilter(since, until, false))
+	if !errors.Is(err, failErr) {
+		t.Fatalf("expected partition error, got %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected no messages on error, got %d", len(got))
+	}
+}
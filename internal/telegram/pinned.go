@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// GetPinnedMessages fetches the pinned messages of a chat, newest first. A
+// non-zero topicID limits the result to that forum topic.
+func (c *Client) GetPinnedMessages(ctx context.Context, chatID int64, topicID int, resume *Cursor, progress ProgressFunc) ([]Message, error) {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchMessages(
+		ctx,
+		progress,
+		"pinned",
+		time.Time{},
+		false,
+		resume,
+		func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+			return c.ctx.Raw.MessagesSearch(ctx, &tg.MessagesSearchRequest{
+				Peer:     inputPeer,
+				Filter:   &tg.InputMessagesFilterPinned{},
+				TopMsgID: topicID,
+				OffsetID: offsetID,
+				Limit:    limit,
+			})
+		},
+		textMessageFilter,
+	)
+}
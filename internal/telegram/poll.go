@@ -0,0 +1,115 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+)
+
+// Poll is a decoded poll attached to a message.
+type Poll struct {
+	ID          int64
+	Question    string
+	Options     []PollOption
+	TotalVoters int
+	Quiz        bool
+	Closed      bool
+}
+
+// PollOption is one poll answer with its vote count. Correct is only known
+// for quizzes once the user has voted or the quiz is closed.
+type PollOption struct {
+	Text    string
+	Voters  int
+	Correct bool
+	// Option identifies the answer in poll results. It is exported so it
+	// survives checkpoints and --poll-results still applies on --resume.
+	Option []byte
+}
+
+// messagePoll decodes the poll of a message, or returns nil.
+func messagePoll(msg *tg.Message) *Poll {
+	media, ok := msg.Media.(*tg.MessageMediaPoll)
+	if !ok {
+		return nil
+	}
+	poll := &Poll{
+		ID:       media.Poll.ID,
+		Question: media.Poll.Question.Text,
+		Quiz:     media.Poll.Quiz,
+		Closed:   media.Poll.Closed,
+	}
+	poll.Options = make([]PollOption, 0, len(media.Poll.Answers))
+	for _, answer := range media.Poll.Answers {
+		poll.Options = append(poll.Options, PollOption{Text: answer.Text.Text, Option: answer.Option})
+	}
+	applyPollResults(poll, media.Results)
+	return poll
+}
+
+// applyPollResults copies vote counts onto the poll options, matching them
+// by option bytes. Results without counts (min results) leave them as is.
+func applyPollResults(poll *Poll, results tg.PollResults) {
+	if total, ok := results.GetTotalVoters(); ok {
+		poll.TotalVoters = total
+	}
+	voters, ok := results.GetResults()
+	if !ok {
+		return
+	}
+	for _, result := range voters {
+		for i := range poll.Options {
+			if bytes.Equal(poll.Options[i].Option, result.Option) {
+				poll.Options[i].Voters = result.Voters
+				poll.Options[i].Correct = result.Correct
+			}
+		}
+	}
+}
+
+// RefreshPoll replaces the vote counts of an open poll with fresh results
+// from messages.getPollResults. Closed polls and messages without a poll
+// are left untouched.
+func (c *Client) RefreshPoll(ctx context.Context, chatID int64, msg *Message) error {
+	if msg.Poll == nil || msg.Poll.Closed {
+		return nil
+	}
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return err
+	}
+	updates, err := c.ctx.Raw.MessagesGetPollResults(ctx, &tg.MessagesGetPollResultsRequest{
+		Peer:  inputPeer,
+		MsgID: msg.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get poll results for message %d: %w", msg.ID, err)
+	}
+	updatePollResults(msg.Poll, updates)
+	return nil
+}
+
+// updatePollResults applies the UpdateMessagePoll for poll found in updates.
+func updatePollResults(poll *Poll, updates tg.UpdatesClass) {
+	var list []tg.UpdateClass
+	switch u := updates.(type) {
+	case *tg.Updates:
+		list = u.Updates
+	case *tg.UpdatesCombined:
+		list = u.Updates
+	case *tg.UpdateShort:
+		list = []tg.UpdateClass{u.Update}
+	}
+	for _, update := range list {
+		pollUpdate, ok := update.(*tg.UpdateMessagePoll)
+		if !ok || pollUpdate.PollID != poll.ID {
+			continue
+		}
+		if fresh, ok := pollUpdate.GetPoll(); ok {
+			poll.Closed = fresh.Closed
+		}
+		applyPollResults(poll, pollUpdate.Results)
+	}
+}
@@ -0,0 +1,82 @@
+package telegram
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func testPollMessage() *tg.Message {
+	poll := tg.Poll{
+		ID:       99,
+		Quiz:     true,
+		Question: tg.TextWithEntities{Text: "Capital of France?"},
+		Answers: []tg.PollAnswer{
+			{Text: tg.TextWithEntities{Text: "Paris"}, Option: []byte("0")},
+			{Text: tg.TextWithEntities{Text: "Lyon"}, Option: []byte("1")},
+		},
+	}
+	var results tg.PollResults
+	results.SetTotalVoters(5)
+	results.SetResults([]tg.PollAnswerVoters{
+		{Option: []byte("1"), Voters: 1},
+		{Option: []byte("0"), Voters: 4, Correct: true},
+	})
+	return &tg.Message{ID: 10, Media: &tg.MessageMediaPoll{Poll: poll, Results: results}}
+}
+
+func TestMessagePoll(t *testing.T) {
+	msg := testPollMessage()
+	if !hasContent(msg) {
+		t.Fatal("expected poll without text to have content")
+	}
+
+	poll := newMessage(msg).Poll
+	if poll == nil {
+		t.Fatal("expected poll to be decoded")
+	}
+	if poll.Question != "Capital of France?" || !poll.Quiz || poll.Closed || poll.TotalVoters != 5 {
+		t.Fatalf("unexpected poll: %+v", poll)
+	}
+	if len(poll.Options) != 2 {
+		t.Fatalf("expected 2 options, got %d", len(poll.Options))
+	}
+	if o := poll.Options[0]; o.Text != "Paris" || o.Voters != 4 || !o.Correct {
+		t.Fatalf("unexpected first option: %+v", o)
+	}
+	if o := poll.Options[1]; o.Text != "Lyon" || o.Voters != 1 || o.Correct {
+		t.Fatalf("unexpected second option: %+v", o)
+	}
+}
+
+func TestUpdatePollResults(t *testing.T) {
+	// Polls from a checkpoint went through JSON.
+	data, err := json.Marshal(newMessage(testPollMessage()))
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	poll := msg.Poll
+
+	var results tg.PollResults
+	results.SetTotalVoters(9)
+	results.SetResults([]tg.PollAnswerVoters{
+		{Option: []byte("0"), Voters: 6, Correct: true},
+		{Option: []byte("1"), Voters: 3},
+	})
+	update := &tg.UpdateMessagePoll{PollID: 99, Results: results}
+	update.SetPoll(tg.Poll{ID: 99, Closed: true})
+	other := &tg.UpdateMessagePoll{PollID: 1, Results: tg.PollResults{}}
+
+	updatePollResults(poll, &tg.Updates{Updates: []tg.UpdateClass{other, update}})
+	if poll.TotalVoters != 9 || !poll.Closed {
+		t.Fatalf("unexpected poll after update: %+v", poll)
+	}
+	if poll.Options[0].Voters != 6 || poll.Options[1].Voters != 3 {
+		t.Fatalf("unexpected votes after update: %+v", poll.Options)
+	}
+}
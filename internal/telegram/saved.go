@@ -0,0 +1,295 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// SavedMessagesTitle is the title of the user's own chat.
+const SavedMessagesTitle = "Saved Messages"
+
+// Forward describes where a forwarded message originally came from.
+type Forward struct {
+	FromID int64
+	// FromName is the name of the original sender or channel. It is the
+	// only origin set for users who hide their account in forwards.
+	FromName string
+	Date     time.Time
+	// PostID and PostAuthor identify the original channel post.
+	PostID     int
+	PostAuthor string
+}
+
+// savedDialog is one source in Saved Messages: the peer forwarded items
+// came from, or the user for notes written directly.
+type savedDialog struct {
+	peer  tg.InputPeerClass
+	title string
+}
+
+// GetSavedMessages fetches the Saved Messages items between since and until
+// grouped by source: each message's Group is the title of the chat or user
+// it was saved from. Groups are ordered by their latest item and messages
+// within a group newest first. Zero since or until leave the range open.
+func (c *Client) GetSavedMessages(ctx context.Context, since, until time.Time, progress ProgressFunc) ([]Message, error) {
+	// Tag titles are a Premium feature; without them tags keep only
+	// their emoji.
+	tags, err := c.savedTagTitles(ctx)
+	if err != nil {
+		reportProgress(progress, ProgressUpdate{Phase: err.Error()})
+	}
+	names := make(map[int64]string)
+	dialogs, err := c.savedDialogs(ctx, since, names)
+	if err != nil {
+		return nil, err
+	}
+
+	offsetDate := 0
+	if !until.IsZero() {
+		offsetDate = int(until.Unix())
+	}
+	var all []Message
+	for _, dialog := range dialogs {
+		messages, err := c.pageMessages(
+			ctx,
+			progress,
+			"saved "+dialog.title,
+			0,
+			offsetDate,
+			nil,
+			false,
+			savedHistoryFetchFunc(ctx, c, dialog.peer, names),
+			savedFilter(since, until),
+		)
+		for i := range messages {
+			messages[i].Group = dialog.title
+			nameForward(messages[i].Forward, names)
+			applySavedTags(&messages[i], tags)
+		}
+		all = append(all, messages...)
+		if err != nil {
+			return all, err
+		}
+	}
+	return all, nil
+}
+
+// savedDialogs lists the Saved Messages sources with items newer than
+// since and records the names of the peers in the responses.
+func (c *Client) savedDialogs(ctx context.Context, since time.Time, names map[int64]string) ([]savedDialog, error) {
+	const batchSize = 100
+	request := &tg.MessagesGetSavedDialogsRequest{OffsetPeer: &tg.InputPeerEmpty{}, Limit: batchSize}
+
+	var dialogs []savedDialog
+	for {
+		result, err := c.ctx.Raw.MessagesGetSavedDialogs(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get saved dialogs: %w", err)
+		}
+		page, ok := result.AsModified()
+		if !ok {
+			return dialogs, nil
+		}
+		c.rememberUsers(page.GetUsers())
+		addPeerNames(names, page.GetUsers(), page.GetChats())
+		peers := inputPeers(page.GetUsers(), page.GetChats())
+		dates := make(map[int]int, len(page.GetMessages()))
+		for _, msg := range page.GetMessages() {
+			if m, ok := msg.(*tg.Message); ok {
+				dates[m.ID] = m.Date
+			}
+		}
+
+		var last *tg.SavedDialog
+		for _, d := range page.GetDialogs() {
+			dialog, ok := d.(*tg.SavedDialog)
+			if !ok {
+				continue
+			}
+			// A dialog without its top message has no date to order it by.
+			date, ok := dates[dialog.TopMessage]
+			if !ok {
+				continue
+			}
+			last = dialog
+			if !since.IsZero() && time.Unix(int64(date), 0).Before(since) {
+				// Pinned dialogs come first regardless of their date.
+				if dialog.Pinned {
+					continue
+				}
+				return dialogs, nil
+			}
+			peerID := resolveSenderID(dialog.Peer)
+			peer, ok := peers[peerID]
+			if !ok {
+				continue
+			}
+			dialogs = append(dialogs, savedDialog{peer: peer, title: peerTitle(peerID, names)})
+		}
+
+		if last == nil || len(page.GetDialogs()) < batchSize {
+			return dialogs, nil
+		}
+		request.OffsetID = last.TopMessage
+		request.OffsetDate = dates[last.TopMessage]
+		request.OffsetPeer = peers[resolveSenderID(last.Peer)]
+		if request.OffsetPeer == nil {
+			return dialogs, nil
+		}
+	}
+}
+
+// savedTagTitles maps tag reactions to the titles the user gave them.
+func (c *Client) savedTagTitles(ctx context.Context) (map[string]string, error) {
+	result, err := c.ctx.Raw.MessagesGetSavedReactionTags(ctx, &tg.MessagesGetSavedReactionTagsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved tags: %w", err)
+	}
+	titles := make(map[string]string)
+	tags, ok := result.(*tg.MessagesSavedReactionTags)
+	if !ok {
+		return titles, nil
+	}
+	for _, tag := range tags.Tags {
+		if emoji, ok := tag.Reaction.(*tg.ReactionEmoji); ok {
+			titles[emoji.Emoticon] = tag.Title
+		}
+	}
+	return titles, nil
+}
+
+func savedHistoryFetchFunc(ctx context.Context, c *Client, peer tg.InputPeerClass, names map[int64]string) func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+	return func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+		result, err := c.ctx.Raw.MessagesGetSavedHistory(ctx, &tg.MessagesGetSavedHistoryRequest{
+			Peer:       peer,
+			OffsetID:   offsetID,
+			OffsetDate: offsetDate,
+			Limit:      limit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get saved history: %w", err)
+		}
+		if modified, ok := result.AsModified(); ok {
+			addPeerNames(names, modified.GetUsers(), modified.GetChats())
+		}
+		return result, nil
+	}
+}
+
+// savedFilter matches Saved Messages items between since and until while
+// paging newest first. Unlike other chats, every item is outgoing.
+func savedFilter(since, until time.Time) func(msg *tg.Message) (bool, bool) {
+	return func(msg *tg.Message) (bool, bool) {
+		msgTime := time.Unix(int64(msg.Date), 0)
+		if !since.IsZero() && msgTime.Before(since) {
+			return false, true
+		}
+		if !until.IsZero() && msgTime.After(until) {
+			return false, false
+		}
+		return hasContent(msg), false
+	}
+}
+
+func messageForward(msg *tg.Message) *Forward {
+	header, ok := msg.GetFwdFrom()
+	if !ok {
+		return nil
+	}
+	return &Forward{
+		FromID:     resolveSenderID(header.FromID),
+		FromName:   header.FromName,
+		Date:       time.Unix(int64(header.Date), 0),
+		PostID:     header.ChannelPost,
+		PostAuthor: header.PostAuthor,
+	}
+}
+
+// nameForward fills in the name of the forward origin from names.
+func nameForward(forward *Forward, names map[int64]string) {
+	if forward == nil || forward.FromName != "" {
+		return
+	}
+	forward.FromName = names[forward.FromID]
+}
+
+// applySavedTags turns the reactions of a Saved Messages item into its
+// tags, e.g. "🔥 Reading list"; there they label items instead of
+// reacting to them.
+func applySavedTags(msg *Message, titles map[string]string) {
+	for _, reaction := range msg.Reactions {
+		tag := reaction.Emoji
+		if title := titles[reaction.Emoji]; title != "" {
+			tag += " " + title
+		}
+		msg.Tags = append(msg.Tags, tag)
+	}
+	msg.Reactions = nil
+}
+
+// addPeerNames records the display names of users and chats by ID.
+func addPeerNames(names map[int64]string, users []tg.UserClass, chats []tg.ChatClass) {
+	for _, u := range users {
+		user, ok := u.(*tg.User)
+		if !ok {
+			continue
+		}
+		switch {
+		case user.Self:
+			names[user.ID] = SavedMessagesTitle
+		case userDisplayName(user) != "":
+			names[user.ID] = userDisplayName(user)
+		case user.Username != "":
+			names[user.ID] = "@" + user.Username
+		}
+	}
+	for _, ch := range chats {
+		switch chat := ch.(type) {
+		case *tg.Chat:
+			names[chat.ID] = chat.Title
+		case *tg.Channel:
+			names[chat.ID] = chat.Title
+		case *tg.ChatForbidden:
+			names[chat.ID] = chat.Title
+		case *tg.ChannelForbidden:
+			names[chat.ID] = chat.Title
+		}
+	}
+}
+
+// inputPeers builds the input peers of users and chats by ID.
+func inputPeers(users []tg.UserClass, chats []tg.ChatClass) map[int64]tg.InputPeerClass {
+	peers := make(map[int64]tg.InputPeerClass)
+	for _, u := range users {
+		user, ok := u.(*tg.User)
+		if !ok {
+			continue
+		}
+		if user.Self {
+			peers[user.ID] = &tg.InputPeerSelf{}
+			continue
+		}
+		peers[user.ID] = &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}
+	}
+	for _, ch := range chats {
+		switch chat := ch.(type) {
+		case *tg.Chat:
+			peers[chat.ID] = &tg.InputPeerChat{ChatID: chat.ID}
+		case *tg.Channel:
+			peers[chat.ID] = &tg.InputPeerChannel{ChannelID: chat.ID, AccessHash: chat.AccessHash}
+		case *tg.ChannelForbidden:
+			peers[chat.ID] = &tg.InputPeerChannel{ChannelID: chat.ID, AccessHash: chat.AccessHash}
+		}
+	}
+	return peers
+}
+
+func peerTitle(id int64, names map[int64]string) string {
+	if name := names[id]; name != "" {
+		return name
+	}
+	return fmt.Sprintf("Unknown Peer %d", id)
+}
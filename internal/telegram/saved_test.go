@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestSavedFilter(t *testing.T) {
+	since := time.Unix(1000, 0)
+	until := time.Unix(2000, 0)
+	filter := savedFilter(since, until)
+
+	tests := []struct {
+		msg     *tg.Message
+		process bool
+		stop    bool
+	}{
+		{msg: &tg.Message{Date: 1500, Out: true, Message: "note"}, process: true},
+		{msg: &tg.Message{Date: 2500, Out: true, Message: "too new"}},
+		{msg: &tg.Message{Date: 500, Out: true, Message: "too old"}, stop: true},
+		{msg: &tg.Message{Date: 1500, Out: true}},
+	}
+	for _, tt := range tests {
+		process, stop := filter(tt.msg)
+		if process != tt.process || stop != tt.stop {
+			t.Errorf("savedFilter(%q at %d) = %v, %v; want %v, %v", tt.msg.Message, tt.msg.Date, process, stop, tt.process, tt.stop)
+		}
+	}
+
+	if process, stop := savedFilter(time.Time{}, time.Time{})(&tg.Message{Date: 1, Message: "any"}); !process || stop {
+		t.Fatal("expected an open range to match everything")
+	}
+}
+
+func TestNewMessage_ForwardOrigin(t *testing.T) {
+	msg := &tg.Message{ID: 1, Date: 2000, Message: "post"}
+	msg.SetFwdFrom(tg.MessageFwdHeader{FromID: &tg.PeerChannel{ChannelID: 300}, Date: 1000, ChannelPost: 812})
+
+	got := newMessage(msg)
+	if got.Forward == nil || got.Forward.FromID != 300 || got.Forward.PostID != 812 || !got.Forward.Date.Equal(time.Unix(1000, 0)) {
+		t.Fatalf("unexpected forward: %+v", got.Forward)
+	}
+
+	names := make(map[int64]string)
+	addPeerNames(names, nil, []tg.ChatClass{&tg.Channel{ID: 300, Title: "Tech News"}})
+	nameForward(got.Forward, names)
+	if got.Forward.FromName != "Tech News" {
+		t.Fatalf("expected forward origin name, got %q", got.Forward.FromName)
+	}
+
+	if newMessage(&tg.Message{ID: 2, Message: "own"}).Forward != nil {
+		t.Fatal("expected no forward for own messages")
+	}
+}
+
+func TestApplySavedTags(t *testing.T) {
+	msg := Message{Reactions: []Reaction{{Emoji: "🔥", Count: 1}, {Emoji: "⭐", Count: 1}}}
+	applySavedTags(&msg, map[string]string{"🔥": "Reading list"})
+
+	if len(msg.Tags) != 2 || msg.Tags[0] != "🔥 Reading list" || msg.Tags[1] != "⭐" {
+		t.Fatalf("unexpected tags: %v", msg.Tags)
+	}
+	if msg.Reactions != nil {
+		t.Fatal("expected tags to replace reactions")
+	}
+}
+
+func TestProcessDialogs_SavedMessages(t *testing.T) {
+	client := &Client{
+		peerCache:    make(map[int64]tg.InputPeerClass),
+		channelCache: make(map[int64]*tg.Channel),
+	}
+
+	dialogs := []tg.DialogClass{&tg.Dialog{Peer: &tg.PeerUser{UserID: 42}}}
+	users := []tg.UserClass{&tg.User{ID: 42, Self: true, FirstName: "Me", Username: "me"}}
+
+	result := client.processDialogs(dialogs, nil, users)
+	if !result[0].IsSelf || result[0].Title != SavedMessagesTitle {
+		t.Fatalf("expected the self chat to be listed as Saved Messages, got %+v", result[0])
+	}
+}
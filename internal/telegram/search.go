@@ -0,0 +1,208 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// SearchScope limits a global search to one kind of chat.
+type SearchScope string
+
+const (
+	SearchAll      SearchScope = "all"
+	SearchGroups   SearchScope = "groups"
+	SearchChannels SearchScope = "channels"
+	SearchPrivate  SearchScope = "private"
+)
+
+// ParseSearchScope parses a search scope name. An empty name searches all
+// chats.
+func ParseSearchScope(value string) (SearchScope, error) {
+	switch scope := SearchScope(value); scope {
+	case "":
+		return SearchAll, nil
+	case SearchAll, SearchGroups, SearchChannels, SearchPrivate:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("unknown search scope %q (use all, groups, channels or private)", value)
+	}
+}
+
+// SearchGlobal searches all chats for query with messages.searchGlobal,
+// limited to scope and to messages between since and until; zero since or
+// until leave the range open. Hits are grouped by chat: each message's
+// Group is the chat title and Link its permalink where the chat has one.
+// Chats are ordered by their latest hit and messages within a chat newest
+// first.
+func (c *Client) SearchGlobal(ctx context.Context, query string, scope SearchScope, since, until time.Time, progress ProgressFunc) ([]Message, error) {
+	hits, err := c.searchGlobal(ctx, "search", query, scope, since, until, hasContent, progress)
+	for i := range hits {
+		hits[i].Group = hits[i].ChatTitle
+	}
+	return groupByChat(hits), err
+}
+
+// searchGlobal pages messages.searchGlobal newest first and returns the
+// hits that match, with the chat each one was found in.
+func (c *Client) searchGlobal(ctx context.Context, phase, query string, scope SearchScope, since, until time.Time, match func(*tg.Message) bool, progress ProgressFunc) ([]Message, error) {
+	const batchSize = 100
+	request := &tg.MessagesSearchGlobalRequest{
+		Q:          query,
+		Filter:     &tg.InputMessagesFilterEmpty{},
+		OffsetPeer: &tg.InputPeerEmpty{},
+		Limit:      batchSize,
+	}
+	switch scope {
+	case SearchGroups:
+		request.SetGroupsOnly(true)
+	case SearchChannels:
+		request.SetBroadcastsOnly(true)
+	case SearchPrivate:
+		request.SetUsersOnly(true)
+	}
+	if !since.IsZero() {
+		request.MinDate = int(since.Unix())
+	}
+	if !until.IsZero() {
+		request.MaxDate = int(until.Unix())
+	}
+
+	names := make(map[int64]string)
+	links := make(map[int64]string)
+	var hits []Message
+	for {
+		if err := ctx.Err(); err != nil {
+			return hits, fmt.Errorf("%w: %w", ErrFetchCanceled, err)
+		}
+		result, err := c.ctx.Raw.MessagesSearchGlobal(ctx, request)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("%w: %w", ErrFetchCanceled, ctx.Err())
+			}
+			return hits, fmt.Errorf("failed to search messages: %w", err)
+		}
+		page, ok := result.AsModified()
+		if !ok {
+			break
+		}
+		c.rememberUsers(page.GetUsers())
+		addPeerNames(names, page.GetUsers(), page.GetChats())
+		addPermalinkBases(links, page.GetChats())
+
+		msgs := page.GetMessages()
+		found := searchHits(msgs, names, links, match)
+		hits = append(hits, found...)
+		reportProgress(progress, ProgressUpdate{
+			Phase:   phase,
+			Parsed:  len(found),
+			Scanned: len(msgs),
+			Batch:   1,
+		})
+
+		last, ok := lastMessage(msgs)
+		if !ok || len(msgs) < batchSize {
+			break
+		}
+		peer := inputPeers(page.GetUsers(), page.GetChats())[resolveSenderID(last.PeerID)]
+		if peer == nil {
+			break
+		}
+		if slice, ok := result.(*tg.MessagesMessagesSlice); ok {
+			request.OffsetRate = slice.NextRate
+		}
+		request.OffsetPeer = peer
+		request.OffsetID = last.ID
+	}
+	return hits, nil
+}
+
+// searchHits converts the messages of a search page that match, recording
+// the chat each one was found in.
+func searchHits(msgs []tg.MessageClass, names map[int64]string, links map[int64]string, match func(*tg.Message) bool) []Message {
+	var hits []Message
+	for _, m := range msgs {
+		msg, ok := m.(*tg.Message)
+		if !ok || !match(msg) {
+			continue
+		}
+		chatID := resolveSenderID(msg.PeerID)
+		hit := newMessage(msg)
+		locateMessage(&hit, chatID, peerTitle(chatID, names), links)
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// locateMessage records the chat a message was found in and its permalink
+// where the chat has one.
+func locateMessage(msg *Message, chatID int64, title string, links map[int64]string) {
+	msg.ChatID = chatID
+	msg.ChatTitle = title
+	if base := links[chatID]; base != "" {
+		msg.Link = fmt.Sprintf("%s/%d", base, msg.ID)
+	}
+}
+
+// groupByChat orders messages by chat, keeping chats in the order they
+// first appear and messages within a chat in their original order.
+func groupByChat(messages []Message) []Message {
+	var order []int64
+	byChat := make(map[int64][]Message)
+	for _, msg := range messages {
+		if _, ok := byChat[msg.ChatID]; !ok {
+			order = append(order, msg.ChatID)
+		}
+		byChat[msg.ChatID] = append(byChat[msg.ChatID], msg)
+	}
+	grouped := make([]Message, 0, len(messages))
+	for _, chatID := range order {
+		grouped = append(grouped, byChat[chatID]...)
+	}
+	return grouped
+}
+
+// lastMessage returns the last regular message of a page, which is the
+// offset of the next search page.
+func lastMessage(msgs []tg.MessageClass) (*tg.Message, bool) {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msg, ok := msgs[i].(*tg.Message); ok {
+			return msg, true
+		}
+	}
+	return nil, false
+}
+
+// addPermalinkBases records the base of the message links of channels and
+// supergroups: the public username when there is one, the t.me/c form that
+// works for members otherwise. Private chats and basic groups have no
+// message links.
+func addPermalinkBases(bases map[int64]string, chats []tg.ChatClass) {
+	for _, ch := range chats {
+		channel, ok := ch.(*tg.Channel)
+		if !ok {
+			continue
+		}
+		if username := channelUsername(channel); username != "" {
+			bases[channel.ID] = "https://t.me/" + username
+			continue
+		}
+		bases[channel.ID] = fmt.Sprintf("https://t.me/c/%d", channel.ID)
+	}
+}
+
+// channelUsername returns the public username of a channel, falling back
+// to its first active collectible username.
+func channelUsername(channel *tg.Channel) string {
+	if channel.Username != "" {
+		return channel.Username
+	}
+	for _, username := range channel.Usernames {
+		if username.Active {
+			return username.Username
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestParseSearchScope(t *testing.T) {
+	for input, want := range map[string]SearchScope{"": SearchAll, "groups": SearchGroups, "channels": SearchChannels, "private": SearchPrivate} {
+		got, err := ParseSearchScope(input)
+		if err != nil || got != want {
+			t.Errorf("ParseSearchScope(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseSearchScope("bots"); err == nil {
+		t.Fatal("expected an error for an unknown scope")
+	}
+}
+
+func TestSearchHits(t *testing.T) {
+	chats := []tg.ChatClass{
+		&tg.Channel{ID: 100, Title: "Go News", Username: "gonews"},
+		&tg.Channel{ID: 200, Title: "Team", Usernames: []tg.Username{{Username: "old"}, {Username: "team", Active: true}}},
+		&tg.Channel{ID: 300, Title: "Private Group"},
+		&tg.Chat{ID: 400, Title: "Basic Group"},
+	}
+	users := []tg.UserClass{&tg.User{ID: 7, FirstName: "Alice"}}
+	names := make(map[int64]string)
+	links := make(map[int64]string)
+	addPeerNames(names, users, chats)
+	addPermalinkBases(links, chats)
+
+	msgs := []tg.MessageClass{
+		&tg.Message{ID: 1, PeerID: &tg.PeerChannel{ChannelID: 100}, Message: "a"},
+		&tg.Message{ID: 2, PeerID: &tg.PeerChannel{ChannelID: 200}, Message: "b"},
+		&tg.Message{ID: 3, PeerID: &tg.PeerChannel{ChannelID: 300}, Message: "c"},
+		&tg.Message{ID: 4, PeerID: &tg.PeerChat{ChatID: 400}, Message: "d"},
+		&tg.Message{ID: 5, PeerID: &tg.PeerUser{UserID: 7}, Message: "e"},
+		&tg.Message{ID: 6, PeerID: &tg.PeerUser{UserID: 7}},
+		&tg.MessageService{ID: 7, PeerID: &tg.PeerUser{UserID: 7}},
+	}
+	hits := searchHits(msgs, names, links, hasContent)
+
+	want := []struct {
+		group string
+		link  string
+	}{
+		{"Go News", "https://t.me/gonews/1"},
+		{"Team", "https://t.me/team/2"},
+		{"Private Group", "https://t.me/c/300/3"},
+		{"Basic Group", ""},
+		{"Alice", ""},
+	}
+	if len(hits) != len(want) {
+		t.Fatalf("expected %d hits, got %d", len(want), len(hits))
+	}
+	for i, w := range want {
+		if hits[i].ChatTitle != w.group || hits[i].Link != w.link {
+			t.Errorf("hit %d = %q %q, want %q %q", i, hits[i].ChatTitle, hits[i].Link, w.group, w.link)
+		}
+	}
+}
+
+func TestGroupByChat(t *testing.T) {
+	messages := []Message{{ID: 5, ChatID: 1}, {ID: 4, ChatID: 2}, {ID: 3, ChatID: 1}, {ID: 2, ChatID: 3}, {ID: 1, ChatID: 2}}
+	grouped := groupByChat(messages)
+
+	wantIDs := []int{5, 3, 4, 1, 2}
+	for i, id := range wantIDs {
+		if grouped[i].ID != id {
+			t.Fatalf("unexpected order: %+v", grouped)
+		}
+	}
+}
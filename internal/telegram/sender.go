@@ -0,0 +1,158 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// GetSenderMessages fetches the messages a user sent in every chat shared
+// with them, between since and until; zero since or until leave the range
+// open. user is a numeric user ID or an @username. Messages of all chats
+// are merged newest first, each located in its chat. Chats that cannot be
+// searched, e.g. private channels, are skipped with a progress note.
+func (c *Client) GetSenderMessages(ctx context.Context, user string, since, until time.Time, progress ProgressFunc) ([]Message, error) {
+	inputUser, err := c.resolveUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	chats, err := c.commonChats(ctx, inputUser)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string)
+	links := make(map[int64]string)
+	addPeerNames(names, nil, chats)
+	addPermalinkBases(links, chats)
+	peers := inputPeers(nil, chats)
+	from := &tg.InputPeerUser{UserID: inputUser.UserID, AccessHash: inputUser.AccessHash}
+
+	var all []Message
+	for _, chat := range chats {
+		// Chats the user was removed from cannot be searched.
+		switch chat.(type) {
+		case *tg.Chat, *tg.Channel:
+		default:
+			continue
+		}
+		chatID := chat.GetID()
+		title := peerTitle(chatID, names)
+		messages, err := c.pageMessages(
+			ctx,
+			progress,
+			"sender in "+title,
+			0,
+			0,
+			nil,
+			false,
+			senderSearchFetchFunc(ctx, c, peers[chatID], from, since, until),
+			textMessageFilter,
+		)
+		if err != nil {
+			if ctx.Err() != nil {
+				return mergeByDate(all), err
+			}
+			reportProgress(progress, ProgressUpdate{Phase: fmt.Sprintf("skipped %s: %v", title, err)})
+			continue
+		}
+		for i := range messages {
+			locateMessage(&messages[i], chatID, title, links)
+		}
+		all = append(all, messages...)
+	}
+	return mergeByDate(all), nil
+}
+
+// resolveUser finds a user by numeric ID or @username. IDs only resolve
+// for users seen before, e.g. in dialogs or message pages.
+func (c *Client) resolveUser(ctx context.Context, ref string) (*tg.InputUser, error) {
+	ref = strings.TrimSpace(ref)
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		c.usersMu.Lock()
+		user := c.userCache[id]
+		c.usersMu.Unlock()
+		if user != nil {
+			return &tg.InputUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+		}
+		if peer, err := c.inputPeer(id); err == nil {
+			if userPeer, ok := peer.(*tg.InputPeerUser); ok {
+				return &tg.InputUser{UserID: userPeer.UserID, AccessHash: userPeer.AccessHash}, nil
+			}
+		}
+		return nil, fmt.Errorf("user %d not found; use their @username instead", id)
+	}
+
+	username := strings.TrimPrefix(ref, "@")
+	resolved, err := c.ctx.Raw.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve @%s: %w", username, err)
+	}
+	c.rememberUsers(resolved.Users)
+	peer, ok := resolved.Peer.(*tg.PeerUser)
+	if !ok {
+		return nil, fmt.Errorf("@%s is not a user", username)
+	}
+	for _, u := range resolved.Users {
+		if user, ok := u.(*tg.User); ok && user.ID == peer.UserID {
+			return &tg.InputUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+		}
+	}
+	return nil, fmt.Errorf("@%s not found", username)
+}
+
+// commonChats lists the groups and channels shared with user.
+func (c *Client) commonChats(ctx context.Context, user *tg.InputUser) ([]tg.ChatClass, error) {
+	const batchSize = 100
+	request := &tg.MessagesGetCommonChatsRequest{UserID: user, Limit: batchSize}
+
+	var chats []tg.ChatClass
+	for {
+		result, err := c.ctx.Raw.MessagesGetCommonChats(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get common chats: %w", err)
+		}
+		page := result.GetChats()
+		chats = append(chats, page...)
+		if len(page) < batchSize {
+			return chats, nil
+		}
+		request.MaxID = page[len(page)-1].GetID()
+	}
+}
+
+func senderSearchFetchFunc(ctx context.Context, c *Client, peer, from tg.InputPeerClass, since, until time.Time) func(offsetID, offsetDate, limit int) (tg.MessagesMessagesClass, error) {
+	return func(offsetID, _, limit int) (tg.MessagesMessagesClass, error) {
+		request := &tg.MessagesSearchRequest{
+			Peer:     peer,
+			Filter:   &tg.InputMessagesFilterEmpty{},
+			OffsetID: offsetID,
+			Limit:    limit,
+		}
+		request.SetFromID(from)
+		if !since.IsZero() {
+			request.MinDate = int(since.Unix())
+		}
+		if !until.IsZero() {
+			request.MaxDate = int(until.Unix())
+		}
+		result, err := c.ctx.Raw.MessagesSearch(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search messages: %w", err)
+		}
+		return result, nil
+	}
+}
+
+// mergeByDate orders messages from several chats newest first.
+func mergeByDate(messages []Message) []Message {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Date.After(messages[j].Date)
+	})
+	return messages
+}
@@ -0,0 +1,39 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestMergeByDate(t *testing.T) {
+	base := time.Unix(1000, 0)
+	messages := []Message{
+		{ID: 1, ChatID: 1, Date: base},
+		{ID: 2, ChatID: 1, Date: base.Add(2 * time.Minute)},
+		{ID: 9, ChatID: 2, Date: base.Add(time.Minute)},
+		{ID: 8, ChatID: 2, Date: base.Add(3 * time.Minute)},
+	}
+	merged := mergeByDate(messages)
+
+	wantIDs := []int{8, 2, 9, 1}
+	for i, id := range wantIDs {
+		if merged[i].ID != id {
+			t.Fatalf("unexpected order: %+v", merged)
+		}
+	}
+}
+
+func TestResolveUser_CachedID(t *testing.T) {
+	client := &Client{userCache: map[int64]*tg.User{7: {ID: 7, AccessHash: 42, FirstName: "Alice"}}}
+
+	user, err := client.resolveUser(context.Background(), " 7 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.UserID != 7 || user.AccessHash != 42 {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
@@ -0,0 +1,243 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// StreamUnreadMessages yields unread messages oldest first, one page at a time,
+// without collecting them in memory.
+func (c *Client) StreamUnreadMessages(ctx context.Context, chatID int64, lastReadID int, progress ProgressFunc) iter.Seq2[Message, error] {
+	return c.streamPeer(chatID, func(inputPeer tg.InputPeerClass) iter.Seq2[Message, error] {
+		return c.streamMessages(
+			ctx,
+			progress,
+			"unread",
+			lastReadID+1,
+			time.Time{},
+			historyPageFunc(ctx, c, inputPeer),
+			unreadStreamFilter(lastReadID, false),
+		)
+	})
+}
+
+// StreamTopicMessages yields unread topic messages oldest first.
+func (c *Client) StreamTopicMessages(ctx context.Context, chatID int64, topicID int, lastReadID int, progress ProgressFunc) iter.Seq2[Message, error] {
+	return c.streamPeer(chatID, func(inputPeer tg.InputPeerClass) iter.Seq2[Message, error] {
+		return c.streamMessages(
+			ctx,
+			progress,
+			"topic-unread",
+			lastReadID+1,
+			time.Time{},
+			topicPageFunc(ctx, c, inputPeer, topicID),
+			unreadStreamFilter(lastReadID, topicID == 1),
+		)
+	})
+}
+
+// StreamMessagesByDate yields messages within a date range oldest first.
+// For supergroups upgraded from a basic group, the original group's
+// messages come first, followed by a migration marker.
+func (c *Client) StreamMessagesByDate(ctx context.Context, chatID int64, since, until time.Time, progress ProgressFunc) iter.Seq2[Message, error] {
+	return c.streamPeer(chatID, func(inputPeer tg.InputPeerClass) iter.Seq2[Message, error] {
+		return c.streamWithLegacyHistory(ctx, inputPeer, since, until, progress, c.streamMessages(
+			ctx,
+			progress,
+			"date-range",
+			0,
+			since,
+			historyPageFunc(ctx, c, inputPeer),
+			dateRangeStreamFilter(since, until, false),
+		))
+	})
+}
+
+// StreamTopicMessagesByDate yields topic messages within a date range oldest first.
+func (c *Client) StreamTopicMessagesByDate(ctx context.Context, chatID int64, topicID int, since, until time.Time, progress ProgressFunc) iter.Seq2[Message, error] {
+	return c.streamPeer(chatID, func(inputPeer tg.InputPeerClass) iter.Seq2[Message, error] {
+		return c.streamMessages(
+			ctx,
+			progress,
+			"topic-date-range",
+			0,
+			since,
+			topicPageFunc(ctx, c, inputPeer, topicID),
+			dateRangeStreamFilter(since, until, topicID == 1),
+		)
+	})
+}
+
+type pageFunc func(offsetID, offsetDate, addOffset, limit int) (tg.MessagesMessagesClass, error)
+
+func historyPageFunc(ctx context.Context, c *Client, inputPeer tg.InputPeerClass) pageFunc {
+	return func(offsetID, offsetDate, addOffset, limit int) (tg.MessagesMessagesClass, error) {
+		return c.ctx.Raw.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+			Peer:       inputPeer,
+			Limit:      limit,
+			OffsetID:   offsetID,
+			OffsetDate: offsetDate,
+			AddOffset:  addOffset,
+		})
+	}
+}
+
+func topicPageFunc(ctx context.Context, c *Client, inputPeer tg.InputPeerClass, topicID int) pageFunc {
+	if topicID == 1 {
+		return historyPageFunc(ctx, c, inputPeer)
+	}
+	return func(offsetID, offsetDate, addOffset, limit int) (tg.MessagesMessagesClass, error) {
+		return c.ctx.Raw.MessagesGetReplies(ctx, &tg.MessagesGetRepliesRequest{
+			Peer:       inputPeer,
+			MsgID:      topicID,
+			Limit:      limit,
+			OffsetID:   offsetID,
+			OffsetDate: offsetDate,
+			AddOffset:  addOffset,
+		})
+	}
+}
+
+func isOtherTopicMessage(msg *tg.Message) bool {
+	if msg.ReplyTo == nil {
+		return false
+	}
+	reply, ok := msg.ReplyTo.(*tg.MessageReplyHeader)
+	return ok && reply.ReplyToTopID != 0
+}
+
+func unreadStreamFilter(lastReadID int, generalTopic bool) func(msg *tg.Message) (bool, bool) {
+	return func(msg *tg.Message) (bool, bool) {
+		if generalTopic && isOtherTopicMessage(msg) {
+			return false, false
+		}
+		if msg.ID <= lastReadID || !hasContent(msg) || msg.Out {
+			return false, false
+		}
+		return true, false
+	}
+}
+
+func dateRangeStreamFilter(since, until time.Time, generalTopic bool) func(msg *tg.Message) (bool, bool) {
+	return func(msg *tg.Message) (bool, bool) {
+		msgTime := time.Unix(int64(msg.Date), 0)
+		if msgTime.After(until) {
+			return false, true // Stop (paging forward, everything after is newer)
+		}
+		if generalTopic && isOtherTopicMessage(msg) {
+			return false, false
+		}
+		if msgTime.Before(since) || !hasContent(msg) || msg.Out {
+			return false, false
+		}
+		return true, false
+	}
+}
+
+// streamPeer resolves the peer up front and turns a lookup failure into
+// the iterator's first (and only) error.
+func (c *Client) streamPeer(chatID int64, stream func(tg.InputPeerClass) iter.Seq2[Message, error]) iter.Seq2[Message, error] {
+	inputPeer, err := c.inputPeer(chatID)
+	if err != nil {
+		return func(yield func(Message, error) bool) {
+			yield(Message{}, err)
+		}
+	}
+	return stream(inputPeer)
+}
+
+// streamMessages pages forward from startID (or startDate when startID is 0)
+// and yields matching messages oldest first. Each page is requested with a
+// negative add_offset so Telegram returns messages newer than the offset.
+func (c *Client) streamMessages(
+	ctx context.Context,
+	progress ProgressFunc,
+	phase string,
+	startID int,
+	startDate time.Time,
+	fetch pageFunc,
+	filter func(msg *tg.Message) (process bool, stop bool),
+) iter.Seq2[Message, error] {
+	return func(yield func(Message, error) bool) {
+		const batchSize = 100
+		self := c.self()
+		outgoing := make(map[int]bool)
+		offsetID := startID
+		offsetDate := 0
+		if offsetID == 0 && !startDate.IsZero() {
+			offsetDate = int(startDate.Unix())
+			reportProgress(progress, ProgressUpdate{
+				Phase: fmt.Sprintf("jumped to date %s", startDate.Format("2006-01-02")),
+			})
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(Message{}, fmt.Errorf("%w: %w", ErrFetchCanceled, err))
+				return
+			}
+
+			result, err := fetch(offsetID, offsetDate, -batchSize, batchSize)
+			if err != nil {
+				if ctx.Err() != nil {
+					err = fmt.Errorf("%w: %w", ErrFetchCanceled, ctx.Err())
+				}
+				yield(Message{}, err)
+				return
+			}
+
+			msgs, users := extractMessagesAndUsers(result)
+			if len(msgs) == 0 {
+				return
+			}
+			c.rememberUsers(users)
+
+			// Pages come newest first; walk them backwards to yield in order.
+			// Messages come oldest first, so replies follow the outgoing
+			// messages they reply to.
+			addOutgoingIDs(outgoing, msgs)
+			parsed := 0
+			lastID := offsetID
+			stop := false
+			for i := len(msgs) - 1; i >= 0; i-- {
+				if id := msgs[i].GetID(); id > lastID {
+					lastID = id
+				}
+				msg, ok := msgs[i].(*tg.Message)
+				if !ok {
+					continue
+				}
+				process, stopHere := filter(msg)
+				if stopHere {
+					stop = true
+					break
+				}
+				if !process {
+					continue
+				}
+				parsed++
+				message := newMessage(msg)
+				markAttention(&message, msg, self, outgoing)
+				if !yield(message, nil) {
+					return
+				}
+			}
+			reportProgress(progress, ProgressUpdate{
+				Phase:   phase,
+				Parsed:  parsed,
+				Scanned: len(msgs),
+				Batch:   1,
+			})
+
+			if stop || len(msgs) < batchSize {
+				return
+			}
+			offsetID = lastID + 1
+			offsetDate = 0
+		}
+	}
+}
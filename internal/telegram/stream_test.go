@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestStreamMessages_PagesForwardOldestFirst(t *testing.T) {
+	client := &Client{}
+
+	var seenOffsets []int
+	var seenAddOffsets []int
+	fetch := func(offsetID, offsetDate, addOffset, limit int) (tg.MessagesMessagesClass, error) {
+		seenOffsets = append(seenOffsets, offsetID)
+		seenAddOffsets = append(seenAddOffsets, addOffset)
+		switch len(seenOffsets) {
+		case 1:
+			// A full page, newest first, of IDs 110..11.
+			msgs := make([]tg.MessageClass, 0, limit)
+			for i := 0; i < limit; i++ {
+				msgs = append(msgs, &tg.Message{ID: 110 - i, Date: 1000 + 110 - i, Message: "a"})
+			}
+			return &tg.MessagesMessages{Messages: msgs}, nil
+		default:
+			return &tg.MessagesMessages{
+				Messages: []tg.MessageClass{
+					&tg.Message{ID: 112, Date: 2000, Message: "c"},
+					&tg.Message{ID: 111, Date: 1999, Message: "b"},
+				},
+			}, nil
+		}
+	}
+	filter := func(msg *tg.Message) (bool, bool) {
+		return true, false
+	}
+
+	var ids []int
+	for msg, err := range client.streamMessages(context.Background(), nil, "phase", 11, time.Time{}, fetch, filter) {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+		ids = append(ids, msg.ID)
+	}
+
+	if len(ids) != 102 {
+		t.Fatalf("expected 102 messages, got %d", len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("messages not oldest first at %d: %d after %d", i, ids[i], ids[i-1])
+		}
+	}
+	if len(seenOffsets) != 2 || seenOffsets[0] != 11 || seenOffsets[1] != 111 {
+		t.Fatalf("unexpected offsets: %v", seenOffsets)
+	}
+	for _, addOffset := range seenAddOffsets {
+		if addOffset != -100 {
+			t.Fatalf("unexpected add offsets: %v", seenAddOffsets)
+		}
+	}
+}
+
+func TestStreamMessages_StopsWhenFilterStops(t *testing.T) {
+	client := &Client{}
+
+	until := time.Unix(1500, 0)
+	fetch := func(offsetID, offsetDate, addOffset, limit int) (tg.MessagesMessagesClass, error) {
+		return &tg.MessagesMessages{
+			Messages: []tg.MessageClass{
+				&tg.Message{ID: 3, Date: 1600, Message: "late"},
+				&tg.Message{ID: 2, Date: 1400, Message: "b"},
+				&tg.Message{ID: 1, Date: 1300, Message: "a"},
+			},
+		}, nil
+	}
+
+	var texts []string
+	for msg, err := range client.streamMessages(context.Background(), nil, "phase", 0, time.Unix(1000, 0), fetch, dateRangeStreamFilter(time.Unix(1000, 0), until, false)) {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+		texts = append(texts, msg.Text)
+	}
+	if len(texts) != 2 || texts[0] != "a" || texts[1] != "b" {
+		t.Fatalf("unexpected messages: %v", texts)
+	}
+}
+
+func TestStreamMessages_YieldsCanceledError(t *testing.T) {
+	client := &Client{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fetch := func(offsetID, offsetDate, addOffset, limit int) (tg.MessagesMessagesClass, error) {
+		t.Fatal("fetch should not be called after cancel")
+		return nil, nil
+	}
+
+	var gotErr error
+	for _, err := range client.streamMessages(ctx, nil, "phase", 1, time.Time{}, fetch, unreadStreamFilter(0, false)) {
+		gotErr = err
+	}
+	if !errors.Is(gotErr, ErrFetchCanceled) {
+		t.Fatalf("expected ErrFetchCanceled, got %v", gotErr)
+	}
+}
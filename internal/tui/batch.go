@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type batchState int
+
+const (
+	batchPending batchState = iota
+	batchRunning
+	batchExported
+	batchFailed
+	batchSkipped
+)
+
+type batchItem struct {
+	title  string
+	state  batchState
+	status string
+}
+
+// BatchModel shows the progress of exporting several chats or topics one
+// after another: the status of every item and the fetch progress of the
+// one being exported.
+type BatchModel struct {
+	title    string
+	items    []batchItem
+	progress ProgressModel
+	// canceling is set once the user asked to stop; the remaining items
+	// are skipped.
+	canceling bool
+	complete  bool
+	done      bool
+}
+
+// NewBatchModel creates the progress view of a batch export of the items
+// with the given titles.
+func NewBatchModel(title string, titles []string) BatchModel {
+	items := make([]batchItem, len(titles))
+	for i, itemTitle := range titles {
+		items[i] = batchItem{title: itemTitle}
+	}
+	return BatchModel{title: title, items: items}
+}
+
+// Start shows item index as running, with the fetch progress sent on msgCh.
+func (m BatchModel) Start(index int, msgCh <-chan tea.Msg) (BatchModel, tea.Cmd) {
+	m.items[index].state = batchRunning
+	m.progress = NewProgressModel(m.items[index].title, msgCh)
+	return m, m.progress.Init()
+}
+
+// Finish records the outcome of item index.
+func (m BatchModel) Finish(index int, status string, failed bool) BatchModel {
+	m.items[index].state = batchExported
+	if failed {
+		m.items[index].state = batchFailed
+	}
+	m.items[index].status = status
+	return m
+}
+
+// Skip marks every item that has not run yet as skipped.
+func (m BatchModel) Skip(status string) BatchModel {
+	for i := range m.items {
+		if m.items[i].state == batchPending {
+			m.items[i].state = batchSkipped
+			m.items[i].status = status
+		}
+	}
+	return m
+}
+
+// Complete shows the final status of all items until the user confirms.
+func (m BatchModel) Complete() BatchModel {
+	m.complete = true
+	return m
+}
+
+// Cancel marks the batch as stopping.
+func (m BatchModel) Cancel() BatchModel {
+	m.canceling = true
+	m.progress = m.progress.Cancel()
+	return m
+}
+
+func (m BatchModel) Canceling() bool {
+	return m.canceling
+}
+
+func (m BatchModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m BatchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.complete {
+		if key, ok := msg.(tea.KeyMsg); ok && (key.String() == "enter" || key.String() == "esc") {
+			m.done = true
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	var updated tea.Model
+	updated, cmd = m.progress.Update(msg)
+	m.progress = updated.(ProgressModel)
+	return m, cmd
+}
+
+func (m BatchModel) View() string {
+	finished := 0
+	for _, item := range m.items {
+		if item.state == batchExported || item.state == batchFailed {
+			finished++
+		}
+	}
+	lines := []string{progressTitleStyle.Render(fmt.Sprintf("%s (%d of %d done)", m.title, finished, len(m.items)))}
+	for _, item := range m.items {
+		lines = append(lines, progressInfoStyle.Render(m.itemLine(item)))
+	}
+	switch {
+	case m.complete:
+		lines = append(lines, helpStyle.Render("enter: back to the chat list"))
+	case m.canceling:
+		lines = append(lines, progressInfoStyle.Render("Canceling, waiting for the current batch..."))
+	default:
+		lines = append(lines, helpStyle.Render("esc: stop and skip the remaining items"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m BatchModel) itemLine(item batchItem) string {
+	switch item.state {
+	case batchRunning:
+		line := fmt.Sprintf("%s %s: parsed %d messages", m.progress.spinner.View(), item.title, m.progress.parsed)
+		if m.progress.scanned > 0 {
+			line += fmt.Sprintf(" (scanned %d in %d batches)", m.progress.scanned, m.progress.batches)
+		}
+		if m.progress.phase != "" {
+			line += ", " + m.progress.phase
+		}
+		return line
+	case batchExported:
+		return "done " + item.title + ": " + item.status
+	case batchFailed:
+		return "failed " + item.title + ": " + item.status
+	case batchSkipped:
+		return "skip " + item.title + ": " + item.status
+	default:
+		return "wait " + item.title
+	}
+}
+
+func (m BatchModel) Done() bool {
+	return m.done
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ConfirmModel asks a yes/no question and records the answer.
+type ConfirmModel struct {
+	title     string
+	body      string
+	confirmed bool
+	done      bool
+}
+
+func NewConfirmModel(title, body string) ConfirmModel {
+	return ConfirmModel{title: title, body: body}
+}
+
+func (m ConfirmModel) Init() tea.Cmd { return nil }
+
+func (m ConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "y", "Y", "enter":
+		m.confirmed = true
+		m.done = true
+	case "n", "N", "esc":
+		m.done = true
+	}
+	return m, nil
+}
+
+func (m ConfirmModel) View() string {
+	var b strings.Builder
+	if m.title != "" {
+		b.WriteString(m.title)
+		b.WriteString("\n\n")
+	}
+	if m.body != "" {
+		b.WriteString(m.body)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("y/enter: yes  n/esc: no")
+	return b.String()
+}
+
+func (m ConfirmModel) Done() bool {
+	return m.done
+}
+
+func (m ConfirmModel) Confirmed() bool {
+	return m.confirmed
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"time"
@@ -25,6 +26,7 @@ var (
 	quitTextStyle     = lipgloss.NewStyle().Margin(1, 0, 2, 4)
 	errorStyle        = lipgloss.NewStyle().MarginLeft(2).Foreground(lipgloss.Color("160"))
 	statusBarStyle    = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("62"))
+	inactiveItemStyle = lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("241"))
 )
 
 const (
@@ -40,7 +42,11 @@ type item struct {
 
 func (i item) FilterValue() string { return i.chat.Title }
 
-type itemDelegate struct{}
+// itemDelegate renders chats; marked holds the IDs of the chats selected
+// for a multi-chat export.
+type itemDelegate struct {
+	marked map[int64]bool
+}
 
 func (d itemDelegate) Height() int                             { return 1 }
 func (d itemDelegate) Spacing() int                            { return 0 }
@@ -52,8 +58,18 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	}
 
 	str := fmt.Sprintf("%s (%d unread)", i.chat.Title, i.chat.UnreadCount)
+	if d.marked[i.chat.ID] {
+		str = "[x] " + str
+	}
+	status := i.chat.Status()
+	if status != "" {
+		str += " [" + status + "]"
+	}
 
 	fn := itemStyle.Render
+	if status != "" {
+		fn = inactiveItemStyle.Render
+	}
 	if index == m.Index() {
 		fn = func(s ...string) string {
 			return selectedItemStyle.Render("> " + strings.Join(s, " "))
@@ -68,6 +84,11 @@ type ExportMode int
 const (
 	ModeUnread ExportMode = iota
 	ModeDateRange
+	ModeLastN
+	ModeMentions
+	ModeReactions
+	ModePinned
+	ModeAdminLog
 )
 
 type viewState int
@@ -77,12 +98,18 @@ const (
 	stateModeList
 	stateSinceInput
 	stateUntilInput
+	stateLastInput
+	stateContextInput
 )
 
 type ModelOptions struct {
 	Mode  ExportMode
 	Since time.Time
 	Until time.Time
+	Last  int
+	// Context is the number of already read messages to include before
+	// the first unread one.
+	Context int
 }
 
 type Model struct {
@@ -92,6 +119,9 @@ type Model struct {
 	quitting     bool
 	done         bool
 	canceled     bool
+	search       bool
+	inbox        bool
+	marked       map[int64]bool
 	markReadFunc func(telegram.Chat) error
 	statusMsg    string
 	errorMsg     string
@@ -99,8 +129,12 @@ type Model struct {
 	state        viewState
 	sinceInput   textinput.Model
 	untilInput   textinput.Model
+	lastInput    textinput.Model
+	contextInput textinput.Model
 	since        time.Time
 	until        time.Time
+	last         int
+	context      int
 }
 
 type statusClearMsg struct{}
@@ -122,7 +156,8 @@ func NewModel(chats []telegram.Chat, markReadFunc func(telegram.Chat) error, opt
 		items[i] = item{chat: chat}
 	}
 
-	l := list.New(items, itemDelegate{}, defaultListWidth, defaultListHeight)
+	marked := make(map[int64]bool)
+	l := list.New(items, itemDelegate{marked: marked}, defaultListWidth, defaultListHeight)
 	l.Title = "Select Chat to Summarize"
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
@@ -143,6 +178,26 @@ func NewModel(chats []telegram.Chat, markReadFunc func(telegram.Chat) error, opt
 				key.WithKeys("m"),
 				key.WithHelp("m", "mode"),
 			),
+			key.NewBinding(
+				key.WithKeys("c"),
+				key.WithHelp("c", "read context"),
+			),
+			key.NewBinding(
+				key.WithKeys("s"),
+				key.WithHelp("s", "search all chats"),
+			),
+			key.NewBinding(
+				key.WithKeys("i"),
+				key.WithHelp("i", "inbox digest of unread chats"),
+			),
+			key.NewBinding(
+				key.WithKeys(" "),
+				key.WithHelp("space", "select for export"),
+			),
+			key.NewBinding(
+				key.WithKeys("a"),
+				key.WithHelp("a", "select all listed"),
+			),
 		}
 	}
 	l.AdditionalShortHelpKeys = func() []key.Binding {
@@ -161,6 +216,11 @@ func NewModel(chats []telegram.Chat, markReadFunc func(telegram.Chat) error, opt
 	modeItems := []list.Item{
 		modeItem{mode: ModeUnread, label: "Unread"},
 		modeItem{mode: ModeDateRange, label: "Date range"},
+		modeItem{mode: ModeLastN, label: "Last N messages"},
+		modeItem{mode: ModeMentions, label: "Unread mentions"},
+		modeItem{mode: ModeReactions, label: "Unread reactions"},
+		modeItem{mode: ModePinned, label: "Pinned messages"},
+		modeItem{mode: ModeAdminLog, label: "Admin log"},
 	}
 	modeList := list.New(modeItems, list.NewDefaultDelegate(), defaultListWidth, defaultListHeight)
 	modeList.Title = "Select Export Mode"
@@ -180,8 +240,18 @@ func NewModel(chats []telegram.Chat, markReadFunc func(telegram.Chat) error, opt
 	untilInput.CharLimit = 10
 	untilInput.Width = 12
 
+	lastInput := textinput.New()
+	lastInput.Placeholder = "500"
+	lastInput.CharLimit = 7
+	lastInput.Width = 9
+
+	contextInput := textinput.New()
+	contextInput.Placeholder = "0"
+	contextInput.CharLimit = 5
+	contextInput.Width = 7
+
 	mode := opts.Mode
-	if mode != ModeDateRange {
+	if mode == ModeLastN && opts.Last <= 0 {
 		mode = ModeUnread
 	}
 
@@ -191,17 +261,28 @@ func NewModel(chats []telegram.Chat, markReadFunc func(telegram.Chat) error, opt
 	if !opts.Until.IsZero() {
 		untilInput.SetValue(opts.Until.Format("2006-01-02"))
 	}
+	if opts.Last > 0 {
+		lastInput.SetValue(strconv.Itoa(opts.Last))
+	}
+	if opts.Context > 0 {
+		contextInput.SetValue(strconv.Itoa(opts.Context))
+	}
 
 	return Model{
 		list:         l,
 		modeList:     modeList,
+		marked:       marked,
 		markReadFunc: markReadFunc,
 		mode:         mode,
 		state:        stateChatList,
 		sinceInput:   sinceInput,
 		untilInput:   untilInput,
+		lastInput:    lastInput,
+		contextInput: contextInput,
 		since:        opts.Since,
 		until:        opts.Until,
+		last:         opts.Last,
+		context:      opts.Context,
 	}
 }
 
@@ -233,7 +314,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.untilInput.Blur()
 						return m, textinput.Blink
 					}
-					m.mode = ModeUnread
+					if i.mode == ModeLastN {
+						m.state = stateLastInput
+						m.errorMsg = ""
+						m.lastInput.Focus()
+						return m, textinput.Blink
+					}
+					m.mode = i.mode
 					m.state = stateChatList
 				}
 				return m, nil
@@ -302,6 +389,61 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.untilInput.Blur()
 				return m, nil
 			}
+		case stateLastInput:
+			switch keypress := msg.String(); keypress {
+			case "ctrl+c":
+				m.quitting = true
+				m.done = true
+				m.canceled = true
+				return m, nil
+			case "esc":
+				m.errorMsg = ""
+				m.state = stateChatList
+				m.lastInput.Blur()
+				return m, nil
+			case "enter":
+				value := strings.TrimSpace(m.lastInput.Value())
+				count, err := strconv.Atoi(value)
+				if err != nil || count <= 0 {
+					m.errorMsg = "Enter a positive number of messages"
+					return m, nil
+				}
+				m.last = count
+				m.mode = ModeLastN
+				m.errorMsg = ""
+				m.state = stateChatList
+				m.lastInput.Blur()
+				return m, nil
+			}
+		case stateContextInput:
+			switch keypress := msg.String(); keypress {
+			case "ctrl+c":
+				m.quitting = true
+				m.done = true
+				m.canceled = true
+				return m, nil
+			case "esc":
+				m.errorMsg = ""
+				m.state = stateChatList
+				m.contextInput.Blur()
+				return m, nil
+			case "enter":
+				value := strings.TrimSpace(m.contextInput.Value())
+				count := 0
+				if value != "" {
+					var err error
+					count, err = strconv.Atoi(value)
+					if err != nil || count < 0 {
+						m.errorMsg = "Enter a number of messages (0 to disable)"
+						return m, nil
+					}
+				}
+				m.context = count
+				m.errorMsg = ""
+				m.state = stateChatList
+				m.contextInput.Blur()
+				return m, nil
+			}
 		default:
 			switch keypress := msg.String(); keypress {
 			case "ctrl+c", "esc":
@@ -328,9 +470,65 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 
+			case "c":
+				if m.list.FilterState() != list.Filtering {
+					m.state = stateContextInput
+					m.errorMsg = ""
+					m.contextInput.Focus()
+					return m, textinput.Blink
+				}
+
+			case "s":
+				if m.list.FilterState() != list.Filtering {
+					m.search = true
+					m.done = true
+					return m, nil
+				}
+
+			case "i":
+				if m.list.FilterState() != list.Filtering {
+					m.inbox = true
+					m.done = true
+					return m, nil
+				}
+
+			case " ":
+				if m.list.FilterState() != list.Filtering {
+					i, ok := m.list.SelectedItem().(item)
+					if !ok {
+						return m, nil
+					}
+					if err := i.chat.ExportError(); err != nil {
+						m.statusMsg = fmt.Sprintf("Error: %v", err)
+						return m, nil
+					}
+					if m.marked[i.chat.ID] {
+						delete(m.marked, i.chat.ID)
+					} else {
+						m.marked[i.chat.ID] = true
+					}
+					m.statusMsg = ""
+					return m, nil
+				}
+
+			case "a":
+				if m.list.FilterState() != list.Filtering {
+					m.toggleAll()
+					m.statusMsg = ""
+					return m, nil
+				}
+
 			case "enter":
+				if len(m.marked) > 0 {
+					m.done = true
+					return m, nil
+				}
 				i, ok := m.list.SelectedItem().(item)
 				if ok {
+					if err := i.chat.ExportError(); err != nil {
+						m.statusMsg = fmt.Sprintf("Error: %v", err)
+						return m, nil
+					}
 					m.selected = &i.chat
 				}
 				m.done = true
@@ -387,6 +585,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.sinceInput, cmd = m.sinceInput.Update(msg)
 	case stateUntilInput:
 		m.untilInput, cmd = m.untilInput.Update(msg)
+	case stateLastInput:
+		m.lastInput, cmd = m.lastInput.Update(msg)
+	case stateContextInput:
+		m.contextInput, cmd = m.contextInput.Update(msg)
 	default:
 		m.list, cmd = m.list.Update(msg)
 	}
@@ -408,9 +610,13 @@ func (m Model) View() string {
 		return renderDateInput("Start date (YYYY-MM-DD)", m.sinceInput, m.errorMsg)
 	case stateUntilInput:
 		return renderDateInput("End date (YYYY-MM-DD, optional)", m.untilInput, m.errorMsg)
+	case stateLastInput:
+		return renderDateInput("Number of messages", m.lastInput, m.errorMsg)
+	case stateContextInput:
+		return renderDateInput("Read messages to include before the first unread one", m.contextInput, m.errorMsg)
 	default:
 		view := m.list.View()
-		view += "\n" + renderStatusBar(m.mode, m.statusMsg, m.currentChat())
+		view += "\n" + renderStatusBar(m.modeStatus(), m.statusMsg, m.currentChat(), len(m.marked))
 		return view
 	}
 }
@@ -427,6 +633,62 @@ func (m Model) Canceled() bool {
 	return m.canceled
 }
 
+// SearchRequested reports whether the user left the chat list to search
+// all chats instead of selecting one.
+func (m Model) SearchRequested() bool {
+	return m.search
+}
+
+// InboxRequested reports whether the user left the chat list to export
+// the unread messages of all listed chats at once.
+func (m Model) InboxRequested() bool {
+	return m.inbox
+}
+
+// VisibleChats returns the chats currently listed, i.e. the ones matching
+// the filter when one is applied.
+func (m Model) VisibleChats() []telegram.Chat {
+	var chats []telegram.Chat
+	for _, listItem := range m.list.VisibleItems() {
+		if i, ok := listItem.(item); ok {
+			chats = append(chats, i.chat)
+		}
+	}
+	return chats
+}
+
+// MultiSelected returns the chats selected with space or a, in list order.
+// It is empty when a single chat was chosen with enter.
+func (m Model) MultiSelected() []telegram.Chat {
+	var chats []telegram.Chat
+	for _, listItem := range m.list.Items() {
+		if i, ok := listItem.(item); ok && m.marked[i.chat.ID] {
+			chats = append(chats, i.chat)
+		}
+	}
+	return chats
+}
+
+// toggleAll selects every listed chat that can be exported, or clears them
+// when all of them are selected already.
+func (m *Model) toggleAll() {
+	var ids []int64
+	allMarked := true
+	for _, listItem := range m.list.VisibleItems() {
+		if i, ok := listItem.(item); ok && i.chat.ExportError() == nil {
+			ids = append(ids, i.chat.ID)
+			allMarked = allMarked && m.marked[i.chat.ID]
+		}
+	}
+	for _, id := range ids {
+		if allMarked {
+			delete(m.marked, id)
+		} else {
+			m.marked[id] = true
+		}
+	}
+}
+
 func (m Model) GetExportMode() ExportMode {
 	return m.mode
 }
@@ -438,15 +700,49 @@ func (m Model) GetDateRange() (time.Time, time.Time, bool) {
 	return m.since, m.until, true
 }
 
+// GetLastCount returns the message count chosen for ModeLastN.
+func (m Model) GetLastCount() (int, bool) {
+	if m.mode != ModeLastN {
+		return 0, false
+	}
+	return m.last, true
+}
+
+// GetContextSize returns the number of already read messages to include
+// before the first unread one; 0 disables the context section.
+func (m Model) GetContextSize() int {
+	return m.context
+}
+
 func modeLabel(mode ExportMode) string {
 	switch mode {
 	case ModeDateRange:
 		return "Date range"
+	case ModeLastN:
+		return "Last N"
+	case ModeMentions:
+		return "Mentions"
+	case ModeReactions:
+		return "Reactions"
+	case ModePinned:
+		return "Pinned"
+	case ModeAdminLog:
+		return "Admin log"
 	default:
 		return "Unread"
 	}
 }
 
+func (m Model) modeStatus() string {
+	if m.mode == ModeLastN {
+		return fmt.Sprintf("Last %d", m.last)
+	}
+	if m.mode == ModeUnread && m.context > 0 {
+		return fmt.Sprintf("Unread (context %d)", m.context)
+	}
+	return modeLabel(m.mode)
+}
+
 func renderDateInput(title string, input textinput.Model, errMsg string) string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render(title))
@@ -461,11 +757,26 @@ func renderDateInput(title string, input textinput.Model, errMsg string) string
 	return b.String()
 }
 
-func renderStatusBar(mode ExportMode, statusMsg string, chat *telegram.Chat) string {
-	parts := []string{"Mode: " + modeLabel(mode)}
+func renderStatusBar(mode string, statusMsg string, chat *telegram.Chat, selected int) string {
+	parts := []string{"Mode: " + mode}
+	if selected > 0 {
+		parts = append(parts, fmt.Sprintf("Selected: %d", selected))
+	}
 	if chat != nil {
 		parts = append(parts, "Type: "+chatTypeLabel(*chat))
 		parts = append(parts, fmt.Sprintf("ID: %d", chat.ID))
+		if chat.UnreadMentions > 0 {
+			parts = append(parts, fmt.Sprintf("Mentions: %d", chat.UnreadMentions))
+		}
+		if chat.UnreadReactions > 0 {
+			parts = append(parts, fmt.Sprintf("Reactions: %d", chat.UnreadReactions))
+		}
+		if status := chat.Status(); status != "" {
+			parts = append(parts, "Status: "+status)
+		}
+		if chat.RestrictionReason != "" {
+			parts = append(parts, "Reason: "+chat.RestrictionReason)
+		}
 	}
 	if statusMsg != "" {
 		parts = append(parts, statusMsg)
@@ -524,20 +835,29 @@ func applyModeListSize(l *list.Model, msg tea.WindowSizeMsg, extraLines int) {
 
 // TopicModel is a TUI model for selecting forum topics
 type TopicModel struct {
-	list     list.Model
-	selected *telegram.Topic
-	quitting bool
-	done     bool
-	canceled bool
+	list      list.Model
+	selected  *telegram.Topic
+	marked    map[int]bool
+	allTopics bool
+	quitting  bool
+	done      bool
+	canceled  bool
 }
 
+// topicItem is a topic of the list, or the "All topics" entry at its top
+// when all is set; that entry counts the unread messages of all topics.
 type topicItem struct {
 	topic telegram.Topic
+	all   bool
 }
 
 func (i topicItem) FilterValue() string { return i.topic.Title }
 
-type topicItemDelegate struct{}
+// topicItemDelegate renders topics; marked holds the IDs of the topics
+// selected for a multi-topic export.
+type topicItemDelegate struct {
+	marked map[int]bool
+}
 
 func (d topicItemDelegate) Height() int                             { return 1 }
 func (d topicItemDelegate) Spacing() int                            { return 0 }
@@ -549,6 +869,9 @@ func (d topicItemDelegate) Render(w io.Writer, m list.Model, index int, listItem
 	}
 
 	str := fmt.Sprintf("%s (%d unread)", i.topic.Title, i.topic.UnreadCount)
+	if !i.all && d.marked[i.topic.ID] {
+		str = "[x] " + str
+	}
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -561,20 +884,40 @@ func (d topicItemDelegate) Render(w io.Writer, m list.Model, index int, listItem
 }
 
 func NewTopicModel(topics []telegram.Topic) TopicModel {
-	items := make([]list.Item, len(topics))
-	for i, topic := range topics {
-		items[i] = topicItem{topic: topic}
+	items := make([]list.Item, 0, len(topics)+1)
+	if len(topics) > 0 {
+		all := telegram.Topic{Title: "All topics"}
+		for _, topic := range topics {
+			all.UnreadCount += topic.UnreadCount
+		}
+		items = append(items, topicItem{topic: all, all: true})
+	}
+	for _, topic := range topics {
+		items = append(items, topicItem{topic: topic})
 	}
 
-	l := list.New(items, topicItemDelegate{}, defaultListWidth, defaultListHeight)
+	marked := make(map[int]bool)
+	l := list.New(items, topicItemDelegate{marked: marked}, defaultListWidth, defaultListHeight)
 	l.Title = "Select Topic to Summarize"
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
 	l.Styles.Title = titleStyle
 	l.Styles.PaginationStyle = paginationStyle
 	l.Styles.HelpStyle = helpStyle
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(
+				key.WithKeys(" "),
+				key.WithHelp("space", "select for export"),
+			),
+			key.NewBinding(
+				key.WithKeys("a"),
+				key.WithHelp("a", "select all listed"),
+			),
+		}
+	}
 
-	return TopicModel{list: l}
+	return TopicModel{list: l, marked: marked}
 }
 
 func (m TopicModel) Init() tea.Cmd {
@@ -603,9 +946,35 @@ func (m TopicModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
+		case " ":
+			if m.list.FilterState() != list.Filtering {
+				i, ok := m.list.SelectedItem().(topicItem)
+				if ok && !i.all {
+					if m.marked[i.topic.ID] {
+						delete(m.marked, i.topic.ID)
+					} else {
+						m.marked[i.topic.ID] = true
+					}
+				}
+				return m, nil
+			}
+
+		case "a":
+			if m.list.FilterState() != list.Filtering {
+				m.toggleAll()
+				return m, nil
+			}
+
 		case "enter":
+			if len(m.marked) > 0 {
+				m.done = true
+				return m, nil
+			}
 			i, ok := m.list.SelectedItem().(topicItem)
-			if ok {
+			switch {
+			case ok && i.all:
+				m.allTopics = true
+			case ok:
 				m.selected = &i.topic
 			}
 			m.done = true
@@ -625,6 +994,12 @@ func (m TopicModel) View() string {
 	if m.selected != nil {
 		return quitTextStyle.Render(fmt.Sprintf("Selected topic: %s", m.selected.Title))
 	}
+	if m.allTopics {
+		return quitTextStyle.Render("Selected all topics")
+	}
+	if len(m.marked) > 0 {
+		return m.list.View() + "\n" + statusBarStyle.Render(fmt.Sprintf("Selected: %d topics", len(m.marked)))
+	}
 	return m.list.View()
 }
 
@@ -632,6 +1007,44 @@ func (m TopicModel) GetSelected() *telegram.Topic {
 	return m.selected
 }
 
+// MultiSelected returns the topics selected with space or a, in list
+// order. It is empty when a single entry was chosen with enter.
+func (m TopicModel) MultiSelected() []telegram.Topic {
+	var topics []telegram.Topic
+	for _, listItem := range m.list.Items() {
+		if i, ok := listItem.(topicItem); ok && !i.all && m.marked[i.topic.ID] {
+			topics = append(topics, i.topic)
+		}
+	}
+	return topics
+}
+
+// toggleAll selects every listed topic, or clears them when all of them are
+// selected already.
+func (m *TopicModel) toggleAll() {
+	var ids []int
+	allMarked := true
+	for _, listItem := range m.list.VisibleItems() {
+		if i, ok := listItem.(topicItem); ok && !i.all {
+			ids = append(ids, i.topic.ID)
+			allMarked = allMarked && m.marked[i.topic.ID]
+		}
+	}
+	for _, id := range ids {
+		if allMarked {
+			delete(m.marked, id)
+		} else {
+			m.marked[id] = true
+		}
+	}
+}
+
+// AllTopicsSelected reports whether the "All topics" entry was chosen to
+// export every topic with activity into one file.
+func (m TopicModel) AllTopicsSelected() bool {
+	return m.allTopics
+}
+
 func (m TopicModel) Done() bool {
 	return m.done
 }
@@ -1,7 +1,9 @@
 package tui
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"cli-tg-chat-summary/internal/telegram"
 
@@ -314,13 +316,18 @@ func TestTopicModel_Update_Enter(t *testing.T) {
 	}
 	model := NewTopicModel(topics)
 
+	// The first entry is "All topics".
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyDown})
 	msg := tea.KeyMsg{Type: tea.KeyEnter}
-	newModel, cmd := model.Update(msg)
+	newModel, cmd := newModel.(TopicModel).Update(msg)
 
 	m := newModel.(TopicModel)
-	if m.selected == nil {
+	if m.selected == nil || m.selected.ID != 1 {
 		t.Error("expected selected to not be nil after Enter")
 	}
+	if m.AllTopicsSelected() {
+		t.Error("expected a single topic to be selected")
+	}
 	if !m.Done() || m.Canceled() {
 		t.Error("expected topic model to be done and not canceled after Enter")
 	}
@@ -329,6 +336,25 @@ func TestTopicModel_Update_Enter(t *testing.T) {
 	}
 }
 
+func TestTopicModel_AllTopics(t *testing.T) {
+	topics := []telegram.Topic{
+		{ID: 1, Title: "General", UnreadCount: 5},
+		{ID: 2, Title: "Off-topic", UnreadCount: 10},
+	}
+	model := NewTopicModel(topics)
+
+	first, ok := model.list.Items()[0].(topicItem)
+	if !ok || !first.all || first.topic.UnreadCount != 15 {
+		t.Fatalf("expected an All topics entry with 15 unread, got %+v", model.list.Items()[0])
+	}
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(TopicModel)
+	if !m.Done() || !m.AllTopicsSelected() || m.GetSelected() != nil {
+		t.Fatal("expected Enter on the first entry to select all topics")
+	}
+}
+
 func TestTopicModel_Update_WindowSize(t *testing.T) {
 	model := NewTopicModel([]telegram.Topic{{ID: 1, Title: "Test"}})
 
@@ -420,3 +446,273 @@ func TestSummaryModel_Update_Enter(t *testing.T) {
 		t.Error("expected done to be true")
 	}
 }
+
+func TestConfirmModel_Update(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  tea.KeyMsg
+		want bool
+	}{
+		{name: "yes", msg: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}, want: true},
+		{name: "enter", msg: tea.KeyMsg{Type: tea.KeyEnter}, want: true},
+		{name: "no", msg: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}, want: false},
+		{name: "esc", msg: tea.KeyMsg{Type: tea.KeyEsc}, want: false},
+	}
+
+	for _, tt := range tests {
+		model := NewConfirmModel("Resume export?", "body")
+		newModel, _ := model.Update(tt.msg)
+		m := newModel.(ConfirmModel)
+		if !m.Done() {
+			t.Errorf("%s: expected done", tt.name)
+		}
+		if m.Confirmed() != tt.want {
+			t.Errorf("%s: Confirmed() = %v, want %v", tt.name, m.Confirmed(), tt.want)
+		}
+	}
+}
+
+func TestModel_LastNMode(t *testing.T) {
+	model := NewModel([]telegram.Chat{{ID: 1, Title: "Test"}}, nil, ModelOptions{})
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := updated.(Model)
+	if m.state != stateLastInput {
+		t.Fatalf("expected last input state, got %v", m.state)
+	}
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("0")})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m := updated.(Model); m.errorMsg == "" || m.state != stateLastInput {
+		t.Fatal("expected zero to be rejected")
+	}
+
+	m = updated.(Model)
+	m.lastInput.SetValue("250")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.GetExportMode() != ModeLastN {
+		t.Fatalf("expected ModeLastN, got %v", m.GetExportMode())
+	}
+	if count, ok := m.GetLastCount(); !ok || count != 250 {
+		t.Fatalf("unexpected last count: %d %v", count, ok)
+	}
+	if !strings.Contains(m.View(), "Mode: Last 250") {
+		t.Fatalf("expected status bar to show count: %q", m.View())
+	}
+}
+
+func TestModel_MentionsMode(t *testing.T) {
+	model := NewModel([]telegram.Chat{{ID: 1, Title: "Test", UnreadMentions: 2}}, nil, ModelOptions{})
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	for i := 0; i < 3; i++ {
+		updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := updated.(Model)
+	if m.GetExportMode() != ModeMentions {
+		t.Fatalf("expected ModeMentions, got %v", m.GetExportMode())
+	}
+	view := m.View()
+	if !strings.Contains(view, "Mode: Mentions") || !strings.Contains(view, "Mentions: 2") {
+		t.Fatalf("unexpected status bar: %q", view)
+	}
+}
+
+func TestModel_ContextInput(t *testing.T) {
+	model := NewModel([]telegram.Chat{{ID: 1, Title: "Test"}}, nil, ModelOptions{})
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	m := updated.(Model)
+	if m.state != stateContextInput {
+		t.Fatalf("expected context input state, got %v", m.state)
+	}
+
+	m.contextInput.SetValue("-3")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m := updated.(Model); m.errorMsg == "" || m.state != stateContextInput {
+		t.Fatal("expected negative count to be rejected")
+	}
+
+	m = updated.(Model)
+	m.contextInput.SetValue("20")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.GetContextSize() != 20 {
+		t.Fatalf("expected context 20, got %d", m.GetContextSize())
+	}
+	if m.GetExportMode() != ModeUnread {
+		t.Fatalf("expected mode to stay unread, got %v", m.GetExportMode())
+	}
+	if !strings.Contains(m.View(), "Mode: Unread (context 20)") {
+		t.Fatalf("expected status bar to show context: %q", m.View())
+	}
+}
+
+func TestModel_EnterRejectsForbiddenChat(t *testing.T) {
+	chats := []telegram.Chat{{ID: 1, Title: "Old Group", Forbidden: true}}
+	model := NewModel(chats, nil, ModelOptions{})
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := updated.(Model)
+	if m.done || m.GetSelected() != nil {
+		t.Fatal("expected forbidden chat not to be selected")
+	}
+	if !strings.Contains(m.statusMsg, "removed or banned") {
+		t.Fatalf("expected forbidden status message, got %q", m.statusMsg)
+	}
+	if view := m.View(); !strings.Contains(view, "[forbidden]") || !strings.Contains(view, "Status: forbidden") {
+		t.Fatalf("expected forbidden tag in view: %q", view)
+	}
+}
+
+func TestModel_SearchKey(t *testing.T) {
+	model := NewModel([]telegram.Chat{{ID: 1, Title: "Chat"}}, nil, ModelOptions{})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m := newModel.(Model)
+	if !m.Done() || !m.SearchRequested() || m.GetSelected() != nil {
+		t.Fatal("expected s to leave the chat list for the search screen")
+	}
+}
+
+func TestModel_InboxKey(t *testing.T) {
+	chats := []telegram.Chat{{ID: 1, Title: "Chat"}, {ID: 2, Title: "News"}}
+	model := NewModel(chats, nil, ModelOptions{})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m := newModel.(Model)
+	if !m.Done() || !m.InboxRequested() || m.GetSelected() != nil {
+		t.Fatal("expected i to request the inbox digest")
+	}
+	if got := m.VisibleChats(); len(got) != 2 || got[1].ID != 2 {
+		t.Fatalf("unexpected visible chats: %+v", got)
+	}
+}
+
+func TestModel_MultiSelect(t *testing.T) {
+	chats := []telegram.Chat{
+		{ID: 1, Title: "Alpha"},
+		{ID: 2, Title: "Banned", Forbidden: true},
+		{ID: 3, Title: "Gamma"},
+	}
+	model := NewModel(chats, nil, ModelOptions{})
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	newModel, _ := model.Update(space)
+	m := newModel.(Model)
+	if got := m.MultiSelected(); len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("expected space to select the current chat, got %+v", got)
+	}
+	if !strings.Contains(m.View(), "Selected: 1") || !strings.Contains(m.View(), "[x] Alpha") {
+		t.Fatalf("expected the selection in the view, got %q", m.View())
+	}
+	newModel, _ = m.Update(space)
+	m = newModel.(Model)
+	if len(m.MultiSelected()) != 0 {
+		t.Fatal("expected space to clear the selection")
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = newModel.(Model)
+	if got := m.MultiSelected(); len(got) != 2 || got[0].ID != 1 || got[1].ID != 3 {
+		t.Fatalf("expected a to select the exportable chats, got %+v", got)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if !m.Done() || m.GetSelected() != nil || len(m.MultiSelected()) != 2 {
+		t.Fatal("expected enter to finish with the selected chats")
+	}
+
+	newModel, _ = NewModel(chats, nil, ModelOptions{}).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	newModel, _ = newModel.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if got := newModel.(Model).MultiSelected(); len(got) != 0 {
+		t.Fatalf("expected a second a to clear the selection, got %+v", got)
+	}
+}
+
+func TestTopicModel_MultiSelect(t *testing.T) {
+	topics := []telegram.Topic{
+		{ID: 1, Title: "General", UnreadCount: 5},
+		{ID: 2, Title: "Off-topic", UnreadCount: 10},
+	}
+	model := NewTopicModel(topics)
+
+	// Space on the All topics entry selects nothing.
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	newModel, _ := model.Update(space)
+	m := newModel.(TopicModel)
+	if len(m.MultiSelected()) != 0 {
+		t.Fatal("expected the All topics entry not to be selectable")
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = newModel.(TopicModel)
+	if got := m.MultiSelected(); len(got) != 2 || !strings.Contains(m.View(), "Selected: 2 topics") {
+		t.Fatalf("expected a to select all topics, got %+v", got)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(TopicModel)
+	if !m.Done() || m.AllTopicsSelected() || m.GetSelected() != nil {
+		t.Fatal("expected enter to finish with the selected topics")
+	}
+}
+
+func TestBatchModel(t *testing.T) {
+	msgCh := make(chan tea.Msg)
+	model := NewBatchModel("Exporting 3 chats", []string{"Alpha", "Beta", "Gamma"})
+	model, _ = model.Start(0, msgCh)
+	updated, _ := model.Update(ProgressMsg{Parsed: 12})
+	model = updated.(BatchModel)
+	if view := model.View(); !strings.Contains(view, "Alpha: parsed 12 messages") || !strings.Contains(view, "wait Beta") {
+		t.Fatalf("unexpected running view: %q", view)
+	}
+
+	model = model.Finish(0, "12 messages to exports/Alpha.txt", false)
+	model = model.Finish(1, "chat is restricted", true)
+	model = model.Skip("canceled").Complete()
+	view := model.View()
+	for _, want := range []string{"(2 of 3 done)", "done Alpha: 12 messages", "failed Beta: chat is restricted", "skip Gamma: canceled"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in %q", want, view)
+		}
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !updated.(BatchModel).Done() {
+		t.Fatal("expected enter to close the completed batch")
+	}
+}
+
+func TestSearchModel_Update(t *testing.T) {
+	model := NewSearchModel(time.Time{}, time.Time{})
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(SearchModel)
+	if m.Done() || !strings.Contains(m.View(), "Enter a search query") {
+		t.Fatal("expected an empty query to be rejected")
+	}
+
+	for _, r := range "golang" {
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = newModel.(SearchModel)
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = newModel.(SearchModel)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(SearchModel)
+	if !m.Done() || m.Canceled() || m.Query() != "golang" || m.Scope() != telegram.SearchGroups {
+		t.Fatalf("unexpected search: done=%v query=%q scope=%q", m.Done(), m.Query(), m.Scope())
+	}
+
+	newModel, _ = NewSearchModel(time.Time{}, time.Time{}).Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m := newModel.(SearchModel); !m.Done() || !m.Canceled() {
+		t.Fatal("expected esc to cancel the search")
+	}
+}
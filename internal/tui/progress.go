@@ -31,6 +31,9 @@ type ProgressModel struct {
 	spinner spinner.Model
 	msgCh   <-chan tea.Msg
 	done    bool
+	// canceling is set once the user asked to stop; the fetch still has to
+	// return what it collected so far.
+	canceling bool
 }
 
 func NewProgressModel(title string, msgCh <-chan tea.Msg) ProgressModel {
@@ -84,6 +87,11 @@ func (m ProgressModel) View() string {
 	if m.phase != "" {
 		lines = append(lines, progressInfoStyle.Render(fmt.Sprintf("Phase: %s", m.phase)))
 	}
+	if m.canceling {
+		lines = append(lines, progressInfoStyle.Render("Canceling, waiting for the current batch..."))
+	} else {
+		lines = append(lines, helpStyle.Render("esc: stop and keep what was fetched"))
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
@@ -91,6 +99,16 @@ func (m ProgressModel) Done() bool {
 	return m.done
 }
 
+// Cancel marks the progress view as stopping.
+func (m ProgressModel) Cancel() ProgressModel {
+	m.canceling = true
+	return m
+}
+
+func (m ProgressModel) Canceling() bool {
+	return m.canceling
+}
+
 func waitForProgress(ch <-chan tea.Msg) tea.Cmd {
 	return func() tea.Msg {
 		msg, ok := <-ch
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"cli-tg-chat-summary/internal/telegram"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var searchScopes = []telegram.SearchScope{
+	telegram.SearchAll,
+	telegram.SearchGroups,
+	telegram.SearchChannels,
+	telegram.SearchPrivate,
+}
+
+// SearchModel asks for the query of a global search across all chats.
+// Tab cycles through the kinds of chats to search.
+type SearchModel struct {
+	input    textinput.Model
+	scope    int
+	since    time.Time
+	until    time.Time
+	errorMsg string
+	done     bool
+	canceled bool
+}
+
+// NewSearchModel creates the search screen. A non-zero since and until
+// limit the search to that window and are shown on the screen.
+func NewSearchModel(since, until time.Time) SearchModel {
+	input := textinput.New()
+	input.Placeholder = "query"
+	input.CharLimit = 256
+	input.Width = 40
+	input.Focus()
+	return SearchModel{input: input, since: since, until: until}
+}
+
+func (m SearchModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "ctrl+c", "esc":
+			m.done = true
+			m.canceled = true
+			return m, nil
+		case "tab":
+			m.scope = (m.scope + 1) % len(searchScopes)
+			return m, nil
+		case "shift+tab":
+			m.scope = (m.scope + len(searchScopes) - 1) % len(searchScopes)
+			return m, nil
+		case "enter":
+			if m.Query() == "" {
+				m.errorMsg = "Enter a search query"
+				return m, nil
+			}
+			m.errorMsg = ""
+			m.done = true
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m SearchModel) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Search all chats"))
+	b.WriteString("\n\n  ")
+	b.WriteString(m.input.View())
+	b.WriteString("\n\n")
+	b.WriteString(statusBarStyle.Render("Scope: " + string(m.Scope())))
+	if !m.since.IsZero() {
+		b.WriteString("\n")
+		b.WriteString(statusBarStyle.Render("Window: " + m.since.Format("2006-01-02") + " to " + m.until.Format("2006-01-02")))
+	}
+	if m.errorMsg != "" {
+		b.WriteString("\n\n")
+		b.WriteString(errorStyle.Render(m.errorMsg))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("enter: search  tab: scope  esc: back"))
+	return b.String()
+}
+
+// Query returns the trimmed search query.
+func (m SearchModel) Query() string {
+	return strings.TrimSpace(m.input.Value())
+}
+
+// Scope returns the kind of chats to search.
+func (m SearchModel) Scope() telegram.SearchScope {
+	return searchScopes[m.scope]
+}
+
+func (m SearchModel) Done() bool {
+	return m.done
+}
+
+func (m SearchModel) Canceled() bool {
+	return m.canceled
+}